@@ -0,0 +1,217 @@
+package cmd
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cosmos/relayer/relayer"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/tendermint/tendermint/libs/log"
+)
+
+// chainMetricsInterval is how often the height and balance metrics of each chain are refreshed
+const chainMetricsInterval = time.Minute
+
+// relayedPath is a path relayed by the running process, as exposed by the api
+type relayedPath struct {
+	name     string
+	path     *relayer.Path
+	src, dst *relayer.Chain
+	channels *relayer.ChannelSet
+}
+
+// pathResponse is the api representation of a relayed path
+type pathResponse struct {
+	Name   string `json:"name"`
+	Paused bool   `json:"paused"`
+	*relayer.PathWithStatus
+}
+
+// unrelayedResponse lists the unrelayed packets and acknowledgements of a channel relayed by a path
+type unrelayedResponse struct {
+	Channel          string                  `json:"channel"`
+	Packets          *relayer.RelaySequences `json:"packets"`
+	Acknowledgements *relayer.RelaySequences `json:"acknowledgements"`
+}
+
+// apiHandler serves the status and control api for the paths relayed by the process:
+//
+//	GET  /paths                       list the paths with their status
+//	GET  /paths/{name}                show a single path with its status
+//	GET  /paths/{name}/unrelayed      show the unrelayed packets and acknowledgements of every channel of a path
+//	POST /paths/{name}/update-clients update the clients of a path
+//	POST /paths/{name}/pause          stop relaying on a path
+//	POST /paths/{name}/resume         resume relaying on a paused path
+//
+// POST requests must carry the api-token of the global config as a bearer token, and are refused
+// altogether when no token is configured.
+type apiHandler struct {
+	paths  map[string]*relayedPath
+	token  string
+	logger log.Logger
+}
+
+// newAPIMux returns the handler served on the api-listen-addr of the global config
+func newAPIMux(paths map[string]*relayedPath, token string, logger log.Logger) *http.ServeMux {
+	api := &apiHandler{paths: paths, token: token, logger: logger}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(relayer.Metrics.Registry, promhttp.HandlerOpts{}))
+	mux.HandleFunc("/paths", api.handlePaths)
+	mux.HandleFunc("/paths/", api.handlePath)
+	return mux
+}
+
+// serveAPI serves handler on addr in the background, logging when the server stops
+func serveAPI(addr string, handler http.Handler, logger log.Logger) {
+	go func() {
+		if err := http.ListenAndServe(addr, handler); err != nil {
+			logger.Error("api server stopped", "addr", addr, "err", err)
+		}
+	}()
+}
+
+func (a *apiHandler) handlePaths(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		a.writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+		return
+	}
+
+	names := make([]string, 0, len(a.paths))
+	for name := range a.paths {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	out := make([]*pathResponse, 0, len(names))
+	for _, name := range names {
+		out = append(out, a.paths[name].response())
+	}
+	a.writeJSON(w, http.StatusOK, out)
+}
+
+func (a *apiHandler) handlePath(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/paths/"), "/"), "/")
+	rp, ok := a.paths[parts[0]]
+	if !ok {
+		a.writeError(w, http.StatusNotFound, fmt.Errorf("path %s is not being relayed", parts[0]))
+		return
+	}
+
+	var action string
+	if len(parts) > 1 {
+		action = strings.Join(parts[1:], "/")
+	}
+
+	method := http.MethodPost
+	if action == "" || action == "unrelayed" {
+		method = http.MethodGet
+	}
+	if r.Method != method {
+		a.writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+		return
+	}
+	if method == http.MethodPost {
+		if status, err := a.authorize(r); err != nil {
+			a.writeError(w, status, err)
+			return
+		}
+	}
+
+	switch action {
+	case "":
+		a.writeJSON(w, http.StatusOK, rp.response())
+
+	case "unrelayed":
+		var unrelayed []*unrelayedResponse
+		for _, ch := range rp.channels.Channels() {
+			sp, err := relayer.UnrelayedSequences(ch.Src, ch.Dst)
+			if err != nil {
+				a.writeError(w, http.StatusInternalServerError, err)
+				return
+			}
+			ap, err := relayer.UnrelayedAcknowledgements(ch.Src, ch.Dst)
+			if err != nil {
+				a.writeError(w, http.StatusInternalServerError, err)
+				return
+			}
+			unrelayed = append(unrelayed, &unrelayedResponse{Channel: ch.Src.PathEnd.ChannelID, Packets: sp, Acknowledgements: ap})
+		}
+		a.writeJSON(w, http.StatusOK, unrelayed)
+
+	case "update-clients":
+		if err := rp.src.UpdateClients(rp.dst); err != nil {
+			a.writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		a.writeJSON(w, http.StatusOK, rp.response())
+
+	case "pause":
+		relayer.PausePath(rp.name)
+		rp.src.Log(fmt.Sprintf("[%s] relaying paused", rp.name))
+		a.writeJSON(w, http.StatusOK, rp.response())
+
+	case "resume":
+		relayer.ResumePath(rp.name)
+		rp.src.Log(fmt.Sprintf("[%s] relaying resumed", rp.name))
+		a.writeJSON(w, http.StatusOK, rp.response())
+
+	default:
+		a.writeError(w, http.StatusNotFound, fmt.Errorf("unknown action %s", action))
+	}
+}
+
+// authorize checks that r carries the api token, returning the status to refuse it with otherwise
+func (a *apiHandler) authorize(r *http.Request) (int, error) {
+	if a.token == "" {
+		return http.StatusForbidden, errors.New("path control is disabled, set api-token in the global config to enable it")
+	}
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if subtle.ConstantTimeCompare([]byte(token), []byte(a.token)) != 1 {
+		return http.StatusUnauthorized, errors.New("invalid api token")
+	}
+	return 0, nil
+}
+
+func (rp *relayedPath) response() *pathResponse {
+	return &pathResponse{
+		Name:           rp.name,
+		Paused:         relayer.PathPaused(rp.name),
+		PathWithStatus: rp.path.QueryPathStatus(rp.src, rp.dst),
+	}
+}
+
+func (a *apiHandler) writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		a.logger.Error("failed to write api response", "err", err)
+	}
+}
+
+func (a *apiHandler) writeError(w http.ResponseWriter, status int, err error) {
+	a.writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+// reportChainMetrics periodically refreshes the metrics of every chain until done is closed
+func reportChainMetrics(chains []*relayer.Chain, done <-chan struct{}) {
+	ticker := time.NewTicker(chainMetricsInterval)
+	defer ticker.Stop()
+	for {
+		for _, c := range chains {
+			if err := relayer.Metrics.UpdateChainMetrics(c); err != nil {
+				c.Error(fmt.Errorf("failed to update chain metrics: %w", err))
+			}
+		}
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+	}
+}
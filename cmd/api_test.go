@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/cosmos/relayer/relayer"
+	"github.com/cosmos/relayer/relayer/provider/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/tendermint/tendermint/libs/log"
+)
+
+// newTestAPI serves the api for a path named demo between two mock chains
+func newTestAPI(t *testing.T, token string) *httptest.Server {
+	t.Helper()
+	chains := make([]*relayer.Chain, 2)
+	for i, chainID := range []string{"ibc-0", "ibc-1"} {
+		mc := mock.NewChain(chainID, time.Now())
+		t.Cleanup(mc.Run())
+		c := &relayer.Chain{ChainProvider: mock.NewProvider(mc, "testkey"), Chainid: chainID}
+		c.Init(log.NewNopLogger(), false)
+		require.NoError(t, c.SetPath(&relayer.PathEnd{ChainID: chainID, PortID: "transfer", Order: "unordered", Version: "ics20-1"}))
+		chains[i] = c
+	}
+	paths := map[string]*relayedPath{"demo": {
+		name: "demo",
+		path: &relayer.Path{Src: chains[0].PathEnd, Dst: chains[1].PathEnd},
+		src:  chains[0],
+		dst:  chains[1],
+	}}
+
+	srv := httptest.NewServer(newAPIMux(paths, token, log.NewNopLogger()))
+	t.Cleanup(srv.Close)
+	t.Cleanup(func() { relayer.ResumePath("demo") })
+	return srv
+}
+
+// apiRequest sends a request to the api and returns the status and body of the response
+func apiRequest(t *testing.T, srv *httptest.Server, method, path, token string) (int, string) {
+	t.Helper()
+	req, err := http.NewRequest(method, srv.URL+path, nil)
+	require.NoError(t, err)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	res, err := srv.Client().Do(req)
+	require.NoError(t, err)
+	defer res.Body.Close()
+	body, err := ioutil.ReadAll(res.Body)
+	require.NoError(t, err)
+	return res.StatusCode, string(body)
+}
+
+func TestAPIPauseResume(t *testing.T) {
+	srv := newTestAPI(t, "secret")
+
+	paused := func() bool {
+		status, body := apiRequest(t, srv, http.MethodGet, "/paths/demo", "")
+		require.Equal(t, http.StatusOK, status, body)
+		var res pathResponse
+		require.NoError(t, json.Unmarshal([]byte(body), &res))
+		require.Equal(t, "demo", res.Name)
+		return res.Paused
+	}
+	require.False(t, paused())
+
+	status, _ := apiRequest(t, srv, http.MethodPost, "/paths/demo/pause", "secret")
+	require.Equal(t, http.StatusOK, status)
+	require.True(t, relayer.PathPaused("demo"))
+	require.True(t, paused())
+
+	status, body := apiRequest(t, srv, http.MethodGet, "/paths", "")
+	require.Equal(t, http.StatusOK, status)
+	var list []*pathResponse
+	require.NoError(t, json.Unmarshal([]byte(body), &list))
+	require.Len(t, list, 1)
+	require.True(t, list[0].Paused)
+
+	status, _ = apiRequest(t, srv, http.MethodPost, "/paths/demo/resume", "secret")
+	require.Equal(t, http.StatusOK, status)
+	require.False(t, paused())
+}
+
+func TestAPIAuth(t *testing.T) {
+	srv := newTestAPI(t, "secret")
+
+	for _, tc := range []struct {
+		method, path, token string
+		status              int
+	}{
+		// reading the status of a path needs no token, controlling it does
+		{http.MethodGet, "/paths/demo", "", http.StatusOK},
+		{http.MethodPost, "/paths/demo/pause", "", http.StatusUnauthorized},
+		{http.MethodPost, "/paths/demo/pause", "wrong", http.StatusUnauthorized},
+		{http.MethodGet, "/paths/demo/pause", "secret", http.StatusMethodNotAllowed},
+		{http.MethodPost, "/paths/other/pause", "secret", http.StatusNotFound},
+		{http.MethodPost, "/paths/demo/restart", "secret", http.StatusNotFound},
+	} {
+		status, body := apiRequest(t, srv, tc.method, tc.path, tc.token)
+		require.Equal(t, tc.status, status, "%s %s: %s", tc.method, tc.path, body)
+	}
+	require.False(t, relayer.PathPaused("demo"))
+}
+
+func TestAPIControlDisabledWithoutToken(t *testing.T) {
+	srv := newTestAPI(t, "")
+
+	status, body := apiRequest(t, srv, http.MethodPost, "/paths/demo/pause", "")
+	require.Equal(t, http.StatusForbidden, status)
+	require.Contains(t, body, "api-token")
+	require.False(t, relayer.PathPaused("demo"))
+}
+
+func TestAPIMetrics(t *testing.T) {
+	srv := newTestAPI(t, "")
+	relayer.Metrics.LatestHeight.WithLabelValues("ibc-0").Set(42)
+
+	status, body := apiRequest(t, srv, http.MethodGet, "/metrics", "")
+	require.Equal(t, http.StatusOK, status)
+	require.Contains(t, body, `rly_latest_height{chain_id="ibc-0"} 42`)
+}
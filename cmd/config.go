@@ -450,6 +450,31 @@ func (c *Config) ChainsFromPath(path string) (map[string]*relayer.Chain, string,
 	return chains, src, dst, nil
 }
 
+// PathChains returns the src and dst chains of the named path. Unlike ChainsFromPath the chains
+// are copies, so the chains of several paths can be used at the same time.
+func (c *Config) PathChains(path string) (*relayer.Chain, *relayer.Chain, error) {
+	pth, err := c.Paths.Get(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	chains, err := c.Chains.Gets(pth.Src.ChainID, pth.Dst.ChainID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	src, err := chains[pth.Src.ChainID].WithPath(path, pth.Src)
+	if err != nil {
+		return nil, nil, err
+	}
+	dst, err := chains[pth.Dst.ChainID].WithPath(path, pth.Dst)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return src, dst, nil
+}
+
 // MustYAML returns the yaml string representation of the Paths
 func (c Config) MustYAML() []byte {
 	out, err := yaml.Marshal(c)
@@ -472,12 +497,25 @@ type GlobalConfig struct {
 	APIListenPort  string `yaml:"api-listen-addr" json:"api-listen-addr"`
 	Timeout        string `yaml:"timeout" json:"timeout"`
 	LightCacheSize int    `yaml:"light-cache-size" json:"light-cache-size"`
+
+	// APIToken is the bearer token that requests to pause, resume or update the clients of a path through
+	// the api must carry. Those requests are refused when it is empty.
+	APIToken string `yaml:"api-token,omitempty" json:"api-token,omitempty"`
+
+	// ChainFeeBudgets limits the fees spent on each chain, keyed by chain id
+	ChainFeeBudgets map[string]*relayer.FeeBudget `yaml:"chain-fee-budgets,omitempty" json:"chain-fee-budgets,omitempty"`
+
+	// ChainMinBalances sets the balance below which the keys and fee granter of each chain are reported, keyed by chain id
+	ChainMinBalances map[string]*relayer.MinBalance `yaml:"chain-min-balances,omitempty" json:"chain-min-balances,omitempty"`
+
+	// Notifications configures the webhooks that relayer incidents are reported to
+	Notifications *relayer.NotifierConfig `yaml:"notifications,omitempty" json:"notifications,omitempty"`
 }
 
 // newDefaultGlobalConfig returns a global config with defaults set
 func newDefaultGlobalConfig() GlobalConfig {
 	return GlobalConfig{
-		APIListenPort:  ":5183",
+		APIListenPort:  "127.0.0.1:5183",
 		Timeout:        "10s",
 		LightCacheSize: 20,
 	}
@@ -584,6 +622,37 @@ func validateConfig(c *Config) error {
 		return fmt.Errorf("did you remember to run 'rly config init' error:%w", err)
 	}
 
+	for chainID, fb := range c.Global.ChainFeeBudgets {
+		if err = fb.Validate(); err != nil {
+			return fmt.Errorf("invalid fee budget for chain %s: %w", chainID, err)
+		}
+	}
+	if c.Global.Notifications != nil {
+		if err = c.Global.Notifications.Validate(); err != nil {
+			return err
+		}
+	}
+	for chainID, mb := range c.Global.ChainMinBalances {
+		if err = mb.Validate(); err != nil {
+			return fmt.Errorf("invalid min balance for chain %s: %w", chainID, err)
+		}
+	}
+	for name, p := range c.Paths {
+		if p.FeeBudget != nil {
+			if err = p.FeeBudget.Validate(); err != nil {
+				return fmt.Errorf("invalid fee budget for path %s: %w", name, err)
+			}
+		}
+		if p.Filter != nil {
+			if err = p.Filter.Validate(); err != nil {
+				return fmt.Errorf("invalid packet filter for path %s: %w", name, err)
+			}
+		}
+		if err = p.ValidateChannels(); err != nil {
+			return fmt.Errorf("invalid channels for path %s: %w", name, err)
+		}
+	}
+
 	return nil
 }
 
@@ -613,6 +682,11 @@ func initConfig(cmd *cobra.Command) error {
 				os.Exit(1)
 			}
 
+			logger, err := relayer.NewLogger(logFormat, logLevel)
+			if err != nil {
+				return err
+			}
+
 			// build the config struct
 			var chains relayer.Chains
 			for _, pcfg := range cfgWrapper.ProviderConfigs {
@@ -620,9 +694,16 @@ func initConfig(cmd *cobra.Command) error {
 				if err != nil {
 					return fmt.Errorf("Error while building ChainProviders. Err: %w\n", err)
 				}
+				prov.SetLightCacheSize(cfgWrapper.Global.LightCacheSize)
 
 				chain := &relayer.Chain{ChainProvider: prov}
-				chain.Init(nil, debug)
+				chain.Init(logger, debug)
+				switch {
+				case generateOnly != "":
+					chain.EnableGenerateOnly(generatedTxs)
+				case dryRun:
+					chain.EnableDryRun()
+				}
 				chains = append(chains, chain)
 			}
 
@@ -644,6 +725,11 @@ func initConfig(cmd *cobra.Command) error {
 }
 
 func overWriteConfig(cfg *Config) (err error) {
+	// a dry run only shows what would change
+	if dryRunMode() {
+		return nil
+	}
+
 	cfgPath := path.Join(homePath, "config", "config.yaml")
 	if _, err = os.Stat(cfgPath); err == nil {
 		viper.SetConfigFile(cfgPath)
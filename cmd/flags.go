@@ -29,6 +29,24 @@ var (
 	flagPort                    = "port"
 	flagOrder                   = "unordered"
 	flagVersion                 = "version"
+	flagEvents                  = "events"
+	flagSweepInterval           = "sweep-interval"
+	flagAll                     = "all"
+	flagSpendLimit              = "spend-limit"
+	flagExpiration              = "expiration"
+	flagFromChain               = "from-chain"
+	flagKeyName                 = "key"
+	flagLogFormat               = "log-format"
+	flagLogLevel                = "log-level"
+	flagMonitorClients          = "monitor-clients"
+	flagUpdateAllClients        = "update-all-clients"
+	flagResume                  = "resume"
+	flagDryRun                  = "dry-run"
+	flagGenerateOnly            = "generate-only"
+	flagToken                   = "token"
+	flagTLSCert                 = "tls-cert"
+	flagTLSKey                  = "tls-key"
+	flagClientCA                = "client-ca"
 )
 
 func ibcDenomFlags(cmd *cobra.Command) *cobra.Command {
@@ -153,6 +171,39 @@ func strategyFlag(cmd *cobra.Command) *cobra.Command {
 	return cmd
 }
 
+func eventRelayFlags(cmd *cobra.Command) *cobra.Command {
+	cmd.Flags().Bool(flagEvents, false, "relay packets as they are reported by event subscriptions instead of polling")
+	cmd.Flags().Duration(flagSweepInterval, 5*time.Minute, "interval between full scans for unrelayed packets when relaying from events")
+	if err := viper.BindPFlag(flagEvents, cmd.Flags().Lookup(flagEvents)); err != nil {
+		panic(err)
+	}
+	if err := viper.BindPFlag(flagSweepInterval, cmd.Flags().Lookup(flagSweepInterval)); err != nil {
+		panic(err)
+	}
+	return cmd
+}
+
+func clientExpiryFlags(cmd *cobra.Command) *cobra.Command {
+	cmd.Flags().Bool(flagMonitorClients, false, "report every client on the relayed chains that comes within the time threshold of expiry")
+	cmd.Flags().Bool(flagUpdateAllClients, false,
+		"update expiring clients whose counterparty chain is configured, implies --"+flagMonitorClients)
+	if err := viper.BindPFlag(flagMonitorClients, cmd.Flags().Lookup(flagMonitorClients)); err != nil {
+		panic(err)
+	}
+	if err := viper.BindPFlag(flagUpdateAllClients, cmd.Flags().Lookup(flagUpdateAllClients)); err != nil {
+		panic(err)
+	}
+	return cmd
+}
+
+func allPathsFlag(cmd *cobra.Command) *cobra.Command {
+	cmd.Flags().Bool(flagAll, false, "start relaying on every configured path")
+	if err := viper.BindPFlag(flagAll, cmd.Flags().Lookup(flagAll)); err != nil {
+		panic(err)
+	}
+	return cmd
+}
+
 func getAddInputs(cmd *cobra.Command) (file string, url string, err error) {
 	file, err = cmd.Flags().GetString(flagFile)
 	if err != nil {
@@ -209,6 +260,14 @@ func overrideFlag(cmd *cobra.Command) *cobra.Command {
 	return cmd
 }
 
+func resumeFlag(cmd *cobra.Command) *cobra.Command {
+	cmd.Flags().Bool(flagResume, false, "resume the handshakes of the path from their on-chain state and print the steps remaining")
+	if err := viper.BindPFlag(flagResume, cmd.Flags().Lookup(flagResume)); err != nil {
+		panic(err)
+	}
+	return cmd
+}
+
 func orderFlag(cmd *cobra.Command) *cobra.Command {
 	cmd.Flags().BoolP(flagOrder, "o", true, "create an unordered channel")
 	if err := viper.BindPFlag(flagOrder, cmd.Flags().Lookup(flagOrder)); err != nil {
@@ -232,3 +291,48 @@ func portFlag(cmd *cobra.Command) *cobra.Command {
 	}
 	return cmd
 }
+
+func feeAllowanceFlags(cmd *cobra.Command) *cobra.Command {
+	cmd.Flags().String(flagSpendLimit, "", "most the keys may spend on fees in total, unlimited if empty")
+	cmd.Flags().Duration(flagExpiration, 0, "how long the allowances are valid for, no expiry if zero")
+	if err := viper.BindPFlag(flagSpendLimit, cmd.Flags().Lookup(flagSpendLimit)); err != nil {
+		panic(err)
+	}
+	if err := viper.BindPFlag(flagExpiration, cmd.Flags().Lookup(flagExpiration)); err != nil {
+		panic(err)
+	}
+	return cmd
+}
+
+func authzGrantFlags(cmd *cobra.Command) *cobra.Command {
+	cmd.Flags().Duration(flagExpiration, 365*24*time.Hour, "how long the authorizations are valid for")
+	if err := viper.BindPFlag(flagExpiration, cmd.Flags().Lookup(flagExpiration)); err != nil {
+		panic(err)
+	}
+	return cmd
+}
+
+func topUpFlags(cmd *cobra.Command) *cobra.Command {
+	cmd.Flags().String(flagFromChain, "", "chain to send the funds from")
+	cmd.Flags().String(flagKeyName, "", "key to refill, the key of the chain if empty")
+	if err := viper.BindPFlag(flagFromChain, cmd.Flags().Lookup(flagFromChain)); err != nil {
+		panic(err)
+	}
+	if err := viper.BindPFlag(flagKeyName, cmd.Flags().Lookup(flagKeyName)); err != nil {
+		panic(err)
+	}
+	return cmd
+}
+
+func signerAuthFlags(cmd *cobra.Command) *cobra.Command {
+	cmd.Flags().String(flagToken, "", "bearer token that requests must carry")
+	cmd.Flags().String(flagTLSCert, "", "certificate to serve https with")
+	cmd.Flags().String(flagTLSKey, "", "key of the certificate to serve https with")
+	cmd.Flags().String(flagClientCA, "", "CA that the client certificates requests must carry are verified with")
+	for _, flag := range []string{flagToken, flagTLSCert, flagTLSKey, flagClientCA} {
+		if err := viper.BindPFlag(flag, cmd.Flags().Lookup(flag)); err != nil {
+			panic(err)
+		}
+	}
+	return cmd
+}
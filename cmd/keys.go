@@ -17,17 +17,29 @@ limitations under the License.
 package cmd
 
 import (
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"log"
+	"net"
+	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/relayer/relayer"
+	"github.com/cosmos/relayer/relayer/provider"
+	"github.com/cosmos/relayer/relayer/provider/cosmos"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 )
 
 const (
 	flagCoinType           = "coin-type"
+	flagLedger             = "ledger"
+	flagLedgerAccount      = "account"
+	flagLedgerIndex        = "index"
 	defaultCoinType uint32 = sdk.CoinType
 )
 
@@ -45,6 +57,10 @@ func keysCmd() *cobra.Command {
 	cmd.AddCommand(keysListCmd())
 	cmd.AddCommand(keysShowCmd())
 	cmd.AddCommand(keysExportCmd())
+	cmd.AddCommand(keysPoolCmd())
+	cmd.AddCommand(keysGrantCmd())
+	cmd.AddCommand(keysAuthzGrantCmd())
+	cmd.AddCommand(keysServeSignerCmd())
 
 	return cmd
 }
@@ -59,7 +75,8 @@ func keysAddCmd() *cobra.Command {
 		Example: strings.TrimSpace(fmt.Sprintf(`
 $ %s keys add ibc-0
 $ %s keys add ibc-1 key2
-$ %s k a ibc-2 testkey`, appName, appName, appName)),
+$ %s k a ibc-2 testkey
+$ %s keys add ibc-0 granter --ledger --account 1`, appName, appName, appName, appName)),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			chain, err := config.Chains.Get(args[0])
 			if err != nil {
@@ -71,6 +88,22 @@ $ %s k a ibc-2 testkey`, appName, appName, appName)),
 				return errKeyExists(keyName)
 			}
 
+			ledger, err := cmd.Flags().GetBool(flagLedger)
+			if err != nil {
+				return err
+			}
+			if ledger {
+				coinType, _ := cmd.Flags().GetUint32(flagCoinType)
+				account, _ := cmd.Flags().GetUint32(flagLedgerAccount)
+				index, _ := cmd.Flags().GetUint32(flagLedgerIndex)
+				address, err := chain.ChainProvider.AddLedgerKey(keyName, coinType, account, index)
+				if err != nil {
+					return err
+				}
+				fmt.Println(address)
+				return nil
+			}
+
 			ko, err := chain.ChainProvider.AddKey(keyName)
 			if err != nil {
 				return err
@@ -86,6 +119,9 @@ $ %s k a ibc-2 testkey`, appName, appName, appName)),
 		},
 	}
 	cmd.Flags().Uint32(flagCoinType, defaultCoinType, "coin type number for HD derivation")
+	cmd.Flags().Bool(flagLedger, false, "keep the private key on a Ledger device, whose cosmos app must be open")
+	cmd.Flags().Uint32(flagLedgerAccount, 0, "account number for HD derivation of a Ledger key")
+	cmd.Flags().Uint32(flagLedgerIndex, 0, "address index for HD derivation of a Ledger key")
 
 	return cmd
 }
@@ -292,3 +328,318 @@ $ %s k e ibc-2 testkey`, appName, appName)),
 
 	return cmd
 }
+
+// keysPoolCmd represents the `keys pool` command
+func keysPoolCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "pool",
+		Aliases: []string{"p"},
+		Short:   "Manage the pool of keys that the relay rounds of a chain are signed with in turn",
+	}
+
+	cmd.AddCommand(keysPoolCreateCmd())
+	cmd.AddCommand(keysPoolFundCmd())
+
+	return cmd
+}
+
+// keysPoolCreateCmd respresents the `keys pool create` command
+func keysPoolCreateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "create [chain-id] [size]",
+		Aliases: []string{"c"},
+		Short:   "Creates a pool of keys named after the chain's key and sets it in the config",
+		Args:    cobra.ExactArgs(2),
+		Example: strings.TrimSpace(fmt.Sprintf(`
+$ %s keys pool create ibc-0 3
+$ %s k p c ibc-1 5`, appName, appName)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			chain, err := config.Chains.Get(args[0])
+			if err != nil {
+				return err
+			}
+
+			size, err := strconv.Atoi(args[1])
+			if err != nil || size < 1 {
+				return fmt.Errorf("invalid pool size %s", args[1])
+			}
+
+			keys, outputs, err := chain.CreateKeyPool(size)
+			if err != nil {
+				return err
+			}
+
+			// the mnemonics of the new keys are printed like those of keys add, existing keys only show their address
+			for _, key := range keys {
+				ko := outputs[key]
+				if ko.Mnemonic == "" {
+					fmt.Printf("key(%s) -> %s\n", key, ko.Address)
+					continue
+				}
+				out, err := json.Marshal(ko)
+				if err != nil {
+					return err
+				}
+				fmt.Printf("key(%s) -> %s\n", key, out)
+			}
+
+			return overWriteConfig(config)
+		},
+	}
+
+	return cmd
+}
+
+// keysPoolFundCmd respresents the `keys pool fund` command
+func keysPoolFundCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "fund [chain-id] [amount]",
+		Aliases: []string{"f"},
+		Short:   "Sends an amount from the chain's key to every key of its pool",
+		Args:    cobra.ExactArgs(2),
+		Example: strings.TrimSpace(fmt.Sprintf(`
+$ %s keys pool fund ibc-0 1000000stake
+$ %s k p f ibc-1 500000stake,1000token`, appName, appName)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			chain, err := config.Chains.Get(args[0])
+			if err != nil {
+				return err
+			}
+
+			amount, err := sdk.ParseCoinsNormalized(args[1])
+			if err != nil {
+				return err
+			}
+
+			return chain.FundKeyPool(amount)
+		},
+	}
+
+	return cmd
+}
+
+// keysGrantCmd represents the `keys grant` command
+func keysGrantCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "grant",
+		Aliases: []string{"g"},
+		Short:   "Manage the fee allowances a treasury key grants to the keys of a chain",
+		Long: strings.TrimSpace(`Manage the x/feegrant allowances a treasury key grants to the key and key pool of a chain.
+Once granted, the treasury is set as the fee-granter of the chain, so it pays the fees of every
+relay transaction and the hot keys only need to hold dust.`),
+	}
+
+	cmd.AddCommand(keysGrantCreateCmd())
+	cmd.AddCommand(keysGrantRevokeCmd())
+
+	return cmd
+}
+
+// keysGrantCreateCmd respresents the `keys grant create` command
+func keysGrantCreateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "create [chain-id] [treasury-key]",
+		Aliases: []string{"c"},
+		Short:   "Grants fee allowances from a treasury key to the chain's keys and sets it as their fee-granter",
+		Args:    cobra.ExactArgs(2),
+		Example: strings.TrimSpace(fmt.Sprintf(`
+$ %s keys grant create ibc-0 treasury
+$ %s keys grant create ibc-0 treasury --spend-limit 10000000stake --expiration 720h
+$ %s k g c ibc-1 treasury`, appName, appName, appName)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			chain, err := config.Chains.Get(args[0])
+			if err != nil {
+				return err
+			}
+
+			treasury, err := providerWithKey(chain, args[1])
+			if err != nil {
+				return err
+			}
+
+			var spendLimit sdk.Coins
+			if limit, _ := cmd.Flags().GetString(flagSpendLimit); limit != "" {
+				if spendLimit, err = sdk.ParseCoinsNormalized(limit); err != nil {
+					return err
+				}
+			}
+
+			var expiration *time.Time
+			if expiry, _ := cmd.Flags().GetDuration(flagExpiration); expiry > 0 {
+				t := time.Now().Add(expiry)
+				expiration = &t
+			}
+
+			if err = chain.GrantFeeAllowances(treasury, spendLimit, expiration); err != nil {
+				return err
+			}
+			return overWriteConfig(config)
+		},
+	}
+
+	return feeAllowanceFlags(cmd)
+}
+
+// keysGrantRevokeCmd respresents the `keys grant revoke` command
+func keysGrantRevokeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "revoke [chain-id] [treasury-key]",
+		Aliases: []string{"r"},
+		Short:   "Revokes the fee allowances of a treasury key to the chain's keys and unsets its fee-granter",
+		Args:    cobra.ExactArgs(2),
+		Example: strings.TrimSpace(fmt.Sprintf(`
+$ %s keys grant revoke ibc-0 treasury
+$ %s k g r ibc-1 treasury`, appName, appName)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			chain, err := config.Chains.Get(args[0])
+			if err != nil {
+				return err
+			}
+
+			treasury, err := providerWithKey(chain, args[1])
+			if err != nil {
+				return err
+			}
+
+			if err = chain.RevokeFeeAllowances(treasury); err != nil {
+				return err
+			}
+			return overWriteConfig(config)
+		},
+	}
+
+	return cmd
+}
+
+// keysAuthzGrantCmd respresents the `keys authz-grant` command
+func keysAuthzGrantCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "authz-grant [chain-id] [granter-key]",
+		Aliases: []string{"ag"},
+		Short:   "Authorizes the chain's keys to relay on behalf of a granter key and sets it as their authz-granter",
+		Long: strings.TrimSpace(`Grants x/authz authorizations from a granter key to the key and key pool of a chain for client
+updates, packets, acknowledgements and timeouts. Once granted, the granter is set as the authz-granter
+of the chain, so relay msgs are sent on its behalf through MsgExec while the hot keys only sign.`),
+		Args: cobra.ExactArgs(2),
+		Example: strings.TrimSpace(fmt.Sprintf(`
+$ %s keys authz-grant ibc-0 main-account
+$ %s keys authz-grant ibc-0 main-account --expiration 2160h
+$ %s k ag ibc-1 main-account`, appName, appName, appName)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			chain, err := config.Chains.Get(args[0])
+			if err != nil {
+				return err
+			}
+
+			granter, err := providerWithKey(chain, args[1])
+			if err != nil {
+				return err
+			}
+
+			expiry, err := cmd.Flags().GetDuration(flagExpiration)
+			if err != nil {
+				return err
+			}
+
+			if err = chain.GrantRelayAuthorizations(granter, time.Now().Add(expiry)); err != nil {
+				return err
+			}
+			return overWriteConfig(config)
+		},
+	}
+
+	return authzGrantFlags(cmd)
+}
+
+// keysServeSignerCmd represents the `keys serve-signer` command
+func keysServeSignerCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "serve-signer [chain-id] [listen-addr]",
+		Short: "Serves the keys of a chain to the relayers configured to sign with a remote signer",
+		Long: strings.TrimSpace(`Serve the keys of a chain over HTTP, for relayers whose chain configuration has a signer url
+pointing at listen-addr to sign with them. The keys never leave this process, which stands in for a
+KMS when testing such a setup, and should run with its own --home, on a host of its own.
+
+Requests must carry the --token as a bearer token, or a client certificate issued by the --client-ca
+when served over https with --tls-cert and --tls-key. Only a signer listening on the loopback
+interface may be served without either.`),
+		Args: cobra.ExactArgs(2),
+		Example: strings.TrimSpace(fmt.Sprintf(`
+$ %s keys serve-signer ibc-0 localhost:7000 --home ~/.signer
+$ %s keys serve-signer ibc-0 0.0.0.0:7000 --home ~/.signer --tls-cert signer.crt --tls-key signer.key --client-ca ca.crt`,
+			appName, appName)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			chain, err := config.Chains.Get(args[0])
+			if err != nil {
+				return err
+			}
+
+			var (
+				auth              = cosmos.SignerAuth{Token: viper.GetString(flagToken)}
+				certFile, keyFile = viper.GetString(flagTLSCert), viper.GetString(flagTLSKey)
+				clientCA          = viper.GetString(flagClientCA)
+			)
+			if (certFile == "") != (keyFile == "") {
+				return fmt.Errorf("--%s and --%s must be set together", flagTLSCert, flagTLSKey)
+			}
+			srv := &http.Server{Addr: args[1]}
+			if clientCA != "" {
+				if certFile == "" {
+					return fmt.Errorf("--%s requires --%s and --%s", flagClientCA, flagTLSCert, flagTLSKey)
+				}
+				pool, err := cosmos.LoadCertPool(clientCA)
+				if err != nil {
+					return err
+				}
+				srv.TLSConfig = &tls.Config{
+					MinVersion: tls.VersionTLS12,
+					ClientAuth: tls.RequireAndVerifyClientCert,
+					ClientCAs:  pool,
+				}
+				auth.ClientCerts = true
+			}
+
+			host, _, err := net.SplitHostPort(args[1])
+			if err != nil {
+				return err
+			}
+			if !auth.Enabled() && !cosmos.IsLoopback(host) {
+				return fmt.Errorf("refusing to serve keys on %s without --%s or --%s", args[1], flagToken, flagClientCA)
+			}
+
+			switch pcfg := chain.ChainProvider.ProviderConfig().(type) {
+			case cosmos.CosmosProviderConfig:
+				signer, err := pcfg.LocalSigner(homePath)
+				if err != nil {
+					return err
+				}
+				srv.Handler = cosmos.NewSignerHandler(signer, auth)
+				fmt.Printf("serving the keys of %s on %s\n", chain.ChainID(), args[1])
+				if certFile != "" {
+					return srv.ListenAndServeTLS(certFile, keyFile)
+				}
+				return srv.ListenAndServe()
+			default:
+				return fmt.Errorf("chain %s does not support serving its keys", chain.ChainID())
+			}
+		},
+	}
+	return signerAuthFlags(cmd)
+}
+
+// providerWithKey returns a provider for the chain that signs with the named key, paying its own fees and
+// sending msgs on its own behalf
+// NOTE: Add logic for new ProviderConfig types in a switch case here
+func providerWithKey(chain *relayer.Chain, key string) (provider.ChainProvider, error) {
+	if !chain.ChainProvider.KeyExists(key) {
+		return nil, errKeyDoesntExist(key)
+	}
+
+	switch pcfg := chain.ChainProvider.ProviderConfig().(type) {
+	case cosmos.CosmosProviderConfig:
+		pcfg.Key, pcfg.Keys, pcfg.FeeGranter, pcfg.AuthzGranter = key, nil, "", ""
+		return pcfg.NewProvider(homePath, debug)
+	default:
+		return nil, fmt.Errorf("chain %s does not support signing with another key", chain.ChainID())
+	}
+}
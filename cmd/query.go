@@ -25,6 +25,7 @@ func queryCmd() *cobra.Command {
 	cmd.AddCommand(
 		queryUnrelayedPackets(),
 		queryUnrelayedAcknowledgements(),
+		queryExpiredPackets(),
 		flags.LineBreak,
 		//queryAccountCmd(),
 		queryBalanceCmd(),
@@ -36,6 +37,7 @@ func queryCmd() *cobra.Command {
 		flags.LineBreak,
 		queryClientCmd(),
 		queryClientsCmd(),
+		queryClientExpiryCmd(),
 		queryConnection(),
 		queryConnections(),
 		queryConnectionsUsingClient(),
@@ -429,6 +431,47 @@ $ %s query clients ibc-2 --offset 2 --limit 30`,
 	return cmd
 }
 
+func queryClientExpiryCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "client-expiry [chain-id]",
+		Aliases: []string{"expiry"},
+		Short:   "query when every light client on a network by chain ID runs out of its trusting period",
+		Args:    cobra.ExactArgs(1),
+		Example: strings.TrimSpace(fmt.Sprintf(`
+$ %s query client-expiry ibc-0
+$ %s q expiry ibc-0 --time-threshold 24h`,
+			appName, appName,
+		)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			chain, err := config.Chains.Get(args[0])
+			if err != nil {
+				return err
+			}
+
+			threshold, err := cmd.Flags().GetDuration(flagThresholdTime)
+			if err != nil {
+				return err
+			}
+
+			expiries, err := relayer.QueryClientExpiries(chain, threshold)
+			if err != nil {
+				return err
+			}
+
+			for _, expiry := range expiries {
+				out, err := json.Marshal(expiry)
+				if err != nil {
+					return err
+				}
+				fmt.Println(string(out))
+			}
+			return nil
+		},
+	}
+
+	return updateTimeFlags(cmd)
+}
+
 //func queryValSetAtHeightCmd() *cobra.Command {
 //	cmd := &cobra.Command{
 //		Use:   "valset [chain-id]",
@@ -814,6 +857,53 @@ $ %s query unrelayed-pkts demo-path`,
 	return cmd
 }
 
+func queryExpiredPackets() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "expired-packets [path]",
+		Short: "query for unrelayed packets on a given path that are past their timeout height or timestamp",
+		Args:  cobra.ExactArgs(1),
+		Example: strings.TrimSpace(fmt.Sprintf(`
+$ %s q expired-packets demo-path
+$ %s query expired-packets demo-path`,
+			appName, appName,
+		)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, err := config.Paths.Get(args[0])
+			if err != nil {
+				return err
+			}
+			src, dst := path.Src.ChainID, path.Dst.ChainID
+
+			c, err := config.Chains.Gets(src, dst)
+			if err != nil {
+				return err
+			}
+
+			if err = c[src].SetPath(path.Src); err != nil {
+				return err
+			}
+			if err = c[dst].SetPath(path.Dst); err != nil {
+				return err
+			}
+
+			ep, err := relayer.QueryExpiredPackets(c[src], c[dst])
+			if err != nil {
+				return err
+			}
+
+			out, err := json.Marshal(ep)
+			if err != nil {
+				return err
+			}
+
+			fmt.Println(string(out))
+			return nil
+		},
+	}
+
+	return cmd
+}
+
 func queryUnrelayedAcknowledgements() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:     "unrelayed-acknowledgements [path]",
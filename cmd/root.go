@@ -23,6 +23,7 @@ import (
 	"strings"
 
 	"github.com/cosmos/cosmos-sdk/client/flags"
+	"github.com/cosmos/relayer/relayer"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -35,10 +36,17 @@ const (
 var (
 	homePath    string
 	debug       bool
+	dryRun      bool
+	logFormat   string
+	logLevel    string
 	config      *Config
 	defaultHome = os.ExpandEnv("$HOME/.relayer")
 	appName     = "rly"
 
+	// generateOnly is the file that the txs generated with --generate-only are written to
+	generateOnly string
+	generatedTxs = &relayer.OfflineTxs{}
+
 	// Default identifiers for dummy usage
 	dcli = "defaultclientid"
 	dcon = "defaultconnectionid"
@@ -79,6 +87,17 @@ func NewRootCmd() *cobra.Command {
 		panic(err)
 	}
 
+	// Register --log-format and --log-level flags
+	rootCmd.PersistentFlags().StringVar(&logFormat, flagLogFormat, relayer.LogFormatText,
+		fmt.Sprintf("log output format, either %s or %s", relayer.LogFormatText, relayer.LogFormatJSON))
+	if err := viper.BindPFlag(flagLogFormat, rootCmd.PersistentFlags().Lookup(flagLogFormat)); err != nil {
+		panic(err)
+	}
+	rootCmd.PersistentFlags().StringVar(&logLevel, flagLogLevel, "info", "minimum log level, one of debug, info, error or none")
+	if err := viper.BindPFlag(flagLogLevel, rootCmd.PersistentFlags().Lookup(flagLogLevel)); err != nil {
+		panic(err)
+	}
+
 	// Register subcommands
 	rootCmd.AddCommand(
 		configCmd(),
@@ -21,6 +21,7 @@ import (
 	"math"
 	"os"
 	"os/signal"
+	"sort"
 	"strconv"
 	"strings"
 	"syscall"
@@ -38,72 +39,292 @@ import (
 // NOTE: This is basically pseudocode
 func startCmd() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:     "start [path-name]",
+		Use:     "start [path-name...]",
 		Aliases: []string{"st"},
-		Short:   "Start the listening relayer on a given path",
-		Args:    cobra.ExactArgs(1),
+		Short:   "Start the listening relayer on the given paths",
+		Long: strings.TrimSpace(`Start relaying packets and acknowledgements on one or more configured paths.
+Every channel of a path gets its own relay loop and every path its own
+client-refresh loop, while chains shared between paths reuse a single connection
+to their RPC endpoint. Paths relay the channel of their path ends along with
+the channels listed under "channels", where "*" stands for every open channel
+on the connection.`),
+		Args: cobra.ArbitraryArgs,
 		Example: strings.TrimSpace(fmt.Sprintf(`
 $ %s start demo-path --max-msgs 3
-$ %s start demo-path2 --max-tx-size 10`, appName, appName)),
+$ %s start demo-path2 --max-tx-size 10
+$ %s start demo-path --events --sweep-interval 10m
+$ %s start demo-path demo-path2
+$ %s start --all`, appName, appName, appName, appName, appName)),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			c, src, dst, err := config.ChainsFromPath(args[0])
+			paths, err := startPathNames(args)
 			if err != nil {
 				return err
 			}
 
-			if err = ensureKeysExist(c); err != nil {
+			maxTxSize, maxMsgLength, err := GetStartOptions(cmd)
+			if err != nil {
 				return err
 			}
 
-			path := config.Paths.MustGet(args[0])
-			maxTxSize, maxMsgLength, err := GetStartOptions(cmd)
+			events, err := cmd.Flags().GetBool(flagEvents)
+			if err != nil {
+				return err
+			}
+			sweepInterval, err := cmd.Flags().GetDuration(flagSweepInterval)
 			if err != nil {
 				return err
 			}
+			if sweepInterval <= 0 {
+				return fmt.Errorf("--%s must be positive, got %s", flagSweepInterval, sweepInterval)
+			}
 
-			if relayer.SendToController != nil {
-				action := relayer.PathAction{
-					Path: path,
-					Type: "RELAYER_PATH_START",
-				}
-				cont, err := relayer.ControllerUpcall(&action)
-				if !cont {
-					return err
-				}
+			thresholdTime := viper.GetDuration(flagThresholdTime)
+
+			if err = setNotifier(); err != nil {
+				return err
 			}
 
-			done, err := relayer.StartRelayer(c[src], c[dst], maxTxSize, maxMsgLength)
+			store, err := relayer.OpenStateStore(homePath)
 			if err != nil {
-				c[src].Log(fmt.Sprintf("relayer start error. Err: %v", err))
+				return err
 			}
+			relayer.SetStateStore(store)
 
-			thresholdTime := viper.GetDuration(flagThresholdTime)
+			var (
+				dones   []func()
+				chains  = make(map[string]*relayer.Chain)
+				relayed = make(map[string]*relayedPath)
+			)
+			for _, name := range paths {
+				src, dst, err := config.PathChains(name)
+				if err != nil {
+					return err
+				}
+				chains[src.ChainID()], chains[dst.ChainID()] = src, dst
+
+				if err = relayer.SetPathFeeBudget(name, config.Paths.MustGet(name).FeeBudget); err != nil {
+					return err
+				}
+				if err = relayer.SetPathFilter(name, config.Paths.MustGet(name).Filter); err != nil {
+					return err
+				}
+
+				if err = ensureKeysExist(map[string]*relayer.Chain{src.ChainID(): src, dst.ChainID(): dst}); err != nil {
+					return err
+				}
+
+				// settle the txs a previous run left in flight before building any new messages
+				if err = store.Reconcile(src); err != nil {
+					return err
+				}
+				if err = store.Reconcile(dst); err != nil {
+					return err
+				}
+				srch, _ := store.PathHeight(name, src.ChainID())
+				dsth, _ := store.PathHeight(name, dst.ChainID())
+				if srch > 0 || dsth > 0 {
+					src.Log(fmt.Sprintf("- [%s] last relayed at [%s]@{%d} and [%s]@{%d}", name, src.ChainID(), srch, dst.ChainID(), dsth))
+				}
 
-			eg := new(errgroup.Group)
-			eg.Go(func() error {
-				for {
-					var timeToExpiry time.Duration
-					if err := retry.Do(func() error {
-						timeToExpiry, err = UpdateClientsFromChains(c[src], c[dst], thresholdTime)
-						if err != nil {
-							return err
-						}
-						return nil
-					}, retry.Attempts(5), retry.Delay(time.Millisecond*500), retry.LastErrorOnly(true)); err != nil {
+				if relayer.SendToController != nil {
+					action := relayer.PathAction{
+						Path: config.Paths.MustGet(name),
+						Type: "RELAYER_PATH_START",
+					}
+					cont, err := relayer.ControllerUpcall(&action)
+					if !cont {
 						return err
 					}
-					time.Sleep(timeToExpiry - thresholdTime)
 				}
-			})
-			if err = eg.Wait(); err != nil {
-				c[src].Log(fmt.Sprintf("update clients error. Err: %v", err))
+
+				// the channels of the path are relayed together, behind one client update per chain
+				channels, err := relayer.NewChannelSet(name, config.Paths.MustGet(name), src, dst)
+				if err != nil {
+					src.Log(fmt.Sprintf("[%s] channel lookup error. Err: %v", name, err))
+					continue
+				}
+				if len(channels.Channels()) == 0 && len(config.Paths.MustGet(name).Channels) == 0 {
+					src.Log(fmt.Sprintf("[%s] no channels to relay", name))
+					continue
+				}
+
+				var done func()
+				if events {
+					done, err = relayer.StartChannelSetEventRelayer(channels, maxTxSize, maxMsgLength, sweepInterval)
+				} else {
+					done, err = relayer.StartChannelSetRelayer(channels, maxTxSize, maxMsgLength)
+				}
+				if err != nil {
+					src.Log(fmt.Sprintf("[%s] relayer start error. Err: %v", name, err))
+					continue
+				}
+				dones = append(dones, done)
+				relayed[name] = &relayedPath{name: name, path: config.Paths.MustGet(name), src: src, dst: dst, channels: channels}
+
+				stopMonitor, err := relayer.StartMisbehaviourMonitor(src, dst)
+				if err != nil {
+					src.Log(fmt.Sprintf("[%s] misbehaviour monitor start error. Err: %v", name, err))
+				} else {
+					dones = append(dones, stopMonitor)
+				}
+
+				go refreshClients(name, src, dst, thresholdTime)
+			}
+
+			for chainID, fb := range config.Global.ChainFeeBudgets {
+				if err = relayer.SetChainFeeBudget(chainID, fb); err != nil {
+					return err
+				}
 			}
 
-			trapSignal(done)
+			for chainID, c := range chains {
+				mb, ok := config.Global.ChainMinBalances[chainID]
+				if !ok {
+					continue
+				}
+				stopMonitor, err := relayer.StartBalanceMonitor(c, mb)
+				if err != nil {
+					return err
+				}
+				dones = append(dones, stopMonitor)
+			}
+
+			updateAllClients := viper.GetBool(flagUpdateAllClients)
+			if viper.GetBool(flagMonitorClients) || updateAllClients {
+				configured := make(map[string]*relayer.Chain)
+				for _, c := range config.Chains {
+					configured[c.ChainID()] = c
+				}
+				for _, c := range chains {
+					dones = append(dones, relayer.StartClientExpiryMonitor(c, configured, thresholdTime, updateAllClients))
+				}
+			}
+
+			if config.Global.APIListenPort != "" {
+				logger, err := relayer.NewLogger(logFormat, logLevel)
+				if err != nil {
+					return err
+				}
+				logger = logger.With("module", "api")
+				serveAPI(config.Global.APIListenPort, newAPIMux(relayed, config.Global.APIToken, logger), logger)
+			}
+
+			metricsDone := make(chan struct{})
+			var metricsChains []*relayer.Chain
+			for _, c := range chains {
+				metricsChains = append(metricsChains, c)
+			}
+			go reportChainMetrics(metricsChains, metricsDone)
+
+			trapSignal(func() {
+				close(metricsDone)
+				for _, done := range dones {
+					done()
+				}
+				if err := store.Close(); err != nil {
+					fmt.Println("failed to close state store:", err)
+				}
+			})
 			return nil
 		},
 	}
-	return strategyFlag(updateTimeFlags(cmd))
+	return clientExpiryFlags(allPathsFlag(eventRelayFlags(strategyFlag(updateTimeFlags(cmd)))))
+}
+
+// startPathNames returns the names of the paths to start, which are either the given
+// arguments or, with the --all flag, every configured path
+func startPathNames(args []string) ([]string, error) {
+	if viper.GetBool(flagAll) {
+		if len(args) > 0 {
+			return nil, fmt.Errorf("cannot pass path names together with --%s", flagAll)
+		}
+		var names []string
+		for name := range config.Paths {
+			names = append(names, name)
+		}
+		if len(names) == 0 {
+			return nil, fmt.Errorf("no paths configured")
+		}
+		sort.Strings(names)
+		return names, nil
+	}
+
+	if len(args) == 0 {
+		return nil, fmt.Errorf("pass at least one path name or --%s", flagAll)
+	}
+	for _, name := range args {
+		if _, err := config.Paths.Get(name); err != nil {
+			return nil, err
+		}
+	}
+	return args, nil
+}
+
+// setNotifier sets up the notifier configured in the global config, if there is one. The deprecated
+// webhooks of the chain min balances are added to it as targets for the low balances of their chain.
+func setNotifier() error {
+	nc := &relayer.NotifierConfig{}
+	if config.Global.Notifications != nil {
+		*nc = *config.Global.Notifications
+	}
+	nc.Targets = append([]*relayer.NotifyTarget(nil), nc.Targets...)
+	for chainID, mb := range config.Global.ChainMinBalances {
+		t := mb.WebhookTarget(chainID)
+		if t == nil {
+			continue
+		}
+		if c, err := config.Chains.Get(chainID); err == nil {
+			c.Log(fmt.Sprintf("the webhook of the min balance of %s is deprecated, add it as a notifications target instead", chainID))
+		}
+		nc.Targets = append(nc.Targets, t)
+	}
+	if config.Global.Notifications == nil && len(nc.Targets) == 0 {
+		return nil
+	}
+
+	logger, err := relayer.NewLogger(logFormat, logLevel)
+	if err != nil {
+		return err
+	}
+	n, err := relayer.NewNotifier(nc, logger.With("module", "notifier"))
+	if err != nil {
+		return err
+	}
+	relayer.SetNotifier(n)
+	return nil
+}
+
+// refreshClients keeps the clients of a path from expiring by updating them thresholdTime
+// before they expire. Clients within thresholdTime of their expiry are reported before they are
+// updated, so a notification goes out while they can still be saved. It returns once the clients
+// can no longer be updated.
+func refreshClients(name string, src, dst *relayer.Chain, thresholdTime time.Duration) {
+	for {
+		var (
+			timeToExpiry time.Duration
+			err          error
+		)
+		if err = relayer.ReportPathClientExpiries(src, dst, thresholdTime); err != nil {
+			src.Log(fmt.Sprintf("[%s] client expiry query error. Err: %v", name, err))
+		}
+		if err = retry.Do(func() error {
+			timeToExpiry, err = UpdateClientsFromChains(src, dst, thresholdTime)
+			if err != nil {
+				return err
+			}
+			return nil
+		}, retry.Attempts(5), retry.Delay(time.Millisecond*500), retry.LastErrorOnly(true)); err != nil {
+			src.Log(fmt.Sprintf("[%s] update clients error. Err: %v", name, err))
+			relayer.Notify(&relayer.Notification{
+				Event:   relayer.EventClientExpiry,
+				Path:    name,
+				Subject: name,
+				Message: fmt.Sprintf("clients of path %s can no longer be kept from expiring: %s", name, err),
+			})
+			return
+		}
+		time.Sleep(timeToExpiry - thresholdTime)
+	}
 }
 
 // trap signal waits for a SIGINT or SIGTERM and then sends down the done channel
@@ -141,6 +362,10 @@ func UpdateClientsFromChains(src, dst *relayer.Chain, thresholdTime time.Duratio
 		return 0, err
 	}
 
+	path := src.PathName()
+	relayer.Metrics.ClientExpiry.WithLabelValues(path, src.ChainID(), src.ClientID()).Set(srcTimeExpiry.Seconds())
+	relayer.Metrics.ClientExpiry.WithLabelValues(path, dst.ChainID(), dst.ClientID()).Set(dstTimeExpiry.Seconds())
+
 	if srcTimeExpiry <= 0 {
 		return 0, fmt.Errorf("client (%s) of chain: %s is expired",
 			src.PathEnd.ClientID, src.ChainID())
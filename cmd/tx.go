@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
@@ -11,7 +12,9 @@ import (
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/cosmos/ibc-go/v2/modules/core/exported"
 	"github.com/cosmos/relayer/relayer"
+	"github.com/cosmos/relayer/relayer/provider"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 )
 
 // transactionCmd returns a parent transaction command handler, where all child
@@ -24,17 +27,39 @@ func transactionCmd() *cobra.Command {
 		Long: strings.TrimSpace(`Commands to create IBC transactions on pre-configured chains.
 Most of these commands take a [path] argument. Make sure:
   1. Chains are properly configured to relay over by using the 'rly chains list' command
-  2. Path is properly configured to relay over by using the 'rly paths list' command`,
+  2. Path is properly configured to relay over by using the 'rly paths list' command
+
+With --dry-run, the txs of a command are simulated instead of being signed and broadcast: each
+one is printed as JSON along with its target chain, estimated gas and estimated fee, and the
+command stops before any step that depends on an earlier tx being committed. The config and
+the relayer state are left untouched.
+
+With --generate-only [file], the txs are written unsigned to the file instead, in the same way,
+to be signed on another host with 'rly tx sign' and submitted with 'rly tx broadcast'. The keys
+of the chains only need to hold their public keys to generate txs.`,
 		),
 	}
 
+	cmd.PersistentFlags().BoolVar(&dryRun, flagDryRun, false,
+		"simulate the txs and print them with their estimated gas and fee instead of broadcasting them")
+	if err := viper.BindPFlag(flagDryRun, cmd.PersistentFlags().Lookup(flagDryRun)); err != nil {
+		panic(err)
+	}
+	cmd.PersistentFlags().StringVar(&generateOnly, flagGenerateOnly, "",
+		"write the txs unsigned to this file for offline signing instead of broadcasting them")
+	if err := viper.BindPFlag(flagGenerateOnly, cmd.PersistentFlags().Lookup(flagGenerateOnly)); err != nil {
+		panic(err)
+	}
+
 	cmd.AddCommand(
 		linkCmd(),
 		linkThenStartCmd(),
 		relayMsgsCmd(),
 		relayMsgCmd(),
 		relayAcksCmd(),
+		relayTimeoutsCmd(),
 		xfersend(),
+		topUpCmd(),
 		flags.LineBreak,
 		createClientsCmd(),
 		createClientCmd(),
@@ -44,13 +69,48 @@ Most of these commands take a [path] argument. Make sure:
 		createConnectionCmd(),
 		closeChannelCmd(),
 		flags.LineBreak,
+		signTxsCmd(),
+		broadcastTxsCmd(),
+		flags.LineBreak,
 
 		//sendCmd(),
 	)
 
+	for _, sub := range cmd.Commands() {
+		if sub.RunE != nil {
+			sub.RunE = dryRunE(sub.RunE)
+		}
+	}
+
 	return cmd
 }
 
+// dryRunE lets a tx command succeed once its txs have been simulated in dry-run mode, or generated
+// in generate-only mode, in which case they are written to their file
+func dryRunE(runE func(*cobra.Command, []string) error) func(*cobra.Command, []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		if dryRun && generateOnly != "" {
+			return fmt.Errorf("--%s and --%s cannot be combined", flagDryRun, flagGenerateOnly)
+		}
+		if err := runE(cmd, args); !dryRunMode() || !errors.Is(err, provider.ErrDryRun) {
+			return err
+		}
+		if generateOnly == "" {
+			return nil
+		}
+		if err := generatedTxs.WriteFile(generateOnly); err != nil {
+			return err
+		}
+		fmt.Printf("wrote %d unsigned txs to %s\n", len(generatedTxs.Txs), generateOnly)
+		return nil
+	}
+}
+
+// dryRunMode returns true if the txs of the command are not to be broadcast, with --dry-run or --generate-only
+func dryRunMode() bool {
+	return dryRun || generateOnly != ""
+}
+
 // TODO send needs revised still
 //func sendCmd() *cobra.Command {
 //	cmd := &cobra.Command{
@@ -431,14 +491,19 @@ func linkCmd() *cobra.Command {
 		Aliases: []string{"connect"},
 		Short:   "create clients, connection, and channel between two configured chains with a configured path",
 		Long: strings.TrimSpace(`Create an IBC client between two IBC-enabled networks, in addition
-to creating a connection and a channel between the two networks on a configured path.`,
+to creating a connection and a channel between the two networks on a configured path.
+
+With --resume, the clients, connections and channels on both chains are inspected first: a
+handshake that was left halfway, for example by an interrupted link, is picked up at the step
+it stopped at, and the steps remaining are printed before they are executed.`,
 		),
 		Args: cobra.ExactArgs(1),
 		Example: strings.TrimSpace(fmt.Sprintf(`
 $ %s transact link demo-path
 $ %s tx link demo-path
+$ %s tx link demo-path --resume
 $ %s tx connect demo-path`,
-			appName, appName, appName,
+			appName, appName, appName, appName,
 		)),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			allowUpdateAfterExpiry, err := cmd.Flags().GetBool(flagUpdateAfterExpiry)
@@ -471,6 +536,11 @@ $ %s tx connect demo-path`,
 				return err
 			}
 
+			resume, err := cmd.Flags().GetBool(flagResume)
+			if err != nil {
+				return err
+			}
+
 			// ensure that keys exist
 			if exists := c[src].ChainProvider.KeyExists(c[src].ChainProvider.Key()); !exists {
 				return fmt.Errorf("key %s not found on chain %s \n", c[src].ChainProvider.Key(), c[src].ChainID())
@@ -479,7 +549,44 @@ $ %s tx connect demo-path`,
 				return fmt.Errorf("key %s not found on chain %s \n", c[dst].ChainProvider.Key(), c[dst].ChainID())
 			}
 
+			if err = setNotifier(); err != nil {
+				return err
+			}
+
+			// record the progress of the handshake so an interrupted link can be told apart from a new one,
+			// unless it is a dry run that will not make any
+			var store *relayer.StateStore
+			if !dryRunMode() {
+				if store, err = relayer.OpenHandshakeStore(homePath); err != nil {
+					return err
+				}
+				defer store.Close()
+			}
+			if stage, err := store.Handshake(args[0]); err == nil && stage != "" && stage != relayer.HandshakeComplete {
+				c[src].Log(fmt.Sprintf("- resuming handshake of path %s at stage %s", args[0], stage))
+			}
+
+			// pick up handshakes left halfway on chain and show what remains to be done
+			if resume {
+				plan, modified, err := relayer.ResumeLink(c[src], c[dst])
+				if modified {
+					if err := overWriteConfig(config); err != nil {
+						return err
+					}
+				}
+				if err != nil {
+					return err
+				}
+				printLinkPlan(args[0], plan)
+				if len(plan) == 0 {
+					return store.SetHandshake(args[0], relayer.HandshakeComplete)
+				}
+			}
+
 			// create clients if they aren't already created
+			if err = store.SetHandshake(args[0], relayer.HandshakeClients); err != nil {
+				return err
+			}
 			modified, err := c[src].CreateClients(c[dst], allowUpdateAfterExpiry, allowUpdateAfterMisbehaviour, override)
 			if modified {
 				if err := overWriteConfig(config); err != nil {
@@ -487,10 +594,13 @@ $ %s tx connect demo-path`,
 				}
 			}
 			if err != nil {
-				return fmt.Errorf("error creating clients. Err: %w\n", err)
+				return notifyHandshakeFailure(args[0], relayer.HandshakeClients, fmt.Errorf("error creating clients. Err: %w\n", err))
 			}
 
 			// create connection if it isn't already created
+			if err = store.SetHandshake(args[0], relayer.HandshakeConnection); err != nil {
+				return err
+			}
 			modified, err = c[src].CreateOpenConnections(c[dst], retries, to)
 			if modified {
 				if err := overWriteConfig(config); err != nil {
@@ -498,10 +608,13 @@ $ %s tx connect demo-path`,
 				}
 			}
 			if err != nil {
-				return fmt.Errorf("error creating connections. Err: %w\n", err)
+				return notifyHandshakeFailure(args[0], relayer.HandshakeConnection, fmt.Errorf("error creating connections. Err: %w\n", err))
 			}
 
 			// create channel if it isn't already created
+			if err = store.SetHandshake(args[0], relayer.HandshakeChannel); err != nil {
+				return err
+			}
 			modified, err = c[src].CreateOpenChannels(c[dst], retries, to)
 			if modified {
 				if err := overWriteConfig(config); err != nil {
@@ -509,14 +622,26 @@ $ %s tx connect demo-path`,
 				}
 			}
 			if err != nil {
-				return fmt.Errorf("error creating channels. Err: %w\n", err)
+				return notifyHandshakeFailure(args[0], relayer.HandshakeChannel, fmt.Errorf("error creating channels. Err: %w\n", err))
 			}
 
-			return nil
+			return store.SetHandshake(args[0], relayer.HandshakeComplete)
 		},
 	}
 
-	return overrideFlag(clientParameterFlags(retryFlag(timeoutFlag(cmd))))
+	return resumeFlag(overrideFlag(clientParameterFlags(retryFlag(timeoutFlag(cmd)))))
+}
+
+// printLinkPlan prints the steps that remain to link the ends of a path
+func printLinkPlan(path string, plan []relayer.LinkStep) {
+	if len(plan) == 0 {
+		fmt.Printf("path %s is already linked, no steps remaining\n", path)
+		return
+	}
+	fmt.Printf("steps remaining to link path %s:\n", path)
+	for i, step := range plan {
+		fmt.Printf("  %d. %s\n", i+1, step)
+	}
 }
 
 func linkThenStartCmd() *cobra.Command {
@@ -544,7 +669,7 @@ $ %s tx link-then-start demo-path --timeout 5s`, appName, appName)),
 		},
 	}
 
-	return overrideFlag(clientParameterFlags(strategyFlag(retryFlag(timeoutFlag(cmd)))))
+	return eventRelayFlags(resumeFlag(overrideFlag(clientParameterFlags(strategyFlag(retryFlag(timeoutFlag(cmd)))))))
 }
 
 func relayMsgCmd() *cobra.Command {
@@ -552,7 +677,10 @@ func relayMsgCmd() *cobra.Command {
 		Use:     "relay-packet [path-name] [seq-num]",
 		Aliases: []string{"relay-pkt"},
 		Short:   "relay a non-relayed packet with a specific sequence number, in both directions",
-		Args:    cobra.ExactArgs(2),
+		Long: strings.TrimSpace(`Relay a non-relayed packet with a specific sequence number, in both directions.
+Sequence numbers are per channel, so only the channel of the path ends is searched, not the
+additional channels listed in the channels of the path.`),
+		Args: cobra.ExactArgs(2),
 		Example: strings.TrimSpace(fmt.Sprintf(`
 $ %s transact relay-packet demo-path 1
 $ %s tx relay-pkt demo-path 1`,
@@ -594,7 +722,7 @@ func relayMsgsCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:     "relay-packets [path-name]",
 		Aliases: []string{"relay-pkts"},
-		Short:   "relay any remaining non-relayed packets on a given path, in both directions",
+		Short:   "relay any remaining non-relayed packets on every channel of a given path, in both directions",
 		Args:    cobra.ExactArgs(1),
 		Example: strings.TrimSpace(fmt.Sprintf(`
 $ %s transact relay-packets demo-path
@@ -616,16 +744,12 @@ $ %s tx relay-pkts demo-path`,
 				return err
 			}
 
-			sp, err := relayer.UnrelayedSequences(c[src], c[dst])
+			channels, err := relayer.NewChannelSet(args[0], config.Paths.MustGet(args[0]), c[src], c[dst])
 			if err != nil {
 				return err
 			}
 
-			if err = relayer.RelayPackets(c[src], c[dst], sp, maxTxSize, maxMsgLength); err != nil {
-				return err
-			}
-
-			return nil
+			return channels.RelayUnrelayed(true, false, maxTxSize, maxMsgLength)
 		},
 	}
 
@@ -636,7 +760,7 @@ func relayAcksCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:     "relay-acknowledgements [path-name]",
 		Aliases: []string{"relay-acks"},
-		Short:   "relay any remaining non-relayed acknowledgements on a given path, in both directions",
+		Short:   "relay any remaining non-relayed acknowledgements on every channel of a given path, in both directions",
 		Args:    cobra.ExactArgs(1),
 		Example: strings.TrimSpace(fmt.Sprintf(`
 $ %s transact relay-acknowledgements demo-path
@@ -658,17 +782,53 @@ $ %s tx relay-acks demo-path -l 3 -s 6`,
 				return err
 			}
 
-			// sp.Src contains all sequences acked on SRC but acknowledgement not processed on DST
-			// sp.Dst contains all sequences acked on DST but acknowledgement not processed on SRC
-			sp, err := relayer.UnrelayedAcknowledgements(c[src], c[dst])
+			channels, err := relayer.NewChannelSet(args[0], config.Paths.MustGet(args[0]), c[src], c[dst])
 			if err != nil {
 				return err
 			}
 
-			if err = relayer.RelayAcknowledgements(c[src], c[dst], sp, maxTxSize, maxMsgLength); err != nil {
+			return channels.RelayUnrelayed(false, true, maxTxSize, maxMsgLength)
+		},
+	}
+
+	return strategyFlag(cmd)
+}
+
+func relayTimeoutsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "relay-timeouts [path-name]",
+		Short: "time out any unrelayed packets on every channel of a given path that are past their timeout, refunding their senders",
+		Args:  cobra.ExactArgs(1),
+		Example: strings.TrimSpace(fmt.Sprintf(`
+$ %s transact relay-timeouts demo-path
+$ %s tx relay-timeouts demo-path -l 3 -s 6`,
+			appName, appName,
+		)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, src, dst, err := config.ChainsFromPath(args[0])
+			if err != nil {
+				return err
+			}
+
+			if err = ensureKeysExist(c); err != nil {
+				return err
+			}
+
+			maxTxSize, maxMsgLength, err := GetStartOptions(cmd)
+			if err != nil {
+				return err
+			}
+
+			channels, err := relayer.NewChannelSet(args[0], config.Paths.MustGet(args[0]), c[src], c[dst])
+			if err != nil {
 				return err
 			}
 
+			for _, ch := range channels.Channels() {
+				if err = relayer.RelayTimeouts(ch.Src, ch.Dst, maxTxSize, maxMsgLength); err != nil {
+					return err
+				}
+			}
 			return nil
 		},
 	}
@@ -827,6 +987,92 @@ $ %s tx raw send ibc-0 ibc-1 100000stake cosmos1skjwj5whet0lpe65qaq4rpq03hjxlwd9
 	return timeoutFlags(pathFlag(cmd))
 }
 
+// notifyHandshakeFailure reports that the handshake of a path failed at stage and returns err once the
+// notification has been sent
+func notifyHandshakeFailure(path, stage string, err error) error {
+	if errors.Is(err, provider.ErrDryRun) {
+		return err
+	}
+	relayer.Notify(&relayer.Notification{
+		Event:   relayer.EventPathStatus,
+		Path:    path,
+		Subject: path + "/handshake",
+		Message: fmt.Sprintf("handshake of path %s failed at stage %s: %s", path, stage, err),
+	})
+	relayer.WaitNotifications()
+	return err
+}
+
+func topUpCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "top-up [chain-id] [amount]",
+		Short: "refill a relayer key with an IBC transfer from another chain",
+		Long: strings.TrimSpace(`Refill the key of a chain, or another of its keys, with an IBC transfer from the key of the
+chain passed with --from-chain over the path between the two. The amount is in the denom it has on the
+sending chain, e.g. the trace of a voucher returning to the chain, and the transfer packet must be
+relayed to the chain like any other.`),
+		Args: cobra.ExactArgs(2),
+		Example: strings.TrimSpace(fmt.Sprintf(`
+$ %s tx top-up ibc-0 1000000transfer/channel-0/stake --from-chain ibc-1
+$ %s tx top-up ibc-0 1000000transfer/channel-0/stake --from-chain ibc-1 --path demo-path --key relayer-1`,
+			appName, appName)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			fromChain, err := cmd.Flags().GetString(flagFromChain)
+			if err != nil {
+				return err
+			}
+			if fromChain == "" {
+				return fmt.Errorf("pass the chain to send the funds from with --%s", flagFromChain)
+			}
+
+			dst, src := args[0], fromChain
+			c, err := config.Chains.Gets(src, dst)
+			if err != nil {
+				return err
+			}
+
+			pth, err := cmd.Flags().GetString(flagPath)
+			if err != nil {
+				return err
+			}
+			if _, err = setPathsFromArgs(c[src], c[dst], pth); err != nil {
+				return err
+			}
+
+			key, err := cmd.Flags().GetString(flagKeyName)
+			if err != nil {
+				return err
+			}
+			if key == "" {
+				key = c[dst].ChainProvider.Key()
+			}
+
+			amount, err := sdk.ParseCoinNormalized(args[1])
+			if err != nil {
+				return err
+			}
+
+			srch, err := c[src].ChainProvider.QueryLatestHeight()
+			if err != nil {
+				return err
+			}
+			dts, err := c[src].ChainProvider.QueryDenomTraces(0, 100, srch)
+			if err != nil {
+				return err
+			}
+			for _, d := range dts {
+				if amount.Denom == d.GetFullDenomPath() {
+					amount = sdk.NewCoin(d.IBCDenom(), amount.Amount)
+				}
+			}
+
+			return relayer.TopUp(c[src], c[dst], key, amount)
+		},
+	}
+
+	return topUpFlags(pathFlag(cmd))
+}
+
 func setPathsFromArgs(src, dst *relayer.Chain, name string) (*relayer.Path, error) {
 	// find any configured paths between the chains
 	paths, err := config.Paths.PathsFromChains(src.ChainID(), dst.ChainID())
@@ -878,3 +1124,63 @@ func ensureKeysExist(chains map[string]*relayer.Chain) error {
 	}
 	return nil
 }
+
+func signTxsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sign [file] [signed-file]",
+		Short: "sign the txs written by --generate-only with the keys of their chains",
+		Long: strings.TrimSpace(`Sign the txs written to a file by a tx command run with --generate-only, with the key of
+their chain in the config, and write them to another file. Txs of chains that are not configured are
+left unsigned, so the txs of a path can be signed on different hosts, one after the other. No node is
+queried, the account numbers and sequences being part of the file.`),
+		Args: cobra.ExactArgs(2),
+		Example: strings.TrimSpace(fmt.Sprintf(`
+$ %s tx clients demo-path --generate-only unsigned.json
+$ %s tx sign unsigned.json signed.json`,
+			appName, appName)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			txs, err := relayer.ReadOfflineTxs(args[0])
+			if err != nil {
+				return err
+			}
+			signed, err := txs.Sign(config.Chains)
+			if err != nil {
+				return err
+			}
+			if signed == 0 {
+				return fmt.Errorf("none of the %d txs in %s are for a configured chain", len(txs.Txs), args[0])
+			}
+			if err = txs.WriteFile(args[1]); err != nil {
+				return err
+			}
+			fmt.Printf("signed %d of %d txs and wrote them to %s\n", signed, len(txs.Txs), args[1])
+			return nil
+		},
+	}
+	return cmd
+}
+
+func broadcastTxsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "broadcast [signed-file]",
+		Short: "broadcast the txs signed by 'rly tx sign' to their chains",
+		Long: strings.TrimSpace(`Broadcast the txs of a file signed by 'rly tx sign' to their chains, in the order they were
+generated, waiting for each one to be committed before the next. The first tx that fails stops the
+broadcast.`),
+		Args: cobra.ExactArgs(1),
+		Example: strings.TrimSpace(fmt.Sprintf(`
+$ %s tx broadcast signed.json`,
+			appName)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if dryRunMode() {
+				return fmt.Errorf("signed txs cannot be broadcast with --%s or --%s", flagDryRun, flagGenerateOnly)
+			}
+			txs, err := relayer.ReadOfflineTxs(args[0])
+			if err != nil {
+				return err
+			}
+			return txs.Broadcast(config.Chains)
+		},
+	}
+	return cmd
+}
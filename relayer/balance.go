@@ -0,0 +1,166 @@
+package relayer
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+const (
+	// defaultBalanceCheckBlocks is how many blocks pass between balance checks when none is configured
+	defaultBalanceCheckBlocks = 100
+
+	// balancePollInterval is how often the height of a chain is polled between balance checks
+	balancePollInterval = 6 * time.Second
+
+	// feeGranterLabel stands for the fee granter of a chain in balance metrics and notifications
+	feeGranterLabel = "fee-granter"
+)
+
+// MinBalance is the balance below which the keys and fee granter of a chain are reported as running out of funds.
+// Amount is a coin list such as "1000000uatom", a key is low once any of its denoms is below it.
+type MinBalance struct {
+	Amount string `yaml:"amount" json:"amount"`
+
+	// CheckEvery is the number of blocks between two checks, 100 if unset
+	CheckEvery int64 `yaml:"check-every,omitempty" json:"check-every,omitempty"`
+
+	// Webhook is a url that low balance notifications of the chain are posted to.
+	// Deprecated: add a notify target listening to low-balance events instead.
+	Webhook string `yaml:"webhook,omitempty" json:"webhook,omitempty"`
+}
+
+// Validate returns an error if the amount of the minimum balance can not be parsed
+func (mb *MinBalance) Validate() error {
+	if _, err := sdk.ParseCoinsNormalized(mb.Amount); err != nil {
+		return fmt.Errorf("invalid min balance %s: %w", mb.Amount, err)
+	}
+	if mb.CheckEvery < 0 {
+		return fmt.Errorf("invalid min balance check interval %d", mb.CheckEvery)
+	}
+	return nil
+}
+
+// WebhookTarget returns the notify target that the deprecated Webhook of the minimum balance of
+// chainID stands for, or nil if it is not set
+func (mb *MinBalance) WebhookTarget(chainID string) *NotifyTarget {
+	if mb.Webhook == "" {
+		return nil
+	}
+	return &NotifyTarget{
+		Name:     chainID + "-min-balance-webhook",
+		URL:      mb.Webhook,
+		Events:   []string{EventLowBalance},
+		ChainIDs: []string{chainID},
+	}
+}
+
+// StartBalanceMonitor checks the balances of the key and key pool of c, and of its fee granter if one
+// is set, against mb every mb.CheckEvery blocks, until the returned func is called
+func StartBalanceMonitor(c *Chain, mb *MinBalance) (func(), error) {
+	min, err := sdk.ParseCoinsNormalized(mb.Amount)
+	if err != nil {
+		return nil, err
+	}
+	every := mb.CheckEvery
+	if every == 0 {
+		every = defaultBalanceCheckBlocks
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go monitorBalances(ctx, c, min, every)
+	return cancel, nil
+}
+
+// monitorBalances checks the balances of c whenever every blocks have passed since the last check
+func monitorBalances(ctx context.Context, c *Chain, min sdk.Coins, every int64) {
+	var (
+		next int64
+		low  = make(map[string]bool)
+	)
+	for {
+		if h, err := c.ChainProvider.QueryLatestHeight(); err != nil {
+			c.Error(fmt.Errorf("balance monitor failed to query height: %w", err))
+		} else if h >= next {
+			for _, key := range c.hotKeys() {
+				addr, err := c.ChainProvider.ShowAddress(key)
+				if err == nil {
+					err = checkBalance(c, min, "key {"+key+"}", key, addr, h, low)
+				}
+				if err != nil {
+					c.Error(fmt.Errorf("balance monitor failed to check key {%s}: %w", key, err))
+				}
+			}
+			// the fees of the hot keys are paid by the granter, so it is the account that runs dry
+			if granter := c.ChainProvider.FeeGranter(); granter != "" {
+				if err = checkBalance(c, min, "fee granter {"+granter+"}", feeGranterLabel, granter, h, low); err != nil {
+					c.Error(fmt.Errorf("balance monitor failed to check fee granter {%s}: %w", granter, err))
+				}
+			}
+			next = h + every
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(balancePollInterval):
+		}
+	}
+}
+
+// checkBalance compares the balance of the account at addr, described by name and labelled key in
+// metrics and notifications, with min. An error is logged and a notification sent when the account
+// drops below it, and a log line written once it is funded again.
+func checkBalance(c *Chain, min sdk.Coins, name, key, addr string, height int64, low map[string]bool) error {
+	balance, err := c.ChainProvider.QueryBalanceWithAddress(addr)
+	if err != nil {
+		return err
+	}
+
+	isLow := false
+	for _, coin := range min {
+		if balance.AmountOf(coin.Denom).LT(coin.Amount) {
+			isLow = true
+		}
+	}
+
+	if isLow {
+		Metrics.WalletBalanceLow.WithLabelValues(c.ChainID(), key).Set(1)
+	} else {
+		Metrics.WalletBalanceLow.WithLabelValues(c.ChainID(), key).Set(0)
+	}
+	if isLow == low[key] {
+		return nil
+	}
+	low[key] = isLow
+
+	if !isLow {
+		c.Log(fmt.Sprintf("✔ [%s]@{%d} %s is funded again with %s", c.ChainID(), height, name, balance))
+		return nil
+	}
+	msg := fmt.Sprintf("[%s]@{%d} %s balance %s is below the minimum of %s", c.ChainID(), height, name, balance, min)
+	if key == feeGranterLabel {
+		c.logger.Error(fmt.Sprintf("✘ %s, fund the fee granter", msg))
+	} else {
+		c.logger.Error(fmt.Sprintf("✘ %s, top it up with `rly tx top-up %s`", msg, c.ChainID()))
+	}
+	notifier.Notify(&Notification{
+		Event:   EventLowBalance,
+		ChainID: c.ChainID(),
+		Subject: c.ChainID() + "/" + key,
+		Message: msg,
+	})
+	return nil
+}
+
+// TopUp refills key on dst with amount sent from the key of src over the path between them. The transfer
+// packet still has to be relayed to dst, which a running relayer on that path does.
+func TopUp(src, dst *Chain, key string, amount sdk.Coin) error {
+	addr, err := dst.ChainProvider.ShowAddress(key)
+	if err != nil {
+		return err
+	}
+	return src.SendTransferMsg(dst, amount, addr, 0, 0)
+}
@@ -0,0 +1,37 @@
+package relayer
+
+import (
+	"context"
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMonitorBalancesChecksFeeGranter(t *testing.T) {
+	m := useMetrics(t)
+	c := newMockChain(t, "ibc-0")
+	granter, err := c.ChainProvider.AddKey("granter")
+	require.NoError(t, err)
+	min := sdk.NewCoins(sdk.NewInt64Coin(testDenom, 1000))
+
+	// a single pass over the accounts of c, the monitor returns once ctx is done
+	check := func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		monitorBalances(ctx, c, min, 1)
+	}
+	lowGauge := func(key string) float64 {
+		return testutil.ToFloat64(m.WalletBalanceLow.WithLabelValues(c.ChainID(), key))
+	}
+
+	check()
+	require.Equal(t, float64(0), lowGauge(c.ChainProvider.Key()))
+	require.Equal(t, float64(0), lowGauge(feeGranterLabel), "no fee granter is set")
+
+	c.ChainProvider.SetFeeGranter(granter.Address)
+	check()
+	require.Equal(t, float64(0), lowGauge(c.ChainProvider.Key()))
+	require.Equal(t, float64(1), lowGauge(feeGranterLabel))
+}
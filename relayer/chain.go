@@ -29,6 +29,7 @@ import (
 
 	cdctypes "github.com/cosmos/cosmos-sdk/codec/types"
 	simparams "github.com/cosmos/cosmos-sdk/simapp/params"
+	sdk "github.com/cosmos/cosmos-sdk/types"
 	distrclient "github.com/cosmos/cosmos-sdk/x/distribution/client"
 	feegrant "github.com/cosmos/cosmos-sdk/x/feegrant/module"
 	"github.com/cosmos/cosmos-sdk/x/params"
@@ -46,6 +47,10 @@ var (
 	RtyDel    = retry.Delay(time.Millisecond * 400)
 	RtyErr    = retry.LastErrorOnly(true)
 
+	// RtyIfNotDryRun stops retrying once a tx has been simulated in dry-run mode, as it would only be
+	// simulated again
+	RtyIfNotDryRun = retry.RetryIf(func(err error) bool { return !isDryRun(err) })
+
 	ModuleBasics = []module.AppModuleBasic{
 		auth.AppModuleBasic{},
 		authz.AppModuleBasic{},
@@ -77,8 +82,9 @@ type Chain struct {
 	PathEnd  *PathEnd                 `yaml:"-" json:"-"`
 	Encoding simparams.EncodingConfig `yaml:"-" json:"-"`
 
-	logger log.Logger
-	debug  bool
+	logger   log.Logger
+	debug    bool
+	pathName string
 }
 
 func MakeCodec(moduleBasics []module.AppModuleBasic) simparams.EncodingConfig {
@@ -166,11 +172,30 @@ func (c *Chain) Init(logger log.Logger, debug bool) {
 	if c.logger == nil {
 		c.logger = defaultChainLogger()
 	}
+	c.logger = c.logger.With("chain_id", c.ChainID())
+	c.ChainProvider.SetLogger(c.logger)
 
 	// TODO logging/encoding needs refactored
 	c.Encoding = MakeCodec(ModuleBasics)
 }
 
+// WithPath returns a copy of the chain set to the given path end of the named path. The copy shares
+// the ChainProvider of c, so a chain used by several paths keeps a single set of connections.
+func (c *Chain) WithPath(pathName string, p *PathEnd) (*Chain, error) {
+	pc := *c
+	pc.pathName = pathName
+	pc.logger = c.logger.With("path", pathName)
+	if err := pc.SetPath(p); err != nil {
+		return nil, err
+	}
+	return &pc, nil
+}
+
+// PathName returns the name of the path the chain was set to with WithPath
+func (c *Chain) PathName() string {
+	return c.pathName
+}
+
 func defaultChainLogger() log.Logger {
 	return log.NewTMLogger(log.NewSyncWriter(os.Stdout))
 }
@@ -326,6 +351,170 @@ func (c *Chain) CreateTestKey() error {
 	return err
 }
 
+// CreateKeyPool adds size keys named after the key of the chain to its keychain and makes them its key
+// pool. Keys of that name that already exist are reused. The name of each key is returned along with its
+// address and, for the keys that were added, its mnemonic.
+func (c *Chain) CreateKeyPool(size int) ([]string, map[string]*provider.KeyOutput, error) {
+	var (
+		keys    = make([]string, 0, size)
+		outputs = make(map[string]*provider.KeyOutput, size)
+	)
+	for i := 1; i <= size; i++ {
+		name := fmt.Sprintf("%s-%d", c.ChainProvider.Key(), i)
+		if c.ChainProvider.KeyExists(name) {
+			addr, err := c.ChainProvider.ShowAddress(name)
+			if err != nil {
+				return nil, nil, err
+			}
+			outputs[name] = &provider.KeyOutput{Address: addr}
+		} else {
+			ko, err := c.ChainProvider.AddKey(name)
+			if err != nil {
+				return nil, nil, err
+			}
+			outputs[name] = ko
+		}
+		keys = append(keys, name)
+	}
+	c.ChainProvider.SetKeyPool(keys)
+	return keys, outputs, nil
+}
+
+// FundKeyPool sends amount from the key of the chain to every key of its pool in a single transaction
+func (c *Chain) FundKeyPool(amount sdk.Coins) error {
+	keys := c.ChainProvider.KeyPool()
+	if len(keys) == 0 {
+		return fmt.Errorf("chain %s has no key pool", c.ChainID())
+	}
+
+	var msgs []provider.RelayerMessage
+	for _, name := range keys {
+		addr, err := c.ChainProvider.ShowAddress(name)
+		if err != nil {
+			return err
+		}
+		msg, err := c.ChainProvider.MsgSend(addr, amount)
+		if err != nil {
+			return err
+		}
+		msgs = append(msgs, msg)
+	}
+
+	res, success, err := c.ChainProvider.SendMessages(msgs)
+	switch {
+	case err != nil:
+		return err
+	case !success:
+		return fmt.Errorf("failed to fund the key pool of chain %s: tx(%s) failed with code %d", c.ChainID(), res.TxHash, res.Code)
+	}
+	c.Log(fmt.Sprintf("★ [%s]@{%d} sent %s to each of %d pool keys, tx(%s)", c.ChainID(), res.Height, amount, len(keys), res.TxHash))
+	return nil
+}
+
+// hotKeys returns the key of the chain along with the keys of its pool
+func (c *Chain) hotKeys() []string {
+	keys := []string{c.ChainProvider.Key()}
+	for _, key := range c.ChainProvider.KeyPool() {
+		if key != c.ChainProvider.Key() {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// GrantFeeAllowances grants a fee allowance from the key of treasury to the key and key pool of the chain in
+// a single transaction and makes the treasury pay the fees of the chain's transactions
+func (c *Chain) GrantFeeAllowances(treasury provider.ChainProvider, spendLimit sdk.Coins, expiration *time.Time) error {
+	msgs, err := c.allowanceMsgs(func(grantee string) (provider.RelayerMessage, error) {
+		return treasury.MsgGrantAllowance(grantee, spendLimit, expiration)
+	})
+	if err != nil {
+		return err
+	}
+	if err = c.sendGranterMsgs(treasury, msgs, "granted fee allowances"); err != nil {
+		return err
+	}
+
+	granter, err := treasury.Address()
+	if err != nil {
+		return err
+	}
+	c.ChainProvider.SetFeeGranter(granter)
+	return nil
+}
+
+// RevokeFeeAllowances revokes the fee allowances the key of treasury granted to the key and key pool of the
+// chain, after which the chain's keys pay their own fees again
+func (c *Chain) RevokeFeeAllowances(treasury provider.ChainProvider) error {
+	msgs, err := c.allowanceMsgs(treasury.MsgRevokeAllowance)
+	if err != nil {
+		return err
+	}
+	if err = c.sendGranterMsgs(treasury, msgs, "revoked fee allowances"); err != nil {
+		return err
+	}
+	c.ChainProvider.SetFeeGranter("")
+	return nil
+}
+
+// allowanceMsgs builds a fee allowance msg for every hot key of the chain
+func (c *Chain) allowanceMsgs(newMsg func(grantee string) (provider.RelayerMessage, error)) ([]provider.RelayerMessage, error) {
+	var msgs []provider.RelayerMessage
+	for _, key := range c.hotKeys() {
+		addr, err := c.ChainProvider.ShowAddress(key)
+		if err != nil {
+			return nil, err
+		}
+		msg, err := newMsg(addr)
+		if err != nil {
+			return nil, err
+		}
+		msgs = append(msgs, msg)
+	}
+	return msgs, nil
+}
+
+// GrantRelayAuthorizations grants authz authorizations from the key of granter to the key and key pool of the
+// chain for every relay msg, and makes the chain relay on behalf of granter
+func (c *Chain) GrantRelayAuthorizations(granter provider.ChainProvider, expiration time.Time) error {
+	var msgs []provider.RelayerMessage
+	for _, key := range c.hotKeys() {
+		addr, err := c.ChainProvider.ShowAddress(key)
+		if err != nil {
+			return err
+		}
+		grants, err := granter.MsgGrantRelayAuthorizations(addr, expiration)
+		if err != nil {
+			return err
+		}
+		msgs = append(msgs, grants...)
+	}
+	if err := c.sendGranterMsgs(granter, msgs, "granted relay authorizations"); err != nil {
+		return err
+	}
+
+	addr, err := granter.Address()
+	if err != nil {
+		return err
+	}
+	c.ChainProvider.SetAuthzGranter(addr)
+	return nil
+}
+
+// sendGranterMsgs sends the grant msgs of the key of granter to the keys of the chain
+func (c *Chain) sendGranterMsgs(granter provider.ChainProvider, msgs []provider.RelayerMessage, action string) error {
+	res, success, err := granter.SendMessages(msgs)
+	switch {
+	case err != nil:
+		return err
+	case !success:
+		return fmt.Errorf("failed to send grants on chain %s: tx(%s) failed with code %d", c.ChainID(), res.TxHash, res.Code)
+	}
+	c.Log(fmt.Sprintf("★ [%s]@{%d} %s from key {%s} to %d keys, tx(%s)",
+		c.ChainID(), res.Height, action, granter.Key(), len(c.hotKeys()), res.TxHash))
+	return nil
+}
+
 // GetTimeout returns the chain's configured timeout
 func (c *Chain) GetTimeout() (time.Duration, error) {
 	timeout, err := time.ParseDuration(c.ChainProvider.Timeout())
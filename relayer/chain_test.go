@@ -0,0 +1,26 @@
+package relayer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateKeyPoolMnemonics(t *testing.T) {
+	c := newMockChain(t, "ibc-0")
+	_, err := c.ChainProvider.AddKey("testkey-1")
+	require.NoError(t, err)
+
+	keys, outputs, err := c.CreateKeyPool(2)
+	require.NoError(t, err)
+	require.Equal(t, []string{"testkey-1", "testkey-2"}, keys)
+	require.Equal(t, keys, c.ChainProvider.KeyPool())
+
+	// the mnemonic of an existing key is not known, the new key comes with its mnemonic to back it up
+	require.Empty(t, outputs["testkey-1"].Mnemonic)
+	require.NotEmpty(t, outputs["testkey-1"].Address)
+	require.NotEmpty(t, outputs["testkey-2"].Mnemonic)
+	addr, err := c.ChainProvider.RestoreKey("restored", outputs["testkey-2"].Mnemonic)
+	require.NoError(t, err)
+	require.Equal(t, outputs["testkey-2"].Address, addr)
+}
@@ -28,6 +28,10 @@ func (c *Chain) CreateOpenChannels(dst *Chain, maxRetries uint64, to time.Durati
 	for ; true; <-ticker.C {
 		var err error
 		success, lastStep, recentlyModified, err := ExecuteChannelStep(c, dst)
+		if isDryRun(err) {
+			// the later steps depend on this one being committed, so there is nothing more to simulate
+			return modified, err
+		}
 		if err != nil {
 			c.Log(err.Error())
 		}
@@ -565,7 +569,11 @@ func (c *Chain) CloseChannel(dst *Chain, to time.Duration) error {
 			break
 		}
 
-		if closeSteps.Send(c, dst); closeSteps.Success() && closeSteps.Last {
+		closeSteps.Send(c, dst)
+		if closeSteps.simulated() {
+			return provider.ErrDryRun
+		}
+		if closeSteps.Success() && closeSteps.Last {
 			srcChan, dstChan, err := QueryChannelPair(c, dst, 0, 0)
 			if err != nil {
 				return err
@@ -0,0 +1,348 @@
+package relayer
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cosmos/relayer/relayer/provider"
+	"golang.org/x/sync/errgroup"
+)
+
+// channelRefreshInterval is how often the relay loops look up the channels of their path again, so
+// channels opened on its connection after the relayer started are relayed as well
+const channelRefreshInterval = time.Minute
+
+// ChannelEnds are the chains of a path set to the ends of one of the channels it relays
+type ChannelEnds struct {
+	Src, Dst *Chain
+}
+
+// ChannelSet is the set of channels a path relays over its connection: the channel of the path ends and
+// the channels listed in its Channels. Refresh looks the channels up again, so a wildcard also covers the
+// channels opened after the set was created.
+type ChannelSet struct {
+	name     string
+	path     *Path
+	src, dst *Chain
+
+	mu       sync.Mutex
+	channels []*ChannelEnds
+}
+
+// NewChannelSet returns the set of channels relayed by the named path between src and dst
+func NewChannelSet(name string, path *Path, src, dst *Chain) (*ChannelSet, error) {
+	cs := &ChannelSet{name: name, path: path, src: src, dst: dst}
+	if err := cs.Refresh(); err != nil {
+		return nil, err
+	}
+	return cs, nil
+}
+
+// channelSetOf returns a set holding only the channel that src and dst are set to
+func channelSetOf(src, dst *Chain) *ChannelSet {
+	return &ChannelSet{src: src, dst: dst, channels: []*ChannelEnds{{Src: src, Dst: dst}}}
+}
+
+// Refresh looks up the channels of the path again. The channels found for the first time are logged,
+// the ones that were relayed before keep their chains.
+func (cs *ChannelSet) Refresh() error {
+	if cs.path == nil {
+		return nil
+	}
+	paths, err := cs.path.ChannelPaths(cs.src)
+	if err != nil {
+		return err
+	}
+
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	known := make(map[string]*ChannelEnds)
+	for _, ch := range cs.channels {
+		known[ch.Src.PathEnd.ChannelID] = ch
+	}
+	channels := make([]*ChannelEnds, 0, len(paths))
+	for _, p := range paths {
+		if ch, ok := known[p.Src.ChannelID]; ok {
+			channels = append(channels, ch)
+			continue
+		}
+		src, err := cs.src.WithPath(cs.name, p.Src)
+		if err != nil {
+			return err
+		}
+		dst, err := cs.dst.WithPath(cs.name, p.Dst)
+		if err != nil {
+			return err
+		}
+		if cs.channels != nil {
+			src.Log(fmt.Sprintf("- [%s] relaying channel [%s]chan{%s} <-> [%s]chan{%s}",
+				cs.name, src.ChainID(), src.PathEnd.ChannelID, dst.ChainID(), dst.PathEnd.ChannelID))
+		}
+		channels = append(channels, &ChannelEnds{Src: src, Dst: dst})
+	}
+	cs.channels = channels
+	return nil
+}
+
+// refresh refreshes the set, logging a failed channel lookup
+func (cs *ChannelSet) refresh() {
+	if err := cs.Refresh(); err != nil {
+		cs.src.Log(fmt.Sprintf("[%s] channel lookup error: %s", cs.name, err))
+	}
+}
+
+// Channels returns the channels in the set
+func (cs *ChannelSet) Channels() []*ChannelEnds {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	return append([]*ChannelEnds(nil), cs.channels...)
+}
+
+// Unrelayed scans every channel in the set for unrelayed packets, if packets is set, and unrelayed
+// acknowledgements, if acks is set. A channel whose scan fails is logged and left out.
+func (cs *ChannelSet) Unrelayed(packets, acks bool) []*ChannelSequences {
+	var out []*ChannelSequences
+	for _, ch := range cs.Channels() {
+		src, dst := ch.Src, ch.Dst
+		seqs := &ChannelSequences{Src: src, Dst: dst, Packets: &RelaySequences{}, Acks: &RelaySequences{}}
+
+		var err error
+		if packets {
+			if seqs.Packets, err = UnrelayedSequences(src, dst); err != nil {
+				src.Log(fmt.Sprintf("unrelayed sequences error on channel %s: %s", src.PathEnd.ChannelID, err))
+				continue
+			}
+			if len(seqs.Packets.Src) > 0 && src.debug {
+				src.Log(fmt.Sprintf("[%s] unrelayed-packets-> %v", src.ChainID(), seqs.Packets.Src))
+			}
+			if len(seqs.Packets.Dst) > 0 && dst.debug {
+				dst.Log(fmt.Sprintf("[%s] unrelayed-packets-> %v", dst.ChainID(), seqs.Packets.Dst))
+			}
+		}
+		if acks {
+			if seqs.Acks, err = UnrelayedAcknowledgements(src, dst); err != nil {
+				src.Log(fmt.Sprintf("unrelayed acks error on channel %s: %s", src.PathEnd.ChannelID, err))
+				continue
+			}
+			if len(seqs.Acks.Src) > 0 && src.debug {
+				src.Log(fmt.Sprintf("[%s] unrelayed-acks-> %v", src.ChainID(), seqs.Acks.Src))
+			}
+			if len(seqs.Acks.Dst) > 0 && dst.debug {
+				dst.Log(fmt.Sprintf("[%s] unrelayed-acks-> %v", dst.ChainID(), seqs.Acks.Dst))
+			}
+		}
+		out = append(out, seqs)
+	}
+	return out
+}
+
+// RelayUnrelayed relays the unrelayed packets, if packets is set, and the unrelayed acknowledgements, if
+// acks is set, of every channel in the set in one round
+func (cs *ChannelSet) RelayUnrelayed(packets, acks bool, maxTxSize, maxMsgLength uint64) error {
+	channels := cs.Unrelayed(packets, acks)
+	if err := RelayChannels(channels, maxTxSize, maxMsgLength); err != nil {
+		return err
+	}
+	return incompleteChannels(channels)
+}
+
+// ChannelSequences are the packets and acknowledgements of one channel of a path to relay in a round
+type ChannelSequences struct {
+	Src, Dst      *Chain
+	Packets, Acks *RelaySequences
+
+	// FailedPackets and FailedAcks are set by RelayChannels to the sequences whose txs failed or were dropped
+	FailedPackets, FailedAcks *RelaySequences
+}
+
+// RelayChannels relays the packets and acknowledgements of channels that share the clients and connection
+// of a path in one round. The msgs of all channels are sent behind a single client update on each chain.
+// Errors building the msgs abort the round, while the sequences whose txs failed are set on their channel.
+func RelayChannels(channels []*ChannelSequences, maxTxSize, maxMsgLength uint64) error {
+	// drop the packets the path's filter does not allow and those that in-flight txs already relay
+	var (
+		ready []*ChannelSequences
+		err   error
+	)
+	for _, ch := range channels {
+		ch.FailedPackets = &RelaySequences{Src: []uint64{}, Dst: []uint64{}}
+		ch.FailedAcks = &RelaySequences{Src: []uint64{}, Dst: []uint64{}}
+		if ch.Packets == nil {
+			ch.Packets = &RelaySequences{}
+		}
+		if ch.Acks == nil {
+			ch.Acks = &RelaySequences{}
+		}
+		if !ch.Packets.Empty() {
+			if ch.Packets, err = FilterPackets(ch.Src, ch.Dst, ch.Packets); err != nil {
+				return err
+			}
+			if ch.Packets, err = state.dropInFlight(ch.Src, ch.Dst, ch.Packets, false); err != nil {
+				return err
+			}
+		}
+		if !ch.Acks.Empty() {
+			if ch.Acks, err = state.dropInFlight(ch.Src, ch.Dst, ch.Acks, true); err != nil {
+				return err
+			}
+		}
+		if !ch.Packets.Empty() || !ch.Acks.Empty() {
+			ready = append(ready, ch)
+		}
+	}
+	if len(ready) == 0 {
+		return nil
+	}
+
+	// the chains of the first channel stand in for the path, whose client and connection all channels share
+	src, dst := ready[0].Src, ready[0].Dst
+	msgs := &RelayMsgs{
+		Src:          []provider.RelayerMessage{},
+		Dst:          []provider.RelayerMessage{},
+		MaxTxSize:    maxTxSize,
+		MaxMsgLength: maxMsgLength,
+	}
+
+	srch, dsth, err := QueryLatestHeights(src, dst)
+	if err != nil {
+		return err
+	}
+
+	// count the msgs of every channel, to log them per channel and tell packets from acks in the metrics
+	type channelMsgs struct{ src, dst int }
+	var (
+		packetMsgs, ackMsgs channelMsgs
+		relayed             = make([]channelMsgs, len(ready))
+	)
+	for i, ch := range ready {
+		srcLen, dstLen := len(msgs.Src), len(msgs.Dst)
+		if err = AddMessagesForSequences(ch.Packets.Src, ch.Src, ch.Dst, srch, dsth, &msgs.Src, &msgs.Dst); err != nil {
+			return err
+		}
+		if err = AddMessagesForSequences(ch.Packets.Dst, ch.Dst, ch.Src, dsth, srch, &msgs.Dst, &msgs.Src); err != nil {
+			return err
+		}
+		packetMsgs.src += len(msgs.Src) - srcLen
+		packetMsgs.dst += len(msgs.Dst) - dstLen
+
+		ackSrcLen, ackDstLen := len(msgs.Src), len(msgs.Dst)
+		if err = addAcknowledgementMsgs(ch.Acks, ch.Src, ch.Dst, srch, dsth, msgs); err != nil {
+			return err
+		}
+		ackMsgs.src += len(msgs.Src) - ackSrcLen
+		ackMsgs.dst += len(msgs.Dst) - ackDstLen
+
+		relayed[i] = channelMsgs{src: len(msgs.Src) - srcLen, dst: len(msgs.Dst) - dstLen}
+	}
+
+	if !msgs.Ready() {
+		src.Log(fmt.Sprintf("- No packets or acknowledgements to relay between [%s]port{%s} and [%s]port{%s}",
+			src.ChainID(), src.PathEnd.PortID, dst.ChainID(), dst.PathEnd.PortID))
+		return nil
+	}
+
+	// Prepend non-empty msg lists with UpdateClient
+	eg := new(errgroup.Group)
+	eg.Go(func() error {
+		return PrependUpdateClientMsg(&msgs.Dst, src, dst, srch)
+	})
+	eg.Go(func() error {
+		return PrependUpdateClientMsg(&msgs.Src, dst, src, dsth)
+	})
+	if err = eg.Wait(); err != nil {
+		return err
+	}
+
+	// send messages to their respective chains
+	if msgs.Send(src, dst); msgs.Success() {
+		path := pathLabel(src, dst)
+		state.setPathHeights(src, dst, srch, dsth)
+		for i, ch := range ready {
+			if relayed[i].dst > 0 {
+				ch.Dst.logPacketsRelayed(ch.Src, relayed[i].dst)
+			}
+			if relayed[i].src > 0 {
+				ch.Src.logPacketsRelayed(ch.Dst, relayed[i].src)
+			}
+		}
+		Metrics.PacketsRelayed.WithLabelValues(path, dst.ChainID()).Add(float64(packetMsgs.dst))
+		Metrics.PacketsRelayed.WithLabelValues(path, src.ChainID()).Add(float64(packetMsgs.src))
+		Metrics.AcknowledgementsRelayed.WithLabelValues(path, dst.ChainID()).Add(float64(ackMsgs.dst))
+		Metrics.AcknowledgementsRelayed.WithLabelValues(path, src.ChainID()).Add(float64(ackMsgs.src))
+		logTimeouts(path, src, msgs.Src)
+		logTimeouts(path, dst, msgs.Dst)
+	}
+
+	for _, ch := range ready {
+		ch.FailedPackets = msgs.failedSequences(ch.Src, ch.Dst, false)
+		ch.FailedAcks = msgs.failedSequences(ch.Src, ch.Dst, true)
+	}
+	return nil
+}
+
+// addAcknowledgementMsgs adds the msgs relaying the acknowledgements of sp between src and dst to msgs,
+// proving them at the heights srch and dsth
+func addAcknowledgementMsgs(sp *RelaySequences, src, dst *Chain, srch, dsth int64, msgs *RelayMsgs) error {
+	// add messages for received packets on dst
+	for _, seq := range sp.Dst {
+		// dst wrote the ack. acknowledgementFromSequence will query the acknowledgement
+		// from the counterparty chain (second chain provided in the arguments). The message
+		// should be sent to src.
+		relayAckMsgs, err := src.ChainProvider.AcknowledgementFromSequence(dst.ChainProvider, uint64(dsth), seq, dst.PathEnd.ChannelID, dst.PathEnd.PortID, src.PathEnd.ChannelID, src.PathEnd.PortID)
+		if err != nil {
+			return err
+		}
+
+		msgs.Src = append(msgs.Src, relayAckMsgs)
+	}
+
+	// add messages for received packets on src
+	for _, seq := range sp.Src {
+		// src wrote the ack. acknowledgementFromSequence will query the acknowledgement
+		// from the counterparty chain (second chain provided in the arguments). The message
+		// should be sent to dst.
+		relayAckMsgs, err := dst.ChainProvider.AcknowledgementFromSequence(src.ChainProvider, uint64(srch), seq, src.PathEnd.ChannelID, src.PathEnd.PortID, dst.PathEnd.ChannelID, dst.PathEnd.PortID)
+		if err != nil {
+			return err
+		}
+
+		msgs.Dst = append(msgs.Dst, relayAckMsgs)
+	}
+	return nil
+}
+
+// relayChannels relays the sequences of channels in one round, logging the sequences whose txs failed
+func relayChannels(channels []*ChannelSequences, maxTxSize, maxMsgLength uint64) {
+	if len(channels) == 0 {
+		return
+	}
+	if err := RelayChannels(channels, maxTxSize, maxMsgLength); err != nil {
+		channels[0].Src.Log(fmt.Sprintf("relay error: %s", err))
+		return
+	}
+	if err := incompleteChannels(channels); err != nil {
+		channels[0].Src.Log(fmt.Sprintf("relay error: %s", err))
+	}
+}
+
+// incompleteChannels returns an error listing the sequences of channels whose relay txs failed, if any
+func incompleteChannels(channels []*ChannelSequences) error {
+	var failed []string
+	for _, ch := range channels {
+		if ch.FailedPackets != nil && !ch.FailedPackets.Empty() {
+			failed = append(failed, fmt.Sprintf("packets on %s: %s", ch.Src.PathEnd.ChannelID,
+				&RelayIncompleteError{Sequences: ch.FailedPackets}))
+		}
+		if ch.FailedAcks != nil && !ch.FailedAcks.Empty() {
+			failed = append(failed, fmt.Sprintf("acks on %s: %s", ch.Src.PathEnd.ChannelID,
+				&RelayIncompleteError{Sequences: ch.FailedAcks}))
+		}
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%s", strings.Join(failed, "; "))
+}
@@ -0,0 +1,48 @@
+package relayer
+
+import (
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRelayChannelsSharesClientUpdate(t *testing.T) {
+	src, dst := newMockLink(t)
+	calls := &callsProvider{ChainProvider: dst.ChainProvider}
+	dst.ChainProvider = calls
+
+	path := &Path{Src: src.PathEnd, Dst: dst.PathEnd, Channels: []string{ChannelWildcard}}
+	cs, err := NewChannelSet("test", path, src, dst)
+	require.NoError(t, err)
+	require.Len(t, cs.Channels(), 1)
+
+	// a channel opened on the connection after the set was created is picked up by a refresh
+	srcEnd, dstEnd := *src.PathEnd, *dst.PathEnd
+	srcEnd.ChannelID, dstEnd.ChannelID = "", ""
+	// a different version keeps the handshake from picking the open channel of the path
+	srcEnd.Version, dstEnd.Version = "ics20-2", "ics20-2"
+	src2, err := src.WithPath("test", &srcEnd)
+	require.NoError(t, err)
+	dst2, err := dst.WithPath("test", &dstEnd)
+	require.NoError(t, err)
+	_, err = src2.CreateOpenChannels(dst2, 3, testStepTimeout)
+	require.NoError(t, err)
+	require.NoError(t, cs.Refresh())
+	require.Len(t, cs.Channels(), 2)
+
+	dstAddr, err := dst.ChainProvider.Address()
+	require.NoError(t, err)
+	require.NoError(t, src.SendTransferMsg(dst, sdk.NewInt64Coin(testDenom, 1000), dstAddr, 0, 0))
+	require.NoError(t, src2.SendTransferMsg(dst2, sdk.NewInt64Coin(testDenom, 500), dstAddr, 0, 0))
+	requireBalance(t, src, testDenom, 8500)
+
+	// the packets of both channels are received on dst in one tx, behind a single client update
+	calls.mu.Lock()
+	calls.calls = nil
+	calls.mu.Unlock()
+	require.NoError(t, cs.RelayUnrelayed(true, false, testMaxTxSize, testMaxMsgLength))
+	require.Equal(t, []string{"broadcast", "wait"}, calls.calls)
+	requireBalance(t, dst, voucherDenom(dst, testDenom), 1000)
+	requireBalance(t, dst, voucherDenom(dst2, testDenom), 500)
+}
@@ -0,0 +1,253 @@
+package relayer
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	clienttypes "github.com/cosmos/ibc-go/v2/modules/core/02-client/types"
+	tmclient "github.com/cosmos/ibc-go/v2/modules/light-clients/07-tendermint/types"
+	"github.com/cosmos/relayer/relayer/provider"
+)
+
+// clientExpiryCheckInterval is how often the client expiry monitor lists the clients of a chain
+const clientExpiryCheckInterval = 10 * time.Minute
+
+// Client statuses reported by ClientExpiry
+const (
+	ClientActive   = "active"
+	ClientExpiring = "expiring"
+	ClientExpired  = "expired"
+	ClientFrozen   = "frozen"
+	ClientUnknown  = "unknown"
+)
+
+// ClientExpiry describes when a tendermint light client on a chain runs out of its trusting period
+type ClientExpiry struct {
+	ChainID             string    `json:"chain-id"`
+	ClientID            string    `json:"client-id"`
+	CounterpartyChainID string    `json:"counterparty-chain-id"`
+	TrustingPeriod      string    `json:"trusting-period"`
+	LastUpdate          time.Time `json:"last-update"`
+	Expiry              time.Time `json:"expiry"`
+	Status              string    `json:"status"`
+	Error               string    `json:"error,omitempty"`
+}
+
+// QueryClientExpiries returns the trusting period deadline of every tendermint client on c, soonest
+// first. Clients within threshold of their deadline are reported as expiring. A client whose state
+// can't be read is listed with its error and an unknown status rather than failing the whole listing.
+func QueryClientExpiries(c *Chain, threshold time.Duration) ([]*ClientExpiry, error) {
+	height, err := c.ChainProvider.QueryLatestHeight()
+	if err != nil {
+		return nil, err
+	}
+	clients, err := c.ChainProvider.QueryClients()
+	if err != nil {
+		return nil, err
+	}
+
+	var expiries []*ClientExpiry
+	for _, client := range clients {
+		cs, err := clienttypes.UnpackClientState(client.ClientState)
+		if err != nil {
+			expiries = append(expiries, unknownClientExpiry(c, client.ClientId, err))
+			continue
+		}
+		// only tendermint clients have a trusting period
+		clientState, ok := cs.(*tmclient.ClientState)
+		if !ok {
+			continue
+		}
+		expiry, err := clientExpiry(c, height, client.ClientId, clientState, threshold)
+		if err != nil {
+			expiry = unknownClientExpiry(c, client.ClientId, fmt.Errorf("failed to query consensus state: %w", err))
+			expiry.CounterpartyChainID = clientState.ChainId
+			expiry.TrustingPeriod = clientState.TrustingPeriod.String()
+		}
+		expiries = append(expiries, expiry)
+	}
+
+	sort.Slice(expiries, func(i, j int) bool {
+		return expiries[i].Expiry.Before(expiries[j].Expiry)
+	})
+	return expiries, nil
+}
+
+// QueryClientExpiry returns the trusting period deadline of the tendermint client with the given id on c.
+// The client is reported as expiring once it is within threshold of its deadline.
+func QueryClientExpiry(c *Chain, clientID string, threshold time.Duration) (*ClientExpiry, error) {
+	height, err := c.ChainProvider.QueryLatestHeight()
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.ChainProvider.QueryClientStateResponse(height, clientID)
+	if err != nil {
+		return nil, err
+	}
+	cs, err := clienttypes.UnpackClientState(res.ClientState)
+	if err != nil {
+		return nil, err
+	}
+	clientState, ok := cs.(*tmclient.ClientState)
+	if !ok {
+		return nil, fmt.Errorf("client %s is not an IBC tendermint client", clientID)
+	}
+	return clientExpiry(c, height, clientID, clientState, threshold)
+}
+
+// ReportPathClientExpiries logs and notifies that the clients of the path between src and dst are within
+// threshold of their expiry, ahead of updating them, so an update that keeps failing is noticed while
+// the clients can still be saved
+func ReportPathClientExpiries(src, dst *Chain, threshold time.Duration) error {
+	for _, c := range []*Chain{src, dst} {
+		expiry, err := QueryClientExpiry(c, c.ClientID(), threshold)
+		if err != nil {
+			return err
+		}
+		reportClientExpiry(c, expiry, ClientActive)
+	}
+	return nil
+}
+
+// clientExpiry computes the deadline of a client from the timestamp of its latest consensus state,
+// the same way AutoUpdateClient does
+func clientExpiry(c *Chain, height int64, clientID string, clientState *tmclient.ClientState, threshold time.Duration) (*ClientExpiry, error) {
+	res, err := c.ChainProvider.QueryClientConsensusState(height, clientID, clientState.GetLatestHeight())
+	if err != nil {
+		return nil, err
+	}
+	exportedConsState, err := clienttypes.UnpackConsensusState(res.ConsensusState)
+	if err != nil {
+		return nil, err
+	}
+	consensusState, ok := exportedConsState.(*tmclient.ConsensusState)
+	if !ok {
+		return nil, fmt.Errorf("consensus state of client %s is not IBC tendermint type", clientID)
+	}
+
+	expiry := &ClientExpiry{
+		ChainID:             c.ChainID(),
+		ClientID:            clientID,
+		CounterpartyChainID: clientState.ChainId,
+		TrustingPeriod:      clientState.TrustingPeriod.String(),
+		LastUpdate:          consensusState.Timestamp,
+		Expiry:              consensusState.Timestamp.Add(clientState.TrustingPeriod),
+		Status:              ClientActive,
+	}
+	switch {
+	case !clientState.FrozenHeight.IsZero():
+		expiry.Status = ClientFrozen
+	case clientState.IsExpired(consensusState.Timestamp, time.Now()):
+		expiry.Status = ClientExpired
+	case time.Until(expiry.Expiry) <= threshold:
+		expiry.Status = ClientExpiring
+	}
+	return expiry, nil
+}
+
+// unknownClientExpiry lists a client whose deadline couldn't be computed along with the reason
+func unknownClientExpiry(c *Chain, clientID string, err error) *ClientExpiry {
+	return &ClientExpiry{
+		ChainID:  c.ChainID(),
+		ClientID: clientID,
+		Status:   ClientUnknown,
+		Error:    err.Error(),
+	}
+}
+
+// StartClientExpiryMonitor checks every client on c, including those of paths that aren't relayed, and
+// reports the ones that come within threshold of their expiry, until the returned func is called.
+// With update set, expiring clients whose counterparty is one of chains are updated as well.
+func StartClientExpiryMonitor(c *Chain, chains map[string]*Chain, threshold time.Duration, update bool) func() {
+	ctx, cancel := context.WithCancel(context.Background())
+	go monitorClientExpiries(ctx, c, chains, threshold, update)
+	return cancel
+}
+
+// monitorClientExpiries lists the clients of c every clientExpiryCheckInterval and reports the status
+// of a client whenever it changes
+func monitorClientExpiries(ctx context.Context, c *Chain, chains map[string]*Chain, threshold time.Duration, update bool) {
+	statuses := make(map[string]string)
+	for {
+		expiries, err := QueryClientExpiries(c, threshold)
+		if err != nil {
+			c.Error(fmt.Errorf("client expiry monitor failed to query clients: %w", err))
+		}
+		for _, expiry := range expiries {
+			if expiry.Error != "" {
+				c.Error(fmt.Errorf("client expiry monitor failed to check client %s: %s", expiry.ClientID, expiry.Error))
+				continue
+			}
+			if expiry.Status == ClientExpiring && update {
+				if dst, ok := chains[expiry.CounterpartyChainID]; ok {
+					if err = updateClient(c, dst, expiry.ClientID); err != nil {
+						c.Error(fmt.Errorf("failed to update client %s: %w", expiry.ClientID, err))
+					} else {
+						expiry.Status = ClientActive
+					}
+				}
+			}
+			reportClientExpiry(c, expiry, statuses[expiry.ClientID])
+			statuses[expiry.ClientID] = expiry.Status
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(clientExpiryCheckInterval):
+		}
+	}
+}
+
+// reportClientExpiry logs and notifies that a client became expiring, expired or frozen. Clients seen
+// for the first time in one of those states are reported too.
+func reportClientExpiry(c *Chain, expiry *ClientExpiry, previous string) {
+	if expiry.Status == previous || expiry.Status == ClientActive {
+		return
+	}
+
+	var msg string
+	switch expiry.Status {
+	case ClientExpiring:
+		msg = fmt.Sprintf("[%s]client(%s) of chain %s expires in %s", c.ChainID(), expiry.ClientID,
+			expiry.CounterpartyChainID, time.Until(expiry.Expiry).Round(time.Second))
+	default:
+		msg = fmt.Sprintf("[%s]client(%s) of chain %s is %s", c.ChainID(), expiry.ClientID,
+			expiry.CounterpartyChainID, expiry.Status)
+	}
+	c.Log(fmt.Sprintf("✘ %s", msg))
+	notifier.Notify(&Notification{
+		Event:   EventClientExpiry,
+		ChainID: c.ChainID(),
+		Subject: c.ChainID() + "/" + expiry.ClientID,
+		Message: msg,
+	})
+}
+
+// updateClient updates the client with the given id on c, which tracks dst, to the latest height of dst
+func updateClient(c, dst *Chain, clientID string) error {
+	dsth, err := dst.ChainProvider.QueryLatestHeight()
+	if err != nil {
+		return err
+	}
+	header, err := dst.ChainProvider.GetIBCUpdateHeader(dsth, c.ChainProvider, clientID)
+	if err != nil {
+		return err
+	}
+	msg, err := c.ChainProvider.UpdateClient(clientID, header)
+	if err != nil {
+		return err
+	}
+
+	res, success, err := c.ChainProvider.SendMessages([]provider.RelayerMessage{msg})
+	if err != nil {
+		return err
+	}
+	if !success {
+		return fmt.Errorf("tx failed: %s", res.Data)
+	}
+	c.Log(fmt.Sprintf("★ Client updated: [%s]client(%s) to [%s]@{%d}", c.ChainID(), clientID, dst.ChainID(), dsth))
+	return nil
+}
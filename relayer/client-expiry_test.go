@@ -0,0 +1,94 @@
+package relayer
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	clienttypes "github.com/cosmos/ibc-go/v2/modules/core/02-client/types"
+	ibcexported "github.com/cosmos/ibc-go/v2/modules/core/exported"
+	"github.com/cosmos/relayer/relayer/provider"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReportPathClientExpiries(t *testing.T) {
+	src, dst := newMockPath(t)
+	// the clients are queried with proofs, which need the block they were created in to be committed
+	require.NoError(t, src.ChainProvider.WaitForNBlocks(1))
+	require.NoError(t, dst.ChainProvider.WaitForNBlocks(1))
+
+	notes := make(chan *Notification, 2)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var note Notification
+		if err := json.NewDecoder(r.Body).Decode(&note); err == nil {
+			notes <- &note
+		}
+	}))
+	defer server.Close()
+
+	n, err := NewNotifier(&NotifierConfig{Targets: []*NotifyTarget{{Name: "test", URL: server.URL}}}, nil)
+	require.NoError(t, err)
+	SetNotifier(n)
+	defer SetNotifier(nil)
+
+	// clients far from their expiry are not reported
+	require.NoError(t, ReportPathClientExpiries(src, dst, time.Minute))
+	WaitNotifications()
+	require.Empty(t, notes)
+
+	// a threshold beyond the trusting period puts both clients within it, ahead of any update
+	tp, err := src.GetTrustingPeriod()
+	require.NoError(t, err)
+	require.NoError(t, ReportPathClientExpiries(src, dst, 2*tp))
+	WaitNotifications()
+	require.Len(t, notes, 2)
+	for i := 0; i < 2; i++ {
+		note := <-notes
+		require.Equal(t, EventClientExpiry, note.Event)
+		require.Contains(t, note.Message, "expires in")
+	}
+}
+
+// failingConsensusProvider fails the consensus state queries of one client
+type failingConsensusProvider struct {
+	provider.ChainProvider
+	clientID string
+}
+
+func (p *failingConsensusProvider) QueryClientConsensusState(chainHeight int64, clientID string, clientHeight ibcexported.Height) (*clienttypes.QueryConsensusStateResponse, error) {
+	if clientID == p.clientID {
+		return nil, fmt.Errorf("consensus state not found")
+	}
+	return p.ChainProvider.QueryClientConsensusState(chainHeight, clientID, clientHeight)
+}
+
+func TestQueryClientExpiriesListsClientErrors(t *testing.T) {
+	src, dst := newMockPath(t)
+	// a second client on src, next to the one of the path
+	failing := src.ClientID()
+	src.PathEnd.ClientID, dst.PathEnd.ClientID = "", ""
+	_, err := src.CreateClients(dst, true, true, true)
+	require.NoError(t, err)
+	require.NotEqual(t, failing, src.ClientID())
+	require.NoError(t, src.ChainProvider.WaitForNBlocks(1))
+
+	src.ChainProvider = &failingConsensusProvider{ChainProvider: src.ChainProvider, clientID: failing}
+	expiries, err := QueryClientExpiries(src, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, expiries, 2)
+
+	// the client that can't be read is listed with its error, the other one is still checked
+	for _, expiry := range expiries {
+		if expiry.ClientID == failing {
+			require.Equal(t, ClientUnknown, expiry.Status)
+			require.Contains(t, expiry.Error, "consensus state not found")
+			require.Equal(t, dst.ChainID(), expiry.CounterpartyChainID)
+		} else {
+			require.Equal(t, ClientActive, expiry.Status)
+			require.Empty(t, expiry.Error)
+		}
+	}
+}
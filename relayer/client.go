@@ -46,14 +46,19 @@ func (c *Chain) CreateClients(dst *Chain, allowUpdateAfterExpiry, allowUpdateAft
 	}
 
 	// Create client on src for dst if the client id is unspecified
+	// in dry-run mode the client on dst is simulated as well, before reporting the dry run
 	modified, err = CreateClient(c, dst, srcUpdateHeader, dstUpdateHeader, allowUpdateAfterExpiry, allowUpdateAfterMisbehaviour, override)
-	if err != nil {
+	dryRun := isDryRun(err)
+	if err != nil && !dryRun {
 		return modified, fmt.Errorf("failed to create client on src chain{%s}. Err: %w", c.ChainID(), err)
 	}
 
 	// Create client on dst for src if the client id is unspecified
 	modified, err = CreateClient(dst, c, dstUpdateHeader, srcUpdateHeader, allowUpdateAfterExpiry, allowUpdateAfterMisbehaviour, override)
-	if err != nil {
+	switch {
+	case isDryRun(err) || (err == nil && dryRun):
+		return false, provider.ErrDryRun
+	case err != nil:
 		return modified, fmt.Errorf("failed to create client on dst chain{%s}. Err: %w", dst.ChainID(), err)
 	}
 
@@ -138,7 +143,7 @@ func CreateClient(src, dst *Chain, srcUpdateHeader, dstUpdateHeader ibcexported.
 					return fmt.Errorf("tx failed on chain{%s}: %s", src.ChainID(), res.Data)
 				}
 				return err
-			}, RtyAtt, RtyDel, RtyErr); err != nil {
+			}, RtyAtt, RtyDel, RtyErr, RtyIfNotDryRun); err != nil {
 				return modified, err
 			}
 
@@ -23,6 +23,10 @@ func (c *Chain) CreateOpenConnections(dst *Chain, maxRetries uint64, to time.Dur
 	failed := uint64(0)
 	for ; true; <-ticker.C {
 		success, lastStep, recentlyModified, err := ExecuteConnectionStep(c, dst)
+		if isDryRun(err) {
+			// the later steps depend on this one being committed, so there is nothing more to simulate
+			return modified, err
+		}
 		if err != nil {
 			c.Log(fmt.Sprintf("%v", err))
 		}
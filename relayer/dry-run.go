@@ -0,0 +1,137 @@
+package relayer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	"github.com/cosmos/relayer/relayer/provider"
+	"github.com/gogo/protobuf/proto"
+)
+
+// dryRunMu keeps the txs printed for the chains of a path from interleaving
+var dryRunMu sync.Mutex
+
+// dryRunProvider is the provider of a chain in dry-run mode. The msgs it is asked to send are simulated
+// and printed along with their estimated gas and fee, or generated into unsigned txs in generate-only
+// mode, instead of being signed and broadcast, and provider.ErrDryRun is returned in place of their
+// result. Everything else, queries included, is handled by the wrapped provider.
+type dryRunProvider struct {
+	provider.ChainProvider
+	chain *Chain
+
+	// txs collects the generated txs in generate-only mode
+	txs *OfflineTxs
+}
+
+// dryRunTx is what gets printed for a simulated tx
+type dryRunTx struct {
+	ChainID string            `json:"chain-id"`
+	Msgs    []json.RawMessage `json:"msgs"`
+	Gas     uint64            `json:"estimated-gas,omitempty"`
+	Fee     string            `json:"estimated-fee,omitempty"`
+	Error   string            `json:"simulation-error,omitempty"`
+}
+
+// EnableDryRun puts c in dry-run mode: the txs it would send are simulated and printed instead, and fail
+// with provider.ErrDryRun
+func (c *Chain) EnableDryRun() {
+	if _, ok := c.ChainProvider.(*dryRunProvider); ok {
+		return
+	}
+	c.ChainProvider = &dryRunProvider{ChainProvider: c.ChainProvider, chain: c}
+}
+
+// DryRun returns true if c is in dry-run or generate-only mode
+func (c *Chain) DryRun() bool {
+	_, ok := c.ChainProvider.(*dryRunProvider)
+	return ok
+}
+
+// simulating returns true if c is in dry-run mode and simulates its txs, rather than generating them
+func (c *Chain) simulating() bool {
+	p, ok := c.ChainProvider.(*dryRunProvider)
+	return ok && p.txs == nil
+}
+
+func (p *dryRunProvider) SendMessage(msg provider.RelayerMessage) (*provider.RelayerTxResponse, bool, error) {
+	return p.SendMessages([]provider.RelayerMessage{msg})
+}
+
+func (p *dryRunProvider) SendMessages(msgs []provider.RelayerMessage) (*provider.RelayerTxResponse, bool, error) {
+	return nil, false, p.dryRun(msgs, "")
+}
+
+func (p *dryRunProvider) BroadcastMessages(msgs []provider.RelayerMessage, key string, gas uint64, feeBump float64) (string, error) {
+	return "", p.dryRun(msgs, key)
+}
+
+func (p *dryRunProvider) dryRun(msgs []provider.RelayerMessage, key string) error {
+	if p.txs != nil {
+		return p.generate(msgs)
+	}
+	return p.simulate(msgs, key)
+}
+
+// simulate prints the tx of msgs signed with key along with its estimated gas and fee, or the reason its
+// simulation failed
+func (p *dryRunProvider) simulate(msgs []provider.RelayerMessage, key string) error {
+	tx := &dryRunTx{ChainID: p.ChainId(), Msgs: make([]json.RawMessage, 0, len(msgs))}
+	for _, msg := range msgs {
+		bz, err := p.chain.msgJSON(msg)
+		if err != nil {
+			return fmt.Errorf("failed to decode %s msg: %w", msg.Type(), err)
+		}
+		tx.Msgs = append(tx.Msgs, bz)
+	}
+
+	gas, fee, simErr := p.ChainProvider.SimulateMessages(msgs, key)
+	if simErr != nil {
+		tx.Error = simErr.Error()
+	} else {
+		tx.Gas, tx.Fee = gas, fee.String()
+		if fee.Empty() {
+			tx.Fee = "0"
+		}
+	}
+
+	out, err := json.MarshalIndent(tx, "", "  ")
+	if err != nil {
+		return err
+	}
+	dryRunMu.Lock()
+	fmt.Println(string(out))
+	dryRunMu.Unlock()
+
+	if simErr != nil {
+		return fmt.Errorf("%w: simulation failed: %s", provider.ErrDryRun, simErr)
+	}
+	return provider.ErrDryRun
+}
+
+// msgJSON decodes msg with the codec of c and returns its JSON, type URL included
+func (c *Chain) msgJSON(msg provider.RelayerMessage) ([]byte, error) {
+	bz, err := msg.MsgBytes()
+	if err != nil {
+		return nil, err
+	}
+	pm, err := c.Encoding.InterfaceRegistry.Resolve(msg.Type())
+	if err != nil {
+		return nil, err
+	}
+	if err = proto.Unmarshal(bz, pm); err != nil {
+		return nil, err
+	}
+	anyMsg, err := codectypes.NewAnyWithValue(pm)
+	if err != nil {
+		return nil, err
+	}
+	return c.Encoding.Marshaler.MarshalJSON(anyMsg)
+}
+
+// isDryRun returns true if err reports a tx that was only simulated for a dry run
+func isDryRun(err error) bool {
+	return errors.Is(err, provider.ErrDryRun)
+}
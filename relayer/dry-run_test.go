@@ -0,0 +1,80 @@
+package relayer
+
+import (
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/relayer/relayer/provider"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDryRunCreateClients(t *testing.T) {
+	src, dst := newMockChain(t, "ibc-0"), newMockChain(t, "ibc-1")
+	src.EnableDryRun()
+	dst.EnableDryRun()
+	require.True(t, src.DryRun())
+
+	_, err := src.CreateClients(dst, true, true, false)
+	require.ErrorIs(t, err, provider.ErrDryRun)
+	require.Empty(t, src.ClientID())
+	require.Empty(t, dst.ClientID())
+
+	for _, c := range []*Chain{src, dst} {
+		clients, err := c.ChainProvider.QueryClients()
+		require.NoError(t, err)
+		require.Empty(t, clients)
+	}
+}
+
+func TestDryRunTransfer(t *testing.T) {
+	src, dst := newMockLink(t)
+	src.EnableDryRun()
+
+	dstAddr, err := dst.ChainProvider.Address()
+	require.NoError(t, err)
+	err = src.SendTransferMsg(dst, sdk.NewInt64Coin(testDenom, 1000), dstAddr, 0, 0)
+	require.ErrorIs(t, err, provider.ErrDryRun)
+
+	// the simulated transfer left no trace on src
+	requireBalance(t, src, testDenom, 10000)
+	commitments, err := src.ChainProvider.QueryPacketCommitments(0, src.PathEnd.ChannelID, src.PathEnd.PortID)
+	require.NoError(t, err)
+	require.Empty(t, commitments.Commitments)
+}
+
+// simulationsProvider records the number of msgs in each tx simulated on the chain of the provider it wraps
+type simulationsProvider struct {
+	provider.ChainProvider
+	simulated []int
+}
+
+func (p *simulationsProvider) SimulateMessages(msgs []provider.RelayerMessage, key string) (uint64, sdk.Coins, error) {
+	p.simulated = append(p.simulated, len(msgs))
+	return p.ChainProvider.SimulateMessages(msgs, key)
+}
+
+func TestDryRunSimulatesBatchesAsOneTx(t *testing.T) {
+	src, dst := newMockLink(t)
+	sims := &simulationsProvider{ChainProvider: src.ChainProvider}
+	src.ChainProvider = sims
+	src.EnableDryRun()
+
+	dstAddr, err := dst.ChainProvider.Address()
+	require.NoError(t, err)
+	msgs := NewRelayMsgs()
+	msgs.MaxMsgLength = 1
+	for i := 0; i < 3; i++ {
+		msg, err := src.ChainProvider.MsgTransfer(sdk.NewInt64Coin(testDenom, 100), dst.ChainID(), dstAddr,
+			src.PathEnd.PortID, src.PathEnd.ChannelID, 0, uint64(time.Now().Add(time.Hour).UnixNano()))
+		require.NoError(t, err)
+		msgs.Src = append(msgs.Src, msg)
+	}
+
+	// the three batches are simulated together, as the later ones would rely on the first being committed
+	msgs.Send(src, dst)
+	require.True(t, msgs.simulated())
+	require.Len(t, msgs.Results, 1)
+	require.Equal(t, []int{3}, sims.simulated)
+	requireBalance(t, src, testDenom, 10000)
+}
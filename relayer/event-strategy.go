@@ -0,0 +1,384 @@
+package relayer
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/avast/retry-go"
+	chantypes "github.com/cosmos/ibc-go/v2/modules/core/04-channel/types"
+	ctypes "github.com/tendermint/tendermint/rpc/core/types"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	txEventQuery       = "tm.event='Tx'"
+	newBlockEventQuery = "tm.event='NewBlock'"
+
+	// eventStaleTimeout is how long a chain may go without reporting a new block before its
+	// subscriptions are considered dead and re-established
+	eventStaleTimeout = time.Minute
+
+	// eventResubscribeDelay is the time waited before re-establishing failed subscriptions
+	eventResubscribeDelay = 5 * time.Second
+)
+
+// StartEventRelayer starts a relaying loop that is driven by event subscriptions on both chains
+// instead of polling, on the channel that src and dst are set to
+func StartEventRelayer(src, dst *Chain, maxTxSize, maxMsgLength uint64, sweepInterval time.Duration) (func(), error) {
+	return StartChannelSetEventRelayer(channelSetOf(src, dst), maxTxSize, maxMsgLength, sweepInterval)
+}
+
+// StartChannelSetEventRelayer starts a relaying loop on every channel of cs that is driven by event
+// subscriptions on both chains. Sequences reported by send_packet and write_acknowledgement events are
+// relayed on the next new block, while recv_packet and acknowledge_packet events drop sequences that
+// were delivered by someone else. A full scan of the channels is done whenever the subscriptions are
+// (re)established and every sweepInterval, to pick up anything the events missed. The channels are
+// looked up again on every sweep and whenever a channel is opened on the connection of the path.
+// An error is returned if sweepInterval is not positive.
+func StartChannelSetEventRelayer(cs *ChannelSet, maxTxSize, maxMsgLength uint64, sweepInterval time.Duration) (func(), error) {
+	if sweepInterval <= 0 {
+		return nil, fmt.Errorf("sweep interval must be positive, got %s", sweepInterval)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		for {
+			if err := relayFromEvents(ctx, cs, maxTxSize, maxMsgLength, sweepInterval); err != nil {
+				cs.src.Log(fmt.Sprintf("event subscription error: %s, resubscribing in %s", err, eventResubscribeDelay))
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(eventResubscribeDelay):
+			}
+		}
+	}()
+	return cancel, nil
+}
+
+// relayFromEvents subscribes to both chains and relays the packets and acknowledgements they report
+// until ctx is cancelled or one of the subscriptions fails
+func relayFromEvents(ctx context.Context, cs *ChannelSet, maxTxSize, maxMsgLength uint64, sweepInterval time.Duration) error {
+	subCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	src, dst := cs.src, cs.dst
+	srcTxs, err := src.ChainProvider.SubscribeEvents(subCtx, txEventQuery)
+	if err != nil {
+		return err
+	}
+	dstTxs, err := dst.ChainProvider.SubscribeEvents(subCtx, txEventQuery)
+	if err != nil {
+		return err
+	}
+	srcBlocks, err := src.ChainProvider.SubscribeEvents(subCtx, newBlockEventQuery)
+	if err != nil {
+		return err
+	}
+	dstBlocks, err := dst.ChainProvider.SubscribeEvents(subCtx, newBlockEventQuery)
+	if err != nil {
+		return err
+	}
+
+	// anything sent while we were not subscribed has to be found by scanning
+	if canRelay(src, dst) {
+		relayUnrelayedChannels(cs, maxTxSize, maxMsgLength)
+	}
+
+	var (
+		pending  = newPendingChannels()
+		sweep    = time.NewTicker(sweepInterval)
+		srcStale = time.NewTimer(eventStaleTimeout)
+		dstStale = time.NewTimer(eventStaleTimeout)
+	)
+	defer sweep.Stop()
+	defer srcStale.Stop()
+	defer dstStale.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case ev, ok := <-srcTxs:
+			if !ok {
+				return fmt.Errorf("tx subscription closed on %s", src.ChainID())
+			}
+			if channelOpened(ev.Events, src) {
+				cs.refresh()
+			}
+			for _, ch := range cs.Channels() {
+				pending.get(ch).addSrcEvents(ch.Src, ev)
+			}
+
+		case ev, ok := <-dstTxs:
+			if !ok {
+				return fmt.Errorf("tx subscription closed on %s", dst.ChainID())
+			}
+			if channelOpened(ev.Events, dst) {
+				cs.refresh()
+			}
+			for _, ch := range cs.Channels() {
+				pending.get(ch).addDstEvents(ch.Dst, ev)
+			}
+
+		case _, ok := <-srcBlocks:
+			if !ok {
+				return fmt.Errorf("block subscription closed on %s", src.ChainID())
+			}
+			resetTimer(srcStale, eventStaleTimeout)
+			pending.relay(cs, maxTxSize, maxMsgLength)
+
+		case _, ok := <-dstBlocks:
+			if !ok {
+				return fmt.Errorf("block subscription closed on %s", dst.ChainID())
+			}
+			resetTimer(dstStale, eventStaleTimeout)
+			pending.relay(cs, maxTxSize, maxMsgLength)
+
+		case <-srcStale.C:
+			return fmt.Errorf("no new blocks reported by %s in %s", src.ChainID(), eventStaleTimeout)
+
+		case <-dstStale.C:
+			return fmt.Errorf("no new blocks reported by %s in %s", dst.ChainID(), eventStaleTimeout)
+
+		case <-sweep.C:
+			cs.refresh()
+			if !canRelay(src, dst) {
+				continue
+			}
+			relayUnrelayedChannels(cs, maxTxSize, maxMsgLength)
+		}
+	}
+}
+
+// channelOpened returns true if events emitted on c report a channel opening on the connection of c
+func channelOpened(events map[string][]string, c *Chain) bool {
+	for _, evType := range []string{chantypes.EventTypeChannelOpenAck, chantypes.EventTypeChannelOpenConfirm} {
+		for _, conn := range events[evType+"."+chantypes.AttributeKeyConnectionID] {
+			if conn == c.PathEnd.ConnectionID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// pendingChannels holds the pending sequences of every channel of a set, keyed by their channel on src
+type pendingChannels struct {
+	channels map[string]*pendingSequences
+
+	// dropped is set when sequences were dropped while relaying on the path was paused
+	dropped bool
+}
+
+func newPendingChannels() *pendingChannels {
+	return &pendingChannels{channels: make(map[string]*pendingSequences)}
+}
+
+// get returns the pending sequences of ch
+func (p *pendingChannels) get(ch *ChannelEnds) *pendingSequences {
+	ps, ok := p.channels[ch.Src.PathEnd.ChannelID]
+	if !ok {
+		ps = newPendingSequences()
+		p.channels[ch.Src.PathEnd.ChannelID] = ps
+	}
+	return ps
+}
+
+// relay relays the pending sequences of every channel of cs that are still unrelayed in one round and
+// resets the pending sets. Sequences whose relay txs failed or were dropped are added back to be retried
+// on the next block, other errors leave them for the next sweep. While relaying on the path is paused the
+// pending sets are dropped on every block, so they do not grow for as long as the pause lasts, and the
+// channels are scanned for what they held once relaying resumes.
+func (p *pendingChannels) relay(cs *ChannelSet, maxTxSize, maxMsgLength uint64) {
+	if !canRelay(cs.src, cs.dst) {
+		for _, ps := range p.channels {
+			if !ps.empty() {
+				ps.reset()
+				p.dropped = true
+			}
+		}
+		return
+	}
+	if p.dropped {
+		for _, ps := range p.channels {
+			ps.reset()
+		}
+		p.dropped = false
+		relayUnrelayedChannels(cs, maxTxSize, maxMsgLength)
+		return
+	}
+
+	var (
+		rounds   []*ChannelSequences
+		pendings []*pendingSequences
+	)
+	for _, ch := range cs.Channels() {
+		ps := p.get(ch)
+		if ps.empty() {
+			continue
+		}
+		round, err := ps.take(ch.Src, ch.Dst)
+		if err != nil {
+			ch.Src.Log(fmt.Sprintf("unrelayed sequences error on channel %s: %s", ch.Src.PathEnd.ChannelID, err))
+			continue
+		}
+		rounds, pendings = append(rounds, round), append(pendings, ps)
+	}
+
+	relayChannels(rounds, maxTxSize, maxMsgLength)
+	for i, round := range rounds {
+		if round.FailedPackets != nil {
+			pendings[i].retry(round.FailedPackets, pendings[i].srcPackets, pendings[i].dstPackets)
+		}
+		if round.FailedAcks != nil {
+			pendings[i].retry(round.FailedAcks, pendings[i].srcAcks, pendings[i].dstAcks)
+		}
+	}
+}
+
+// pendingSequences collects the packet and acknowledgement sequences reported by events
+// until they are relayed on the next block
+type pendingSequences struct {
+	srcPackets, dstPackets map[uint64]struct{}
+	srcAcks, dstAcks       map[uint64]struct{}
+}
+
+func newPendingSequences() *pendingSequences {
+	p := &pendingSequences{}
+	p.reset()
+	return p
+}
+
+func (p *pendingSequences) reset() {
+	p.srcPackets = make(map[uint64]struct{})
+	p.dstPackets = make(map[uint64]struct{})
+	p.srcAcks = make(map[uint64]struct{})
+	p.dstAcks = make(map[uint64]struct{})
+}
+
+func (p *pendingSequences) empty() bool {
+	return len(p.srcPackets) == 0 && len(p.dstPackets) == 0 && len(p.srcAcks) == 0 && len(p.dstAcks) == 0
+}
+
+// addSrcEvents records the packet events emitted on the src chain of the path
+func (p *pendingSequences) addSrcEvents(src *Chain, ev ctypes.ResultEvent) {
+	p.add(ev.Events, src, p.srcPackets, p.dstPackets, p.srcAcks, p.dstAcks)
+}
+
+// addDstEvents records the packet events emitted on the dst chain of the path
+func (p *pendingSequences) addDstEvents(dst *Chain, ev ctypes.ResultEvent) {
+	p.add(ev.Events, dst, p.dstPackets, p.srcPackets, p.dstAcks, p.srcAcks)
+}
+
+// add records the events emitted on chain c. Packets sent and acknowledgements written on c are added
+// to its pending sets, packets received and acknowledgements processed on c are dropped from the
+// pending sets of the counterparty.
+func (p *pendingSequences) add(events map[string][]string, c *Chain, packets, cpPackets, acks, cpAcks map[uint64]struct{}) {
+	chanID, portID := c.PathEnd.ChannelID, c.PathEnd.PortID
+	src, dst := chantypes.AttributeKeySrcChannel, chantypes.AttributeKeyDstChannel
+	srcPort, dstPort := chantypes.AttributeKeySrcPort, chantypes.AttributeKeyDstPort
+
+	for _, seq := range ParsePacketSequencesFromEvents(events, chantypes.EventTypeSendPacket, src, srcPort, chanID, portID) {
+		packets[seq] = struct{}{}
+	}
+	for _, seq := range ParsePacketSequencesFromEvents(events, chantypes.EventTypeWriteAck, dst, dstPort, chanID, portID) {
+		acks[seq] = struct{}{}
+	}
+	for _, seq := range ParsePacketSequencesFromEvents(events, chantypes.EventTypeRecvPacket, dst, dstPort, chanID, portID) {
+		delete(cpPackets, seq)
+	}
+	for _, seq := range ParsePacketSequencesFromEvents(events, chantypes.EventTypeAcknowledgePacket, src, srcPort, chanID, portID) {
+		delete(cpAcks, seq)
+	}
+	for _, seq := range ParsePacketSequencesFromEvents(events, chantypes.EventTypeTimeoutPacket, src, srcPort, chanID, portID) {
+		delete(packets, seq)
+	}
+}
+
+// take resets the pending sets and returns the sequences they held that are still unrelayed
+func (p *pendingSequences) take(src, dst *Chain) (*ChannelSequences, error) {
+	sp := &RelaySequences{Src: sequenceList(p.srcPackets), Dst: sequenceList(p.dstPackets)}
+	ap := &RelaySequences{Src: sequenceList(p.srcAcks), Dst: sequenceList(p.dstAcks)}
+	p.reset()
+
+	var err error
+	if !sp.Empty() {
+		if sp, err = filterUnrelayedSequences(src, dst, sp, false); err != nil {
+			return nil, err
+		}
+	}
+	if !ap.Empty() {
+		if ap, err = filterUnrelayedSequences(src, dst, ap, true); err != nil {
+			return nil, err
+		}
+	}
+	return &ChannelSequences{Src: src, Dst: dst, Packets: sp, Acks: ap}, nil
+}
+
+// retry adds sequences that failed to relay back to the given pending sets
+func (p *pendingSequences) retry(rs *RelaySequences, srcSet, dstSet map[uint64]struct{}) {
+	for _, seq := range rs.Src {
+		srcSet[seq] = struct{}{}
+	}
+	for _, seq := range rs.Dst {
+		dstSet[seq] = struct{}{}
+	}
+}
+
+// filterUnrelayedSequences drops the sequences that the counterparty has already received,
+// checking unreceived acknowledgements instead of packets when acks is set
+func filterUnrelayedSequences(src, dst *Chain, rs *RelaySequences, acks bool) (*RelaySequences, error) {
+	var (
+		eg  = new(errgroup.Group)
+		out = &RelaySequences{Src: []uint64{}, Dst: []uint64{}}
+	)
+
+	unreceived := func(c *Chain, seqs []uint64, res *[]uint64) func() error {
+		return func() error {
+			if len(seqs) == 0 {
+				return nil
+			}
+			return retry.Do(func() error {
+				h, err := c.ChainProvider.QueryLatestHeight()
+				if err != nil {
+					return err
+				}
+				if acks {
+					*res, err = c.ChainProvider.QueryUnreceivedAcknowledgements(uint64(h), c.PathEnd.ChannelID, c.PathEnd.PortID, seqs)
+				} else {
+					*res, err = c.ChainProvider.QueryUnreceivedPackets(uint64(h), c.PathEnd.ChannelID, c.PathEnd.PortID, seqs)
+				}
+				return err
+			}, RtyAtt, RtyDel, RtyErr)
+		}
+	}
+
+	eg.Go(unreceived(dst, rs.Src, &out.Src))
+	eg.Go(unreceived(src, rs.Dst, &out.Dst))
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func sequenceList(set map[uint64]struct{}) []uint64 {
+	seqs := make([]uint64, 0, len(set))
+	for seq := range set {
+		seqs = append(seqs, seq)
+	}
+	sort.Slice(seqs, func(i, j int) bool { return seqs[i] < seqs[j] })
+	return seqs
+}
+
+func resetTimer(t *time.Timer, d time.Duration) {
+	if !t.Stop() {
+		select {
+		case <-t.C:
+		default:
+		}
+	}
+	t.Reset(d)
+}
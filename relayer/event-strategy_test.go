@@ -0,0 +1,17 @@
+package relayer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStartEventRelayerRejectsSweepInterval(t *testing.T) {
+	src, dst := newMockPath(t)
+	for _, interval := range []time.Duration{0, -time.Second} {
+		done, err := StartEventRelayer(src, dst, testMaxTxSize, testMaxMsgLength, interval)
+		require.Error(t, err)
+		require.Nil(t, done)
+	}
+}
@@ -2,6 +2,7 @@ package relayer
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 
 	clienttypes "github.com/cosmos/ibc-go/v2/modules/core/02-client/types"
@@ -101,3 +102,29 @@ func splitEventKey(key string) (string, string) {
 //	}
 //	return "", fmt.Errorf("channel identifier event attribute not found")
 //}
+
+// ParsePacketSequencesFromEvents parses the events of a tendermint event subscription and returns
+// the sequences of every packet event of the given type whose channel and port attributes, as
+// selected by chanAttr and portAttr, match channelID and portID.
+func ParsePacketSequencesFromEvents(events map[string][]string, eventType, chanAttr, portAttr, channelID, portID string) []uint64 {
+	var (
+		seqs  = events[eventType+"."+channeltypes.AttributeKeySequence]
+		chans = events[eventType+"."+chanAttr]
+		ports = events[eventType+"."+portAttr]
+		out   []uint64
+	)
+	for i, s := range seqs {
+		if i >= len(chans) || i >= len(ports) {
+			break
+		}
+		if chans[i] != channelID || ports[i] != portID {
+			continue
+		}
+		seq, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			continue
+		}
+		out = append(out, seq)
+	}
+	return out
+}
@@ -0,0 +1,267 @@
+package relayer
+
+import (
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/relayer/relayer/provider"
+)
+
+// FeeBudget limits the fees spent by relay transactions over a rolling hour and a rolling day.
+// Limits are coin lists such as "1000000uatom", an empty limit is not enforced.
+type FeeBudget struct {
+	Hourly string `yaml:"hourly,omitempty" json:"hourly,omitempty"`
+	Daily  string `yaml:"daily,omitempty" json:"daily,omitempty"`
+}
+
+// Validate returns an error if the limits of the budget can not be parsed
+func (fb *FeeBudget) Validate() error {
+	_, _, err := fb.limits()
+	return err
+}
+
+func (fb *FeeBudget) limits() (hourly, daily sdk.Coins, err error) {
+	if fb.Hourly != "" {
+		if hourly, err = sdk.ParseCoinsNormalized(fb.Hourly); err != nil {
+			return nil, nil, fmt.Errorf("invalid hourly fee budget %s: %w", fb.Hourly, err)
+		}
+	}
+	if fb.Daily != "" {
+		if daily, err = sdk.ParseCoinsNormalized(fb.Daily); err != nil {
+			return nil, nil, fmt.Errorf("invalid daily fee budget %s: %w", fb.Daily, err)
+		}
+	}
+	return hourly, daily, nil
+}
+
+// fees tracks the fees spent by each path and chain against their budgets
+var fees = newFeeLedger()
+
+// SetPathFeeBudget sets the fee budget of the named path, a nil budget removes it
+func SetPathFeeBudget(name string, fb *FeeBudget) error {
+	return fees.setBudget(pathBudgetKey(name), fb)
+}
+
+// SetChainFeeBudget sets the fee budget of a chain over all paths, a nil budget removes it
+func SetChainFeeBudget(chainID string, fb *FeeBudget) error {
+	return fees.setBudget(chainBudgetKey(chainID), fb)
+}
+
+type feeLimits struct {
+	hourly, daily sdk.Coins
+}
+
+type feeSpend struct {
+	time time.Time
+	fee  sdk.Coins
+}
+
+// feeLedger keeps the fees spent during the last day for every budget key, along with the estimated
+// fee of the last tx that was held back because it would have exceeded the budget of the key
+type feeLedger struct {
+	mu       sync.Mutex
+	budgets  map[string]feeLimits
+	spends   map[string][]feeSpend
+	pending  map[string]sdk.Coins
+	exceeded map[string]bool
+}
+
+func newFeeLedger() *feeLedger {
+	return &feeLedger{
+		budgets:  make(map[string]feeLimits),
+		spends:   make(map[string][]feeSpend),
+		pending:  make(map[string]sdk.Coins),
+		exceeded: make(map[string]bool),
+	}
+}
+
+func pathBudgetKey(name string) string {
+	return "path/" + name
+}
+
+func chainBudgetKey(chainID string) string {
+	return "chain/" + chainID
+}
+
+func (l *feeLedger) setBudget(key string, fb *FeeBudget) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if fb == nil {
+		delete(l.budgets, key)
+		return nil
+	}
+	hourly, daily, err := fb.limits()
+	if err != nil {
+		return err
+	}
+	l.budgets[key] = feeLimits{hourly: hourly, daily: daily}
+	return nil
+}
+
+// record adds the fee of a relay transaction sent to c to the spend of the path and the chain
+func (l *feeLedger) record(path string, c *Chain, res *provider.RelayerTxResponse) {
+	if res == nil || res.Fee.IsZero() {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	for _, key := range []string{pathBudgetKey(path), chainBudgetKey(c.ChainID())} {
+		l.spends[key] = append(l.spends[key], feeSpend{time: now, fee: res.Fee})
+	}
+	for _, coin := range res.Fee {
+		amount, _ := new(big.Float).SetInt(coin.Amount.BigInt()).Float64()
+		Metrics.FeesSpent.WithLabelValues(path, c.ChainID(), coin.Denom).Add(amount)
+	}
+}
+
+// allows estimates the fee of a tx of msgs to c signed with the signer key and returns false if it would
+// take the path or c over its fee budget. The estimated gas is returned too, so the tx is signed with
+// the estimate it was judged on, or zero if no budget applies and nothing was estimated. The estimate of
+// a tx that is held back counts against the budget in withinBudget, which pauses the path until enough
+// of the spend has aged out for the tx to fit. A tx whose estimate alone exceeds a limit can never fit,
+// so it is reported instead of pausing the path.
+func (l *feeLedger) allows(path string, c *Chain, signer string, msgs []provider.RelayerMessage) (uint64, bool) {
+	keys := []string{pathBudgetKey(path), chainBudgetKey(c.ChainID())}
+	if !l.hasBudget(keys...) {
+		return 0, true
+	}
+
+	// a tx that can not be simulated is left to fail when it is broadcast
+	gas, fee, err := c.ChainProvider.SimulateMessages(msgs, signer)
+	if err != nil {
+		return 0, true
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	ok, held := true, false
+	now := time.Now()
+	for _, key := range keys {
+		limits, hasBudget := l.budgets[key]
+		if !hasBudget {
+			continue
+		}
+		if overLimit(fee, limits.hourly) || overLimit(fee, limits.daily) {
+			c.Error(fmt.Errorf("a tx on %s with an estimated fee of %s can never be sent within the fee budget of %s "+
+				"(hourly %s, daily %s), raise the budget or lower --max-msgs", path, fee, key, limits.hourly, limits.daily))
+			delete(l.pending, key)
+			ok = false
+			continue
+		}
+		hourly, daily := l.spent(key, now)
+		if overLimit(hourly.Add(fee...), limits.hourly) || overLimit(daily.Add(fee...), limits.daily) {
+			l.pending[key] = fee
+			ok, held = false, true
+		} else {
+			delete(l.pending, key)
+		}
+	}
+	if held {
+		c.Log(fmt.Sprintf("✘ [%s] holding back a tx with an estimated fee of %s on %s, it would exceed the fee budget",
+			c.ChainID(), fee, path))
+	}
+	return gas, ok
+}
+
+// hasBudget returns true if any of keys has a fee budget
+func (l *feeLedger) hasBudget(keys ...string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, key := range keys {
+		if _, ok := l.budgets[key]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// overLimit returns true if fees exceed limit in any of the denoms of limit
+func overLimit(fees, limit sdk.Coins) bool {
+	for _, coin := range limit {
+		if fees.AmountOf(coin.Denom).GT(coin.Amount) {
+			return true
+		}
+	}
+	return false
+}
+
+// withinBudget returns false if the path between src and dst or one of its chains has spent its fee
+// budget, or the fees spent along with the estimated fee of the tx held back by allows exceed it. Once
+// enough of the spend has aged out for the held back tx to fit, its estimate is dropped, so the path
+// resumes and the tx is estimated afresh. A log line is written whenever a budget becomes exceeded or
+// available again.
+func (l *feeLedger) withinBudget(src, dst *Chain) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	ok := true
+	for _, key := range []string{pathBudgetKey(pathLabel(src, dst)), chainBudgetKey(src.ChainID()), chainBudgetKey(dst.ChainID())} {
+		limits, hasBudget := l.budgets[key]
+		if !hasBudget {
+			continue
+		}
+
+		hourly, daily := l.spent(key, time.Now())
+		pending := l.pending[key]
+		var reason string
+		switch {
+		case !limits.hourly.Empty() && hourly.IsAnyGTE(limits.hourly):
+			reason = fmt.Sprintf("spent %s in the last hour, hourly budget is %s", hourly, limits.hourly)
+		case !limits.daily.Empty() && daily.IsAnyGTE(limits.daily):
+			reason = fmt.Sprintf("spent %s in the last day, daily budget is %s", daily, limits.daily)
+		case overLimit(hourly.Add(pending...), limits.hourly):
+			reason = fmt.Sprintf("spent %s in the last hour and the next tx needs about %s, hourly budget is %s", hourly, pending, limits.hourly)
+		case overLimit(daily.Add(pending...), limits.daily):
+			reason = fmt.Sprintf("spent %s in the last day and the next tx needs about %s, daily budget is %s", daily, pending, limits.daily)
+		}
+
+		exceeded := reason != ""
+		if !exceeded {
+			delete(l.pending, key)
+		}
+		if exceeded != l.exceeded[key] {
+			if exceeded {
+				src.Log(fmt.Sprintf("✘ fee budget of %s exceeded: %s, relaying on %s is paused", key, reason, pathLabel(src, dst)))
+				notifyPathStatus(pathLabel(src, dst), fmt.Sprintf("was paused, fee budget of %s exceeded", key))
+			} else {
+				src.Log(fmt.Sprintf("✔ fee budget of %s is available again, relaying on %s resumed", key, pathLabel(src, dst)))
+				notifyPathStatus(pathLabel(src, dst), fmt.Sprintf("was resumed, fee budget of %s available again", key))
+			}
+			l.exceeded[key] = exceeded
+		}
+		if exceeded {
+			Metrics.FeeBudgetExceeded.WithLabelValues(key).Set(1)
+			ok = false
+		} else {
+			Metrics.FeeBudgetExceeded.WithLabelValues(key).Set(0)
+		}
+	}
+	return ok
+}
+
+// spent returns the fees spent for key during the last hour and day, dropping older spends
+func (l *feeLedger) spent(key string, now time.Time) (hourly, daily sdk.Coins) {
+	var kept []feeSpend
+	hourly, daily = sdk.NewCoins(), sdk.NewCoins()
+	for _, s := range l.spends[key] {
+		age := now.Sub(s.time)
+		if age > 24*time.Hour {
+			continue
+		}
+		kept = append(kept, s)
+		daily = daily.Add(s.fee...)
+		if age <= time.Hour {
+			hourly = hourly.Add(s.fee...)
+		}
+	}
+	l.spends[key] = kept
+	return hourly, daily
+}
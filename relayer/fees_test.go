@@ -0,0 +1,67 @@
+package relayer
+
+import (
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/relayer/relayer/provider"
+	"github.com/stretchr/testify/require"
+)
+
+// feeProvider estimates a fixed fee for every tx on the chain of the provider it wraps
+type feeProvider struct {
+	provider.ChainProvider
+	fee sdk.Coins
+}
+
+func (p *feeProvider) SimulateMessages(msgs []provider.RelayerMessage, key string) (uint64, sdk.Coins, error) {
+	return 100000, p.fee, nil
+}
+
+func TestFeeBudgetCountsPendingTx(t *testing.T) {
+	src, dst := newMockChain(t, "ibc-0"), newMockChain(t, "ibc-1")
+	src.ChainProvider = &feeProvider{ChainProvider: src.ChainProvider, fee: sdk.NewCoins(sdk.NewInt64Coin(testDenom, 60))}
+
+	l := newFeeLedger()
+	path := pathLabel(src, dst)
+	require.NoError(t, l.setBudget(pathBudgetKey(path), &FeeBudget{Hourly: "100" + testDenom}))
+	l.record(path, src, &provider.RelayerTxResponse{Fee: sdk.NewCoins(sdk.NewInt64Coin(testDenom, 30))})
+
+	// 30 spent and 60 estimated stay within the budget of 100, the tx is signed with the estimated gas
+	gas, ok := l.allows(path, src, "", nil)
+	require.True(t, ok)
+	require.Equal(t, uint64(100000), gas)
+	require.True(t, l.withinBudget(src, dst))
+
+	// with 60 spent the next tx would take the path to 120, so it is held back and the path paused,
+	// although the spend alone is still below the budget
+	l.record(path, src, &provider.RelayerTxResponse{Fee: sdk.NewCoins(sdk.NewInt64Coin(testDenom, 30))})
+	_, ok = l.allows(path, src, "", nil)
+	require.False(t, ok)
+	require.False(t, l.withinBudget(src, dst))
+
+	// once the spend has aged out the held back estimate is dropped and the path resumes
+	for key := range l.spends {
+		for i := range l.spends[key] {
+			l.spends[key][i].time = time.Now().Add(-2 * time.Hour)
+		}
+	}
+	require.True(t, l.withinBudget(src, dst))
+	require.Empty(t, l.pending)
+}
+
+func TestFeeBudgetTxAboveLimit(t *testing.T) {
+	src, dst := newMockChain(t, "ibc-0"), newMockChain(t, "ibc-1")
+	src.ChainProvider = &feeProvider{ChainProvider: src.ChainProvider, fee: sdk.NewCoins(sdk.NewInt64Coin(testDenom, 150))}
+
+	l := newFeeLedger()
+	path := pathLabel(src, dst)
+	require.NoError(t, l.setBudget(pathBudgetKey(path), &FeeBudget{Hourly: "100" + testDenom}))
+
+	// a tx that can never fit the budget is not sent, but does not pause the path either
+	_, ok := l.allows(path, src, "", nil)
+	require.False(t, ok)
+	require.Empty(t, l.pending)
+	require.True(t, l.withinBudget(src, dst))
+}
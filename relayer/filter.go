@@ -0,0 +1,233 @@
+package relayer
+
+import (
+	"container/list"
+	"fmt"
+	"sync"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	transfertypes "github.com/cosmos/ibc-go/v2/modules/apps/transfer/types"
+)
+
+// PacketFilter decides which packets of a path are relayed. Channel rules apply to the channel the
+// packet was sent on, the other rules are checked against the ICS-20 transfer data of the packet.
+// A packet must match an allow list if one is set and must not match any deny list. Once any of the
+// transfer rules is set, packets that are not ICS-20 transfers are not relayed.
+type PacketFilter struct {
+	AllowChannels  []string `yaml:"allow-channels,omitempty" json:"allow-channels,omitempty"`
+	DenyChannels   []string `yaml:"deny-channels,omitempty" json:"deny-channels,omitempty"`
+	AllowSenders   []string `yaml:"allow-senders,omitempty" json:"allow-senders,omitempty"`
+	DenySenders    []string `yaml:"deny-senders,omitempty" json:"deny-senders,omitempty"`
+	AllowReceivers []string `yaml:"allow-receivers,omitempty" json:"allow-receivers,omitempty"`
+	DenyReceivers  []string `yaml:"deny-receivers,omitempty" json:"deny-receivers,omitempty"`
+	AllowDenoms    []string `yaml:"allow-denoms,omitempty" json:"allow-denoms,omitempty"`
+	DenyDenoms     []string `yaml:"deny-denoms,omitempty" json:"deny-denoms,omitempty"`
+
+	// MinAmount is a coin list such as "1000000uatom", transfers of a listed denom below its
+	// amount are not relayed. Denoms are matched against the denom in the packet data, which is
+	// the full trace path for vouchers returning to their source chain.
+	MinAmount string `yaml:"min-amount,omitempty" json:"min-amount,omitempty"`
+}
+
+// Validate returns an error if the minimum amount of the filter can not be parsed
+func (pf *PacketFilter) Validate() error {
+	if pf.MinAmount == "" {
+		return nil
+	}
+	if _, err := sdk.ParseCoinsNormalized(pf.MinAmount); err != nil {
+		return fmt.Errorf("invalid filter min-amount %s: %w", pf.MinAmount, err)
+	}
+	return nil
+}
+
+// transferRules returns true if the filter has rules that need the packet data
+func (pf *PacketFilter) transferRules() bool {
+	return len(pf.AllowSenders) > 0 || len(pf.DenySenders) > 0 ||
+		len(pf.AllowReceivers) > 0 || len(pf.DenyReceivers) > 0 ||
+		len(pf.AllowDenoms) > 0 || len(pf.DenyDenoms) > 0 ||
+		pf.MinAmount != ""
+}
+
+// AllowChannel returns false if packets sent on the channel are filtered out
+func (pf *PacketFilter) AllowChannel(channelID string) bool {
+	return listed(pf.AllowChannels, channelID, true) && !listed(pf.DenyChannels, channelID, false)
+}
+
+// AllowTransfer returns nil if a packet with the given data passes the transfer rules of the
+// filter, or an error describing why it was filtered out
+func (pf *PacketFilter) AllowTransfer(data []byte) error {
+	if !pf.transferRules() {
+		return nil
+	}
+
+	var ftpd transfertypes.FungibleTokenPacketData
+	if err := transfertypes.ModuleCdc.UnmarshalJSON(data, &ftpd); err != nil {
+		return fmt.Errorf("packet is not an ics-20 transfer")
+	}
+
+	switch {
+	case !listed(pf.AllowSenders, ftpd.Sender, true) || listed(pf.DenySenders, ftpd.Sender, false):
+		return fmt.Errorf("sender %s is not allowed", ftpd.Sender)
+	case !listed(pf.AllowReceivers, ftpd.Receiver, true) || listed(pf.DenyReceivers, ftpd.Receiver, false):
+		return fmt.Errorf("receiver %s is not allowed", ftpd.Receiver)
+	case !listed(pf.AllowDenoms, ftpd.Denom, true) || listed(pf.DenyDenoms, ftpd.Denom, false):
+		return fmt.Errorf("denom %s is not allowed", ftpd.Denom)
+	}
+
+	if pf.MinAmount != "" {
+		min, err := sdk.ParseCoinsNormalized(pf.MinAmount)
+		if err != nil {
+			return err
+		}
+		amount, ok := sdk.NewIntFromString(ftpd.Amount)
+		if !ok {
+			return fmt.Errorf("invalid transfer amount %s", ftpd.Amount)
+		}
+		if minAmount := min.AmountOf(ftpd.Denom); amount.LT(minAmount) {
+			return fmt.Errorf("amount %s%s is below the minimum of %s%s", amount, ftpd.Denom, minAmount, ftpd.Denom)
+		}
+	}
+	return nil
+}
+
+// listed returns true if s is in list, or empty if list is empty
+func listed(list []string, s string, empty bool) bool {
+	if len(list) == 0 {
+		return empty
+	}
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// filterCacheSize is how many decisions on packets a path filter remembers
+const filterCacheSize = 10000
+
+// pathFilter is the filter of a path along with the decisions last made for its packets, so the
+// packets it leaves unrelayed are not looked up on every relay round. The decisions are kept in a
+// bounded, least recently used cache, so the decisions on packets relayed long ago are dropped.
+type pathFilter struct {
+	filter *PacketFilter
+
+	mu      sync.Mutex
+	decided map[string]*list.Element
+	order   *list.List
+}
+
+type filterDecision struct {
+	key   string
+	allow bool
+}
+
+func newPathFilter(pf *PacketFilter) *pathFilter {
+	return &pathFilter{filter: pf, decided: make(map[string]*list.Element), order: list.New()}
+}
+
+// decision returns the decision cached for the packet key
+func (pf *pathFilter) decision(key string) (allow, ok bool) {
+	pf.mu.Lock()
+	defer pf.mu.Unlock()
+
+	el, ok := pf.decided[key]
+	if !ok {
+		return false, false
+	}
+	pf.order.MoveToFront(el)
+	return el.Value.(*filterDecision).allow, true
+}
+
+// decide caches the decision for the packet key, evicting the least recently used decision when
+// the cache is full
+func (pf *pathFilter) decide(key string, allow bool) {
+	pf.mu.Lock()
+	defer pf.mu.Unlock()
+
+	if el, ok := pf.decided[key]; ok {
+		el.Value.(*filterDecision).allow = allow
+		pf.order.MoveToFront(el)
+		return
+	}
+	pf.decided[key] = pf.order.PushFront(&filterDecision{key: key, allow: allow})
+
+	for pf.order.Len() > filterCacheSize {
+		oldest := pf.order.Back()
+		pf.order.Remove(oldest)
+		delete(pf.decided, oldest.Value.(*filterDecision).key)
+	}
+}
+
+// pathFilters holds the packet filters of the running paths by name
+var pathFilters sync.Map
+
+// SetPathFilter sets the packet filter of the named path, a nil filter removes it
+func SetPathFilter(name string, pf *PacketFilter) error {
+	if pf == nil {
+		pathFilters.Delete(name)
+		return nil
+	}
+	if err := pf.Validate(); err != nil {
+		return err
+	}
+	pathFilters.Store(name, newPathFilter(pf))
+	return nil
+}
+
+// FilterPackets drops the packet sequences that the filter of the path between src and dst does not
+// allow. Paths without a filter relay every packet.
+func FilterPackets(src, dst *Chain, sp *RelaySequences) (*RelaySequences, error) {
+	v, ok := pathFilters.Load(src.PathName())
+	if !ok {
+		return sp, nil
+	}
+	pf := v.(*pathFilter)
+
+	srcSeqs, err := pf.allowed(src, sp.Src)
+	if err != nil {
+		return nil, err
+	}
+	dstSeqs, err := pf.allowed(dst, sp.Dst)
+	if err != nil {
+		return nil, err
+	}
+	return &RelaySequences{Src: srcSeqs, Dst: dstSeqs}, nil
+}
+
+// allowed returns the sequences of packets sent from c that pass the filter
+func (pf *pathFilter) allowed(c *Chain, seqs []uint64) ([]uint64, error) {
+	if len(seqs) == 0 {
+		return seqs, nil
+	}
+	if !pf.filter.AllowChannel(c.PathEnd.ChannelID) {
+		return []uint64{}, nil
+	}
+	if !pf.filter.transferRules() {
+		return seqs, nil
+	}
+
+	// the packets are queried without holding the lock, so a slow query does not hold up other callers
+	out := []uint64{}
+	for _, seq := range seqs {
+		key := fmt.Sprintf("%s/%s/%s/%d", c.ChainID(), c.PathEnd.PortID, c.PathEnd.ChannelID, seq)
+		allow, ok := pf.decision(key)
+		if !ok {
+			packet, err := c.ChainProvider.QuerySendPacket(c.PathEnd.ChannelID, c.PathEnd.PortID, seq)
+			if err != nil {
+				return nil, err
+			}
+			err = pf.filter.AllowTransfer(packet.Data())
+			if err != nil && c.debug {
+				c.Log(fmt.Sprintf("- [%s]chan{%s}port{%s} skipping packet seq(%d): %s",
+					c.ChainID(), c.PathEnd.ChannelID, c.PathEnd.PortID, seq, err))
+			}
+			allow = err == nil
+			pf.decide(key, allow)
+		}
+		if allow {
+			out = append(out, seq)
+		}
+	}
+	return out, nil
+}
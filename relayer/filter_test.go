@@ -0,0 +1,49 @@
+package relayer
+
+import (
+	"fmt"
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPathFilterDecisionsBounded(t *testing.T) {
+	pf := newPathFilter(&PacketFilter{})
+	for i := 0; i <= filterCacheSize; i++ {
+		pf.decide(fmt.Sprintf("ibc-0/transfer/channel-0/%d", i), false)
+	}
+	require.Len(t, pf.decided, filterCacheSize)
+
+	// the oldest decision was evicted
+	_, ok := pf.decision("ibc-0/transfer/channel-0/0")
+	require.False(t, ok)
+	_, ok = pf.decision(fmt.Sprintf("ibc-0/transfer/channel-0/%d", filterCacheSize))
+	require.True(t, ok)
+}
+
+func TestRelayPacketFiltered(t *testing.T) {
+	src, dst := newMockLink(t)
+	src, err := src.WithPath("filtered", src.PathEnd)
+	require.NoError(t, err)
+	dst, err = dst.WithPath("filtered", dst.PathEnd)
+	require.NoError(t, err)
+	require.NoError(t, SetPathFilter("filtered", &PacketFilter{MinAmount: "5000" + testDenom}))
+	defer func() { require.NoError(t, SetPathFilter("filtered", nil)) }()
+
+	dstAddr, err := dst.ChainProvider.Address()
+	require.NoError(t, err)
+	require.NoError(t, src.SendTransferMsg(dst, sdk.NewInt64Coin(testDenom, 1000), dstAddr, 0, 0))
+	requireBalance(t, src, testDenom, 9000)
+
+	sp, err := UnrelayedSequences(src, dst)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{1}, sp.Src)
+
+	// the transfer is below the minimum amount of the filter, so it is left unrelayed
+	require.NoError(t, RelayPacket(src, dst, sp, testMaxTxSize, testMaxMsgLength, 1))
+	require.NoError(t, dst.ChainProvider.WaitForNBlocks(2))
+	sp, err = UnrelayedSequences(src, dst)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{1}, sp.Src)
+}
@@ -0,0 +1,377 @@
+package relayer
+
+import (
+	"fmt"
+
+	conntypes "github.com/cosmos/ibc-go/v2/modules/core/03-connection/types"
+	chantypes "github.com/cosmos/ibc-go/v2/modules/core/04-channel/types"
+)
+
+// LinkStep is a step that remains to be executed on a chain to link the ends of a path
+type LinkStep struct {
+	ChainID string
+	Action  string
+}
+
+func (s LinkStep) String() string {
+	return fmt.Sprintf("[%s] %s", s.ChainID, s.Action)
+}
+
+// ResumeLink inspects the clients, connections and channels on src and dst and fills in the identifiers
+// missing from their path ends with those of a handshake that was left halfway, so that CreateClients,
+// CreateOpenConnections and CreateOpenChannels drive it from the step it stopped at instead of starting
+// another one. It returns the steps that remain to open the channel between src and dst, and whether
+// a path end was modified.
+func ResumeLink(src, dst *Chain) (plan []LinkStep, modified bool, err error) {
+	// clients
+	for _, c := range []struct{ end, counterparty *Chain }{{src, dst}, {dst, src}} {
+		if c.end.PathEnd.ClientID == "" {
+			plan = append(plan, LinkStep{c.end.ChainID(), fmt.Sprintf("create a client of %s, or reuse a matching one", c.counterparty.ChainID())})
+			continue
+		}
+		if _, err = c.end.ChainProvider.QueryClientState(0, c.end.PathEnd.ClientID); err != nil {
+			return nil, false, fmt.Errorf("failed to query client %s on %s: %w", c.end.PathEnd.ClientID, c.end.ChainID(), err)
+		}
+	}
+	if src.PathEnd.ClientID == "" || dst.PathEnd.ClientID == "" {
+		plan = append(plan, handshakePlan("Conn", src, dst, conntypes.UNINITIALIZED, conntypes.UNINITIALIZED)...)
+		plan = append(plan, handshakePlan("Chan", src, dst, conntypes.UNINITIALIZED, conntypes.UNINITIALIZED)...)
+		return plan, false, nil
+	}
+
+	// connection
+	if modified, err = resumeConnection(src, dst); err != nil {
+		return nil, modified, err
+	}
+	srcConnState, dstConnState, err := connectionStates(src, dst)
+	if err != nil {
+		return nil, modified, err
+	}
+	connSteps, err := handshakeSteps("Conn", src, dst, srcConnState, dstConnState)
+	if err != nil {
+		return nil, modified, fmt.Errorf("cannot resume the connection handshake: %w", err)
+	}
+	plan = append(plan, connSteps...)
+	if src.PathEnd.ConnectionID == "" || dst.PathEnd.ConnectionID == "" {
+		plan = append(plan, handshakePlan("Chan", src, dst, conntypes.UNINITIALIZED, conntypes.UNINITIALIZED)...)
+		return plan, modified, nil
+	}
+
+	// channel
+	chanModified, err := resumeChannel(src, dst)
+	modified = modified || chanModified
+	if err != nil {
+		return nil, modified, err
+	}
+	srcChanState, dstChanState, err := channelStates(src, dst)
+	if err != nil {
+		return nil, modified, err
+	}
+	chanSteps, err := handshakeSteps("Chan", src, dst, srcChanState, dstChanState)
+	if err != nil {
+		return nil, modified, fmt.Errorf("cannot resume the channel handshake: %w", err)
+	}
+	return append(plan, chanSteps...), modified, nil
+}
+
+// resumeConnection sets the connection identifiers missing from the path ends of src and dst to those of
+// a connection handshake between their clients that isn't complete yet, if there is one
+func resumeConnection(src, dst *Chain) (modified bool, err error) {
+	if src.PathEnd.ConnectionID != "" && dst.PathEnd.ConnectionID != "" {
+		return false, nil
+	}
+
+	srcConns, err := src.ChainProvider.QueryConnections()
+	if err != nil {
+		return false, err
+	}
+	dstConns, err := dst.ChainProvider.QueryConnections()
+	if err != nil {
+		return false, err
+	}
+
+	srcID, dstID := findConnectionHandshake(src, dst, srcConns, dstConns)
+	if src.PathEnd.ConnectionID == "" && srcID != "" {
+		src.Log(fmt.Sprintf("- resuming the handshake of [%s]conn{%s}", src.ChainID(), srcID))
+		src.PathEnd.ConnectionID, modified = srcID, true
+	}
+	if dst.PathEnd.ConnectionID == "" && dstID != "" {
+		dst.Log(fmt.Sprintf("- resuming the handshake of [%s]conn{%s}", dst.ChainID(), dstID))
+		dst.PathEnd.ConnectionID, modified = dstID, true
+	}
+	return modified, nil
+}
+
+// findConnectionHandshake returns the ends on src and dst of a connection between their clients whose
+// handshake isn't complete. When one of the path ends already has a connection, its counterparty is returned.
+func findConnectionHandshake(src, dst *Chain, srcConns, dstConns []*conntypes.IdentifiedConnection) (srcID, dstID string) {
+	switch {
+	case src.PathEnd.ConnectionID != "":
+		if end := findConnection(srcConns, src.PathEnd.ConnectionID); end != nil {
+			if counterparty := pairedConnection(end, dst, dstConns); counterparty != nil {
+				return end.Id, counterparty.Id
+			}
+		}
+		return src.PathEnd.ConnectionID, ""
+	case dst.PathEnd.ConnectionID != "":
+		if end := findConnection(dstConns, dst.PathEnd.ConnectionID); end != nil {
+			if counterparty := pairedConnection(end, src, srcConns); counterparty != nil {
+				return counterparty.Id, end.Id
+			}
+		}
+		return "", dst.PathEnd.ConnectionID
+	}
+
+	for _, end := range srcConns {
+		if end.ClientId != src.PathEnd.ClientID || end.Counterparty.ClientId != dst.PathEnd.ClientID {
+			continue
+		}
+		counterparty := pairedConnection(end, dst, dstConns)
+		switch {
+		case counterparty == nil && end.State == conntypes.INIT:
+			return end.Id, ""
+		case counterparty != nil && (end.State != conntypes.OPEN || counterparty.State != conntypes.OPEN):
+			return end.Id, counterparty.Id
+		}
+	}
+	for _, end := range dstConns {
+		if end.ClientId != dst.PathEnd.ClientID || end.Counterparty.ClientId != src.PathEnd.ClientID {
+			continue
+		}
+		if end.State == conntypes.INIT && pairedConnection(end, src, srcConns) == nil {
+			return "", end.Id
+		}
+	}
+	return "", ""
+}
+
+// findConnection returns the connection with the given identifier
+func findConnection(conns []*conntypes.IdentifiedConnection, id string) *conntypes.IdentifiedConnection {
+	for _, conn := range conns {
+		if conn.Id == id {
+			return conn
+		}
+	}
+	return nil
+}
+
+// pairedConnection returns the connection of counterparty that is the other end of the given connection
+func pairedConnection(end *conntypes.IdentifiedConnection, counterparty *Chain, conns []*conntypes.IdentifiedConnection) *conntypes.IdentifiedConnection {
+	for _, conn := range conns {
+		if conn.ClientId != counterparty.PathEnd.ClientID || conn.Counterparty.ClientId != end.ClientId {
+			continue
+		}
+		if conn.Id == end.Counterparty.ConnectionId || conn.Counterparty.ConnectionId == end.Id {
+			return conn
+		}
+	}
+	return nil
+}
+
+// connectionStates returns the states of the connection ends of src and dst, UNINITIALIZED for an end
+// that isn't set
+func connectionStates(src, dst *Chain) (srcState, dstState conntypes.State, err error) {
+	states := make([]conntypes.State, 2)
+	for i, c := range []*Chain{src, dst} {
+		if c.PathEnd.ConnectionID == "" {
+			continue
+		}
+		res, err := c.ChainProvider.QueryConnection(0, c.PathEnd.ConnectionID)
+		if err != nil {
+			return 0, 0, err
+		}
+		if res.Connection.State == conntypes.UNINITIALIZED {
+			return 0, 0, fmt.Errorf("connection %s not found on %s", c.PathEnd.ConnectionID, c.ChainID())
+		}
+		states[i] = res.Connection.State
+	}
+	return states[0], states[1], nil
+}
+
+// resumeChannel sets the channel identifiers missing from the path ends of src and dst to those of a
+// channel handshake on their connection and ports that isn't complete yet, if there is one
+func resumeChannel(src, dst *Chain) (modified bool, err error) {
+	if src.PathEnd.ChannelID != "" && dst.PathEnd.ChannelID != "" {
+		return false, nil
+	}
+
+	srcChans, err := src.ChainProvider.QueryChannels()
+	if err != nil {
+		return false, err
+	}
+	dstChans, err := dst.ChainProvider.QueryChannels()
+	if err != nil {
+		return false, err
+	}
+
+	srcID, dstID := findChannelHandshake(src, dst, srcChans, dstChans)
+	if src.PathEnd.ChannelID == "" && srcID != "" {
+		src.Log(fmt.Sprintf("- resuming the handshake of [%s]chan{%s}", src.ChainID(), srcID))
+		src.PathEnd.ChannelID, modified = srcID, true
+	}
+	if dst.PathEnd.ChannelID == "" && dstID != "" {
+		dst.Log(fmt.Sprintf("- resuming the handshake of [%s]chan{%s}", dst.ChainID(), dstID))
+		dst.PathEnd.ChannelID, modified = dstID, true
+	}
+	return modified, nil
+}
+
+// findChannelHandshake returns the ends on src and dst of a channel on their connection and ports whose
+// handshake isn't complete. When one of the path ends already has a channel, its counterparty is returned.
+func findChannelHandshake(src, dst *Chain, srcChans, dstChans []*chantypes.IdentifiedChannel) (srcID, dstID string) {
+	switch {
+	case src.PathEnd.ChannelID != "":
+		if end := findChannel(srcChans, src.PathEnd.ChannelID, src.PathEnd.PortID); end != nil {
+			if counterparty := pairedChannel(end, dst, dstChans); counterparty != nil {
+				return end.ChannelId, counterparty.ChannelId
+			}
+		}
+		return src.PathEnd.ChannelID, ""
+	case dst.PathEnd.ChannelID != "":
+		if end := findChannel(dstChans, dst.PathEnd.ChannelID, dst.PathEnd.PortID); end != nil {
+			if counterparty := pairedChannel(end, src, srcChans); counterparty != nil {
+				return counterparty.ChannelId, end.ChannelId
+			}
+		}
+		return "", dst.PathEnd.ChannelID
+	}
+
+	for _, end := range srcChans {
+		if !onPath(end, src, dst) {
+			continue
+		}
+		counterparty := pairedChannel(end, dst, dstChans)
+		switch {
+		case counterparty == nil && end.State == chantypes.INIT:
+			return end.ChannelId, ""
+		case counterparty != nil && (end.State != chantypes.OPEN || counterparty.State != chantypes.OPEN) &&
+			end.State != chantypes.CLOSED && counterparty.State != chantypes.CLOSED:
+			return end.ChannelId, counterparty.ChannelId
+		}
+	}
+	for _, end := range dstChans {
+		if onPath(end, dst, src) && end.State == chantypes.INIT && pairedChannel(end, src, srcChans) == nil {
+			return "", end.ChannelId
+		}
+	}
+	return "", ""
+}
+
+// findChannel returns the channel with the given identifiers
+func findChannel(chans []*chantypes.IdentifiedChannel, channelID, portID string) *chantypes.IdentifiedChannel {
+	for _, ch := range chans {
+		if ch.ChannelId == channelID && ch.PortId == portID {
+			return ch
+		}
+	}
+	return nil
+}
+
+// pairedChannel returns the channel of counterparty that is the other end of the given channel
+func pairedChannel(end *chantypes.IdentifiedChannel, counterparty *Chain, chans []*chantypes.IdentifiedChannel) *chantypes.IdentifiedChannel {
+	for _, ch := range chans {
+		if ch.PortId != end.Counterparty.PortId || ch.Counterparty.PortId != end.PortId ||
+			!IsConnectionFound(ch.ConnectionHops, counterparty.PathEnd.ConnectionID) {
+			continue
+		}
+		if ch.ChannelId == end.Counterparty.ChannelId || ch.Counterparty.ChannelId == end.ChannelId {
+			return ch
+		}
+	}
+	return nil
+}
+
+// onPath reports whether a channel of c is on the connection and ports of the path between c and counterparty
+func onPath(ch *chantypes.IdentifiedChannel, c, counterparty *Chain) bool {
+	return ch.PortId == c.PathEnd.PortID && ch.Counterparty.PortId == counterparty.PathEnd.PortID &&
+		IsConnectionFound(ch.ConnectionHops, c.PathEnd.ConnectionID)
+}
+
+// channelStates returns the states of the channel ends of src and dst as connection states, which share
+// their values up to OPEN, UNINITIALIZED for an end that isn't set
+func channelStates(src, dst *Chain) (srcState, dstState conntypes.State, err error) {
+	states := make([]conntypes.State, 2)
+	for i, c := range []*Chain{src, dst} {
+		if c.PathEnd.ChannelID == "" {
+			continue
+		}
+		res, err := c.ChainProvider.QueryChannel(0, c.PathEnd.ChannelID, c.PathEnd.PortID)
+		if err != nil {
+			return 0, 0, err
+		}
+		switch res.Channel.State {
+		case chantypes.UNINITIALIZED:
+			return 0, 0, fmt.Errorf("channel %s not found on %s", c.PathEnd.ChannelID, c.ChainID())
+		case chantypes.CLOSED:
+			return 0, 0, fmt.Errorf("channel %s on %s is closed", c.PathEnd.ChannelID, c.ChainID())
+		}
+		states[i] = conntypes.State(res.Channel.State)
+	}
+	return states[0], states[1], nil
+}
+
+// handshakeSteps returns the steps that ExecuteConnectionStep or ExecuteChannelStep take to open both ends of
+// a handshake from the given states. kind prefixes the names of the steps, either "Conn" or "Chan".
+func handshakeSteps(kind string, src, dst *Chain, srcState, dstState conntypes.State) ([]LinkStep, error) {
+	switch {
+	case srcState == conntypes.UNINITIALIZED && dstState == conntypes.UNINITIALIZED,
+		srcState == conntypes.INIT && dstState == conntypes.UNINITIALIZED,
+		srcState == conntypes.UNINITIALIZED && dstState == conntypes.INIT,
+		srcState == conntypes.INIT && dstState == conntypes.INIT,
+		(srcState == conntypes.INIT || srcState == conntypes.TRYOPEN) && dstState == conntypes.TRYOPEN,
+		srcState == conntypes.TRYOPEN && dstState == conntypes.INIT,
+		srcState == conntypes.TRYOPEN && dstState == conntypes.OPEN,
+		srcState == conntypes.OPEN && dstState == conntypes.TRYOPEN,
+		srcState == conntypes.OPEN && dstState == conntypes.OPEN:
+		return handshakePlan(kind, src, dst, srcState, dstState), nil
+	default:
+		return nil, fmt.Errorf("the end on %s is in state %s and the end on %s in state %s", src.ChainID(), srcState, dst.ChainID(), dstState)
+	}
+}
+
+// handshakePlan returns the steps of a handshake from states that handshakeSteps accepts
+func handshakePlan(kind string, src, dst *Chain, srcState, dstState conntypes.State) []LinkStep {
+	var plan []LinkStep
+	for {
+		switch {
+		// OpenInit on source
+		case srcState == conntypes.UNINITIALIZED && dstState == conntypes.UNINITIALIZED:
+			plan = append(plan, LinkStep{src.ChainID(), kind + "OpenInit"})
+			srcState = conntypes.INIT
+
+		// OpenTry on source, as the counterparty is INIT or both ends are INIT (crossing hellos)
+		case srcState == conntypes.UNINITIALIZED && dstState == conntypes.INIT,
+			srcState == conntypes.INIT && dstState == conntypes.INIT:
+			plan = append(plan, LinkStep{src.ChainID(), kind + "OpenTry"})
+			srcState = conntypes.TRYOPEN
+
+		// OpenTry on counterparty
+		case srcState == conntypes.INIT && dstState == conntypes.UNINITIALIZED:
+			plan = append(plan, LinkStep{dst.ChainID(), kind + "OpenTry"})
+			dstState = conntypes.TRYOPEN
+
+		// OpenAck on source
+		case (srcState == conntypes.INIT || srcState == conntypes.TRYOPEN) && dstState == conntypes.TRYOPEN:
+			plan = append(plan, LinkStep{src.ChainID(), kind + "OpenAck"})
+			srcState = conntypes.OPEN
+
+		// OpenAck on counterparty
+		case srcState == conntypes.TRYOPEN && dstState == conntypes.INIT:
+			plan = append(plan, LinkStep{dst.ChainID(), kind + "OpenAck"})
+			dstState = conntypes.OPEN
+
+		// OpenConfirm on source
+		case srcState == conntypes.TRYOPEN && dstState == conntypes.OPEN:
+			plan = append(plan, LinkStep{src.ChainID(), kind + "OpenConfirm"})
+			srcState = conntypes.OPEN
+
+		// OpenConfirm on counterparty
+		case srcState == conntypes.OPEN && dstState == conntypes.TRYOPEN:
+			plan = append(plan, LinkStep{dst.ChainID(), kind + "OpenConfirm"})
+			dstState = conntypes.OPEN
+
+		default:
+			return plan
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package relayer
+
+import (
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	conntypes "github.com/cosmos/ibc-go/v2/modules/core/03-connection/types"
+	chantypes "github.com/cosmos/ibc-go/v2/modules/core/04-channel/types"
+	"github.com/cosmos/relayer/relayer/provider/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/tendermint/tendermint/libs/log"
+)
+
+const (
+	// testBlockTime is the block time of the mock chains of the tests
+	testBlockTime = 100 * time.Millisecond
+
+	// testStepTimeout is the interval between two handshake steps
+	testStepTimeout = 3 * testBlockTime
+
+	testDenom = "samoleans"
+)
+
+// newMockChain returns a chain backed by a mock chain that commits a block every testBlockTime and
+// whose key holds some testDenom
+func newMockChain(t *testing.T, chainID string) *Chain {
+	t.Helper()
+	mc := mock.NewChain(chainID, time.Now())
+	mc.BlockTime = testBlockTime
+	t.Cleanup(mc.Run())
+
+	p := mock.NewProvider(mc, "testkey")
+	addr, err := p.Address()
+	require.NoError(t, err)
+	mc.SetBalance(addr, sdk.NewCoins(sdk.NewInt64Coin(testDenom, 10000)))
+
+	c := &Chain{ChainProvider: p, Chainid: chainID}
+	c.Init(log.NewNopLogger(), false)
+	require.NoError(t, c.SetPath(&PathEnd{ChainID: chainID, PortID: "transfer", Order: "unordered", Version: "ics20-1"}))
+	return c
+}
+
+// newMockPath returns two chains with clients of each other
+func newMockPath(t *testing.T) (src, dst *Chain) {
+	t.Helper()
+	src, dst = newMockChain(t, "ibc-0"), newMockChain(t, "ibc-1")
+	_, err := src.CreateClients(dst, true, true, false)
+	require.NoError(t, err)
+	return src, dst
+}
+
+// newMockLink returns two chains with an open transfer channel between them
+func newMockLink(t *testing.T) (src, dst *Chain) {
+	t.Helper()
+	src, dst = newMockPath(t)
+
+	// the handshake steps would otherwise tick along with the blocks of both chains, and a block
+	// committed between the update header and the proof query of a step makes it fail
+	time.Sleep(testBlockTime / 2)
+	_, err := src.CreateOpenConnections(dst, 3, testStepTimeout)
+	require.NoError(t, err)
+	_, err = src.CreateOpenChannels(dst, 3, testStepTimeout)
+	require.NoError(t, err)
+	return src, dst
+}
+
+func TestCreateClients(t *testing.T) {
+	src, dst := newMockPath(t)
+	require.NotEmpty(t, src.ClientID())
+	require.NotEmpty(t, dst.ClientID())
+
+	srcClient, err := src.ChainProvider.QueryClientState(0, src.ClientID())
+	require.NoError(t, err)
+	require.Equal(t, dst.ChainID(), srcClient.(interface{ GetChainID() string }).GetChainID())
+
+	dstClient, err := dst.ChainProvider.QueryClientState(0, dst.ClientID())
+	require.NoError(t, err)
+	require.Equal(t, src.ChainID(), dstClient.(interface{ GetChainID() string }).GetChainID())
+
+	// the existing clients are found instead of creating new ones
+	srcClientID, dstClientID := src.ClientID(), dst.ClientID()
+	src.PathEnd.ClientID, dst.PathEnd.ClientID = "", ""
+	modified, err := src.CreateClients(dst, true, true, false)
+	require.NoError(t, err)
+	require.True(t, modified)
+	require.Equal(t, srcClientID, src.ClientID())
+	require.Equal(t, dstClientID, dst.ClientID())
+
+	clients, err := src.ChainProvider.QueryClients()
+	require.NoError(t, err)
+	require.Len(t, clients, 1)
+}
+
+func TestCreateOpenConnectionsAndChannels(t *testing.T) {
+	src, dst := newMockLink(t)
+
+	srcConn, err := src.ChainProvider.QueryConnection(0, src.ConnectionID())
+	require.NoError(t, err)
+	require.Equal(t, conntypes.OPEN, srcConn.Connection.State)
+	require.Equal(t, dst.ConnectionID(), srcConn.Connection.Counterparty.ConnectionId)
+
+	dstConn, err := dst.ChainProvider.QueryConnection(0, dst.ConnectionID())
+	require.NoError(t, err)
+	require.Equal(t, conntypes.OPEN, dstConn.Connection.State)
+	require.Equal(t, src.ConnectionID(), dstConn.Connection.Counterparty.ConnectionId)
+
+	srcChan, err := src.ChainProvider.QueryChannel(0, src.PathEnd.ChannelID, src.PathEnd.PortID)
+	require.NoError(t, err)
+	require.Equal(t, chantypes.OPEN, srcChan.Channel.State)
+	require.Equal(t, dst.PathEnd.ChannelID, srcChan.Channel.Counterparty.ChannelId)
+	require.Equal(t, "ics20-1", srcChan.Channel.Version)
+
+	dstChan, err := dst.ChainProvider.QueryChannel(0, dst.PathEnd.ChannelID, dst.PathEnd.PortID)
+	require.NoError(t, err)
+	require.Equal(t, chantypes.OPEN, dstChan.Channel.State)
+	require.Equal(t, src.PathEnd.ChannelID, dstChan.Channel.Counterparty.ChannelId)
+}
+
+func TestResumeLink(t *testing.T) {
+	src, dst := newMockPath(t)
+	time.Sleep(testBlockTime / 2) // see newMockLink
+
+	// open the connection up to TRYOPEN on dst, then lose its identifiers like an interrupted link would
+	for src.ConnectionID() == "" || dst.ConnectionID() == "" {
+		_, _, _, err := ExecuteConnectionStep(src, dst)
+		require.NoError(t, err)
+		time.Sleep(testStepTimeout)
+	}
+	srcConnID, dstConnID := src.ConnectionID(), dst.ConnectionID()
+	src.PathEnd.ConnectionID, dst.PathEnd.ConnectionID = "", ""
+
+	plan, modified, err := ResumeLink(src, dst)
+	require.NoError(t, err)
+	require.True(t, modified)
+	require.Equal(t, srcConnID, src.ConnectionID())
+	require.Equal(t, dstConnID, dst.ConnectionID())
+	require.Equal(t, []LinkStep{
+		{src.ChainID(), "ConnOpenAck"},
+		{dst.ChainID(), "ConnOpenConfirm"},
+		{src.ChainID(), "ChanOpenInit"},
+		{dst.ChainID(), "ChanOpenTry"},
+		{src.ChainID(), "ChanOpenAck"},
+		{dst.ChainID(), "ChanOpenConfirm"},
+	}, plan)
+
+	_, err = src.CreateOpenConnections(dst, 3, testStepTimeout)
+	require.NoError(t, err)
+	require.Equal(t, srcConnID, src.ConnectionID())
+	require.Equal(t, dstConnID, dst.ConnectionID())
+	_, err = src.CreateOpenChannels(dst, 3, testStepTimeout)
+	require.NoError(t, err)
+
+	plan, modified, err = ResumeLink(src, dst)
+	require.NoError(t, err)
+	require.False(t, modified)
+	require.Empty(t, plan)
+}
@@ -3,6 +3,8 @@ package relayer
 import (
 	"encoding/json"
 	"fmt"
+	"io"
+	"os"
 	"strconv"
 	"strings"
 	"time"
@@ -10,13 +12,73 @@ import (
 	"github.com/cosmos/relayer/relayer/provider"
 	"github.com/cosmos/relayer/relayer/provider/cosmos"
 
-	sdk "github.com/cosmos/cosmos-sdk/types"
 	conntypes "github.com/cosmos/ibc-go/v2/modules/core/03-connection/types"
 	chantypes "github.com/cosmos/ibc-go/v2/modules/core/04-channel/types"
+	"github.com/tendermint/tendermint/libs/log"
 )
 
+// Log formats accepted by NewLogger
+const (
+	LogFormatText = "text"
+	LogFormatJSON = "json"
+)
+
+// NewLogger returns a logger writing to stdout in the given format that drops entries below the
+// given level, which is one of debug, info, error or none
+func NewLogger(format, level string) (log.Logger, error) {
+	return newLogger(os.Stdout, format, level)
+}
+
+func newLogger(w io.Writer, format, level string) (log.Logger, error) {
+	var logger log.Logger
+	switch format {
+	case LogFormatText:
+		logger = log.NewTMLogger(log.NewSyncWriter(w))
+	case LogFormatJSON:
+		logger = log.NewTMJSONLogger(log.NewSyncWriter(w))
+	default:
+		return nil, fmt.Errorf("invalid log format %q, expected %s or %s", format, LogFormatText, LogFormatJSON)
+	}
+
+	allowed, err := log.AllowLevel(level)
+	if err != nil {
+		return nil, err
+	}
+	return log.NewFilter(logger, allowed), nil
+}
+
+// txLogFields returns the structured log fields describing a tx and the msgs it was built from
+func txLogFields(res *provider.RelayerTxResponse, msgs []provider.RelayerMessage) []interface{} {
+	fields := []interface{}{"msg_types", getMsgTypes(msgs)}
+	if res != nil {
+		fields = append(fields, "tx_hash", res.TxHash, "height", res.Height, "gas_used", res.GasUsed, "code", res.Code)
+	}
+
+	var channels, sequences []string
+	for _, msg := range msgs {
+		p, ok := inFlightPacket(msg)
+		if !ok {
+			continue
+		}
+		channel := p.SourcePort + "/" + p.SourceChannel
+		if len(channels) == 0 || channels[len(channels)-1] != channel {
+			channels = append(channels, channel)
+		}
+		sequences = append(sequences, strconv.FormatUint(p.Sequence, 10))
+	}
+	if len(sequences) > 0 {
+		fields = append(fields, "src_channel", strings.Join(channels, ","), "sequences", strings.Join(sequences, ","))
+	}
+	return fields
+}
+
 // LogFailedTx takes the transaction and the messages to create it and logs the appropriate data
 func (c *Chain) LogFailedTx(res *provider.RelayerTxResponse, err error, msgs []provider.RelayerMessage) {
+	// a simulated tx has already been printed
+	if isDryRun(err) {
+		return
+	}
+
 	if c.debug {
 		c.Log(fmt.Sprintf("- [%s] -> failed sending transaction:", c.ChainID()))
 		for _, msg := range msgs {
@@ -25,7 +87,8 @@ func (c *Chain) LogFailedTx(res *provider.RelayerTxResponse, err error, msgs []p
 	}
 
 	if err != nil {
-		c.logger.Error(fmt.Errorf("- [%s] -> err(%v)", c.ChainID(), err).Error())
+		c.logger.Error(fmt.Errorf("- [%s] -> err(%v)", c.ChainID(), err).Error(),
+			append(txLogFields(res, msgs), "error", err.Error())...)
 		if res == nil {
 			return
 		}
@@ -33,8 +96,8 @@ func (c *Chain) LogFailedTx(res *provider.RelayerTxResponse, err error, msgs []p
 
 	if res != nil {
 		if res.Code != 0 && res.Data != "" {
-			c.logger.Info(fmt.Sprintf("✘ [%s]@{%d} - msg(%s) err(%d:%s)",
-				c.ChainID(), res.Height, getMsgTypes(msgs), res.Code, res.Data))
+			c.logger.Error(fmt.Sprintf("✘ [%s]@{%d} - msg(%s) err(%d:%s)",
+				c.ChainID(), res.Height, getMsgTypes(msgs), res.Code, res.Data), txLogFields(res, msgs)...)
 		}
 	}
 
@@ -69,13 +132,16 @@ func (c *Chain) PrintRelayerTxResponse(res *provider.RelayerTxResponse, text, in
 }
 
 // LogSuccessTx take the transaction and the messages to create it and logs the appropriate data
-func (c *Chain) LogSuccessTx(res *sdk.TxResponse, msgs []provider.RelayerMessage) {
-	c.logger.Info(fmt.Sprintf("✔ [%s]@{%d} - msg(%s) hash(%s)", c.ChainID(), res.Height, getMsgTypes(msgs), res.TxHash))
+func (c *Chain) LogSuccessTx(res *provider.RelayerTxResponse, msgs []provider.RelayerMessage) {
+	c.logger.Info(fmt.Sprintf("✔ [%s]@{%d} - msg(%s) hash(%s)", c.ChainID(), res.Height, getMsgTypes(msgs), res.TxHash),
+		txLogFields(res, msgs)...)
 }
 
 func (c *Chain) logPacketsRelayed(dst *Chain, num int) {
-	dst.Log(fmt.Sprintf("★ Relayed %d packets: [%s]port{%s}->[%s]port{%s}",
-		num, dst.ChainID(), dst.PathEnd.PortID, c.ChainID(), c.PathEnd.PortID))
+	dst.logger.Info(fmt.Sprintf("★ Relayed %d packets: [%s]port{%s}->[%s]port{%s}",
+		num, dst.ChainID(), dst.PathEnd.PortID, c.ChainID(), c.PathEnd.PortID),
+		"src_chain_id", dst.ChainID(), "src_channel", dst.PathEnd.ChannelID,
+		"dst_chain_id", c.ChainID(), "dst_channel", c.PathEnd.ChannelID, "packets", num)
 }
 
 func logChannelStates(src, dst *Chain, srcChan, dstChan *chantypes.QueryChannelResponse) {
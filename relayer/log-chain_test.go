@@ -0,0 +1,87 @@
+package relayer
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	clienttypes "github.com/cosmos/ibc-go/v2/modules/core/02-client/types"
+	chantypes "github.com/cosmos/ibc-go/v2/modules/core/04-channel/types"
+	"github.com/cosmos/relayer/relayer/provider"
+	"github.com/cosmos/relayer/relayer/provider/cosmos"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := newLogger(&buf, LogFormatJSON, "info")
+	require.NoError(t, err)
+	logger.Debug("dropped")
+	logger.Info("relayed", "chain_id", "ibc-0")
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry), buf.String())
+	require.Equal(t, "info", entry["level"])
+	require.Equal(t, "relayed", entry["_msg"])
+	require.Equal(t, "ibc-0", entry["chain_id"])
+
+	// entries below the level are dropped
+	buf.Reset()
+	logger, err = newLogger(&buf, LogFormatText, "error")
+	require.NoError(t, err)
+	logger.Info("relayed")
+	require.Empty(t, buf.String())
+	logger.Error("failed", "chain_id", "ibc-0")
+	require.True(t, strings.HasPrefix(buf.String(), "E["), buf.String())
+	require.Contains(t, buf.String(), "chain_id=ibc-0")
+
+	buf.Reset()
+	logger, err = newLogger(&buf, LogFormatText, "none")
+	require.NoError(t, err)
+	logger.Error("failed")
+	require.Empty(t, buf.String())
+
+	_, err = newLogger(&buf, "yaml", "info")
+	require.Error(t, err)
+	_, err = newLogger(&buf, LogFormatJSON, "trace")
+	require.Error(t, err)
+}
+
+func TestTxLogFields(t *testing.T) {
+	packet := func(channel string, seq uint64) chantypes.Packet {
+		return chantypes.Packet{SourcePort: "transfer", SourceChannel: channel, Sequence: seq}
+	}
+	msgs := []provider.RelayerMessage{
+		cosmos.NewCosmosMessage(&clienttypes.MsgUpdateClient{ClientId: "07-tendermint-0"}),
+		cosmos.NewCosmosMessage(&chantypes.MsgRecvPacket{Packet: packet("channel-0", 1)}),
+		cosmos.NewCosmosMessage(&chantypes.MsgRecvPacket{Packet: packet("channel-0", 2)}),
+		cosmos.NewCosmosMessage(&chantypes.MsgAcknowledgement{Packet: packet("channel-1", 7)}),
+	}
+
+	fields := func(res *provider.RelayerTxResponse) map[string]interface{} {
+		kvs := txLogFields(res, msgs)
+		require.Zero(t, len(kvs)%2)
+		out := make(map[string]interface{})
+		for i := 0; i < len(kvs); i += 2 {
+			out[kvs[i].(string)] = kvs[i+1]
+		}
+		return out
+	}
+
+	// a tx that was not committed only has the fields of its msgs
+	f := fields(nil)
+	require.Equal(t, getMsgTypes(msgs), f["msg_types"])
+	require.Equal(t, "transfer/channel-0,transfer/channel-1", f["src_channel"])
+	require.Equal(t, "1,2,7", f["sequences"])
+	require.NotContains(t, f, "tx_hash")
+
+	f = fields(&provider.RelayerTxResponse{TxHash: "ABCD", Height: 10, GasUsed: 500, Code: 0})
+	require.Equal(t, "ABCD", f["tx_hash"])
+	require.Equal(t, int64(10), f["height"])
+	require.Equal(t, int64(500), f["gas_used"])
+
+	// msgs without packets add no packet fields
+	kvs := txLogFields(nil, msgs[:1])
+	require.Equal(t, []interface{}{"msg_types", "0:" + sdk.MsgTypeURL(&clienttypes.MsgUpdateClient{})}, kvs)
+}
@@ -0,0 +1,185 @@
+package relayer
+
+import (
+	"fmt"
+	"math/big"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/relayer/relayer/provider"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Metrics holds the prometheus metrics reported by the relayer
+var Metrics = NewRelayerMetrics()
+
+// RelayerMetrics contains the collectors for the relayer's prometheus metrics, registered
+// on their own registry so they can be served without the go runtime metrics
+type RelayerMetrics struct {
+	Registry *prometheus.Registry
+
+	PacketsRelayed            *prometheus.CounterVec
+	AcknowledgementsRelayed   *prometheus.CounterVec
+	PacketsTimedOut           *prometheus.CounterVec
+	UnrelayedPackets          *prometheus.GaugeVec
+	UnrelayedAcknowledgements *prometheus.GaugeVec
+	TxsSucceeded              *prometheus.CounterVec
+	TxsFailed                 *prometheus.CounterVec
+	TxsPending                *prometheus.CounterVec
+	TxsResubmitted            *prometheus.CounterVec
+	GasUsed                   *prometheus.CounterVec
+	WalletBalance             *prometheus.GaugeVec
+	LatestHeight              *prometheus.GaugeVec
+	ClientExpiry              *prometheus.GaugeVec
+	FeesSpent                 *prometheus.CounterVec
+	FeeBudgetExceeded         *prometheus.GaugeVec
+	WalletBalanceLow          *prometheus.GaugeVec
+}
+
+// NewRelayerMetrics creates the relayer's collectors and registers them on a new registry
+func NewRelayerMetrics() *RelayerMetrics {
+	m := &RelayerMetrics{
+		Registry: prometheus.NewRegistry(),
+		PacketsRelayed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "rly_packets_relayed_total",
+			Help: "Number of packets relayed to a chain",
+		}, []string{"path", "chain_id"}),
+		AcknowledgementsRelayed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "rly_acknowledgements_relayed_total",
+			Help: "Number of acknowledgements relayed to a chain",
+		}, []string{"path", "chain_id"}),
+		PacketsTimedOut: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "rly_packets_timed_out_total",
+			Help: "Number of packets timed out on the chain that sent them",
+		}, []string{"path", "chain_id"}),
+		UnrelayedPackets: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "rly_unrelayed_packets",
+			Help: "Number of packets sent from a chain that have not been relayed yet",
+		}, []string{"path", "chain_id"}),
+		UnrelayedAcknowledgements: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "rly_unrelayed_acknowledgements",
+			Help: "Number of acknowledgements written on a chain that have not been relayed yet",
+		}, []string{"path", "chain_id"}),
+		TxsSucceeded: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "rly_txs_succeeded_total",
+			Help: "Number of relay transactions that were committed successfully",
+		}, []string{"path", "chain_id"}),
+		TxsFailed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "rly_txs_failed_total",
+			Help: "Number of relay transactions that failed to broadcast or execute",
+		}, []string{"path", "chain_id"}),
+		TxsPending: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "rly_txs_pending_total",
+			Help: "Number of relay transactions still in the mempool when the relayer stopped waiting for them",
+		}, []string{"path", "chain_id"}),
+		TxsResubmitted: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "rly_txs_resubmitted_total",
+			Help: "Number of relay transactions resubmitted after they were dropped or failed to broadcast",
+		}, []string{"path", "chain_id"}),
+		GasUsed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "rly_gas_used_total",
+			Help: "Gas used by relay transactions",
+		}, []string{"path", "chain_id"}),
+		WalletBalance: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "rly_wallet_balance",
+			Help: "Balance of the relayer key on a chain",
+		}, []string{"chain_id", "key", "denom"}),
+		LatestHeight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "rly_latest_height",
+			Help: "Latest height seen on a chain",
+		}, []string{"chain_id"}),
+		ClientExpiry: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "rly_client_expiry_seconds",
+			Help: "Seconds until a client expires",
+		}, []string{"path", "chain_id", "client_id"}),
+		FeesSpent: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "rly_fees_spent_total",
+			Help: "Fees spent by relay transactions, computed from the gas used",
+		}, []string{"path", "chain_id", "denom"}),
+		FeeBudgetExceeded: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "rly_fee_budget_exceeded",
+			Help: "Set to 1 while a path or chain fee budget is exceeded and relaying is paused",
+		}, []string{"budget"}),
+		WalletBalanceLow: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "rly_wallet_balance_low",
+			Help: "Set to 1 while the balance of a relayer key is below the minimum balance of its chain",
+		}, []string{"chain_id", "key"}),
+	}
+
+	m.Registry.MustRegister(
+		m.PacketsRelayed,
+		m.AcknowledgementsRelayed,
+		m.PacketsTimedOut,
+		m.UnrelayedPackets,
+		m.UnrelayedAcknowledgements,
+		m.TxsSucceeded,
+		m.TxsFailed,
+		m.TxsPending,
+		m.TxsResubmitted,
+		m.GasUsed,
+		m.WalletBalance,
+		m.LatestHeight,
+		m.ClientExpiry,
+		m.FeesSpent,
+		m.FeeBudgetExceeded,
+		m.WalletBalanceLow,
+	)
+	return m
+}
+
+// UpdateChainMetrics queries the latest height of the chain and the balances of its key and key pool
+func (m *RelayerMetrics) UpdateChainMetrics(c *Chain) error {
+	h, err := c.ChainProvider.QueryLatestHeight()
+	if err != nil {
+		return err
+	}
+	m.LatestHeight.WithLabelValues(c.ChainID()).Set(float64(h))
+
+	coins, err := c.ChainProvider.QueryBalance(c.ChainProvider.Key())
+	if err != nil {
+		return err
+	}
+	m.setWalletBalance(c, c.ChainProvider.Key(), coins)
+
+	for _, key := range c.ChainProvider.KeyPool() {
+		addr, err := c.ChainProvider.ShowAddress(key)
+		if err != nil {
+			return err
+		}
+		if coins, err = c.ChainProvider.QueryBalanceWithAddress(addr); err != nil {
+			return err
+		}
+		m.setWalletBalance(c, key, coins)
+	}
+	return nil
+}
+
+func (m *RelayerMetrics) setWalletBalance(c *Chain, key string, coins sdk.Coins) {
+	for _, coin := range coins {
+		amount, _ := new(big.Float).SetInt(coin.Amount.BigInt()).Float64()
+		m.WalletBalance.WithLabelValues(c.ChainID(), key, coin.Denom).Set(amount)
+	}
+}
+
+// recordTx records the outcome of a relay transaction sent to c, a tx still pending in the
+// mempool is counted apart since it may yet be committed
+func (m *RelayerMetrics) recordTx(path string, c *Chain, res *provider.RelayerTxResponse, outcome TxOutcome) {
+	switch outcome {
+	case TxCommitted:
+		m.TxsSucceeded.WithLabelValues(path, c.ChainID()).Inc()
+	case TxPending:
+		m.TxsPending.WithLabelValues(path, c.ChainID()).Inc()
+	default:
+		m.TxsFailed.WithLabelValues(path, c.ChainID()).Inc()
+	}
+	if res != nil && res.GasUsed > 0 {
+		m.GasUsed.WithLabelValues(path, c.ChainID()).Add(float64(res.GasUsed))
+	}
+}
+
+// pathLabel returns the value of the path label for metrics about the path between src and dst
+func pathLabel(src, dst *Chain) string {
+	if src.PathName() != "" {
+		return src.PathName()
+	}
+	return fmt.Sprintf("%s:%s-%s:%s", src.ChainID(), src.PathEnd.ChannelID, dst.ChainID(), dst.PathEnd.ChannelID)
+}
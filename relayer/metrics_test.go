@@ -0,0 +1,64 @@
+package relayer
+
+import (
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+// useMetrics replaces the metrics of the relayer with fresh ones for the duration of the test
+func useMetrics(t *testing.T) *RelayerMetrics {
+	prev := Metrics
+	Metrics = NewRelayerMetrics()
+	t.Cleanup(func() { Metrics = prev })
+	return Metrics
+}
+
+func TestMetricsRegistered(t *testing.T) {
+	m := NewRelayerMetrics()
+	for _, c := range []prometheus.Collector{
+		m.PacketsRelayed, m.AcknowledgementsRelayed, m.PacketsTimedOut, m.UnrelayedPackets,
+		m.UnrelayedAcknowledgements, m.TxsSucceeded, m.TxsFailed, m.TxsPending, m.TxsResubmitted,
+		m.GasUsed, m.WalletBalance, m.LatestHeight, m.ClientExpiry, m.FeesSpent, m.FeeBudgetExceeded,
+		m.WalletBalanceLow,
+	} {
+		require.IsType(t, prometheus.AlreadyRegisteredError{}, m.Registry.Register(c))
+	}
+}
+
+func TestRelayMetricsLabels(t *testing.T) {
+	m := useMetrics(t)
+	src, dst := newMockLink(t)
+	require.Equal(t, "ibc-0:"+src.PathEnd.ChannelID+"-ibc-1:"+dst.PathEnd.ChannelID, pathLabel(src, dst))
+
+	src, err := src.WithPath("demo", src.PathEnd)
+	require.NoError(t, err)
+	dst, err = dst.WithPath("demo", dst.PathEnd)
+	require.NoError(t, err)
+	require.Equal(t, "demo", pathLabel(src, dst))
+
+	dstAddr, err := dst.ChainProvider.Address()
+	require.NoError(t, err)
+	require.NoError(t, src.SendTransferMsg(dst, sdk.NewInt64Coin(testDenom, 1000), dstAddr, 0, 0))
+	require.NoError(t, src.ChainProvider.WaitForNBlocks(1))
+
+	cs, err := NewChannelSet("demo", &Path{Src: src.PathEnd, Dst: dst.PathEnd}, src, dst)
+	require.NoError(t, err)
+	require.NoError(t, cs.RelayUnrelayed(true, false, testMaxTxSize, testMaxMsgLength))
+
+	// the packet is counted on the chain it was relayed to, under the name of the path
+	require.Equal(t, float64(1), testutil.ToFloat64(m.PacketsRelayed.WithLabelValues("demo", "ibc-1")))
+	require.Equal(t, float64(0), testutil.ToFloat64(m.PacketsRelayed.WithLabelValues("demo", "ibc-0")))
+	require.Equal(t, float64(1), testutil.ToFloat64(m.TxsSucceeded.WithLabelValues("demo", "ibc-1")))
+	require.Zero(t, testutil.CollectAndCount(m.TxsFailed))
+
+	require.NoError(t, m.UpdateChainMetrics(src))
+	h, err := src.ChainProvider.QueryLatestHeight()
+	require.NoError(t, err)
+	require.LessOrEqual(t, float64(1), testutil.ToFloat64(m.LatestHeight.WithLabelValues("ibc-0")))
+	require.GreaterOrEqual(t, float64(h), testutil.ToFloat64(m.LatestHeight.WithLabelValues("ibc-0")))
+	require.Equal(t, float64(9000), testutil.ToFloat64(m.WalletBalance.WithLabelValues("ibc-0", "testkey", testDenom)))
+}
@@ -1,78 +1,144 @@
 package relayer
 
-//var (
-//	// strings for parsing events
-//	updateCliTag = "update_client"
-//	headerTag    = "header"
-//	clientIDTag  = "client_id"
-//)
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	clienttypes "github.com/cosmos/ibc-go/v2/modules/core/02-client/types"
+	tmclient "github.com/cosmos/ibc-go/v2/modules/light-clients/07-tendermint/types"
+)
+
+var (
+	// strings for parsing events
+	updateCliTag = clienttypes.EventTypeUpdateClient
+	headerTag    = clienttypes.AttributeKeyHeader
+	clientIDTag  = clienttypes.AttributeKeyClientID
+
+	// updateClientEventQuery matches the txs updating any client of a chain. The monitors of all the paths
+	// on a chain share the subscription and pick the updates of their own client, as a node only allows a
+	// few subscriptions per client.
+	updateClientEventQuery = fmt.Sprintf("%s AND %s.%s EXISTS", txEventQuery, updateCliTag, clientIDTag)
+)
+
+// StartMisbehaviourMonitor watches the update_client events for the clients of the path on both chains
+// and submits misbehaviour to a client whenever it was updated with a header that conflicts with the
+// header of its counterparty at the same height.
+func StartMisbehaviourMonitor(src, dst *Chain) (func(), error) {
+	if err := ValidateClientPaths(src, dst); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go monitorMisbehaviour(ctx, src, dst)
+	go monitorMisbehaviour(ctx, dst, src)
+	return cancel, nil
+}
+
+// monitorMisbehaviour checks every update of the client of src until ctx is cancelled,
+// resubscribing whenever the subscription fails
+func monitorMisbehaviour(ctx context.Context, src, counterparty *Chain) {
+	for {
+		events, err := src.ChainProvider.SubscribeEvents(ctx, updateClientEventQuery)
+		if err != nil {
+			src.Log(fmt.Sprintf("misbehaviour monitor subscription error: %s, resubscribing in %s", err, eventResubscribeDelay))
+		} else {
+			for ev := range events {
+				if err := checkAndSubmitMisbehaviour(src, counterparty, ev.Events); err != nil {
+					src.Error(fmt.Errorf("failed to check client (%s) for misbehaviour: %w", src.ClientID(), err))
+				}
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(eventResubscribeDelay):
+		}
+	}
+}
 
 // checkAndSubmitMisbehaviour check headers from update_client tx events
 // against the associated light client. If the headers do not match, the emitted
 // header and a reconstructed header are used in misbehaviour submission to
-// the IBC client on the source chain.
-//func checkAndSubmitMisbehaviour(src, counterparty *Chain, events map[string][]string) error {
-//	hdrs, ok := events[fmt.Sprintf("%s.%s", updateCliTag, headerTag)]
-//	if !ok {
-//		return nil
-//	}
-//	for i, hdr := range hdrs {
-//		clientIDs := events[fmt.Sprintf("%s.%s", updateCliTag, clientIDTag)]
-//		if len(clientIDs) <= i {
-//			return fmt.Errorf("emitted client-ids count is less than emitted headers count")
-//		}
-//
-//		emittedClientID := clientIDs[i]
-//		if src.PathEnd.ClientID != emittedClientID {
-//			continue
-//		}
-//
-//		hdrBytes, err := hex.DecodeString(hdr)
-//		if err != nil {
-//			return sdkerrors.Wrapf(err, "failed decoding hexadecimal string of header with client-id: %s",
-//				emittedClientID)
-//		}
-//
-//		exportedHeader, err := clienttypes.UnmarshalHeader(src.Encoding.Marshaler, hdrBytes)
-//		if err != nil {
-//			return sdkerrors.Wrapf(err, "failed unmarshaling header with client-id: %s", emittedClientID)
-//		}
-//
-//		emittedHeader, ok := exportedHeader.(*tmclient.Header)
-//		if !ok {
-//			return fmt.Errorf("emitted header is not tendermint type")
-//		}
-//
-//		trustedHeader, err := counterparty.ChainProvider.GetLightSignedHeaderAtHeight(emittedHeader.Header.Height)
-//		if err != nil {
-//			return err
-//		}
-//
-//		if IsMatchingConsensusState(emittedHeader.ConsensusState(), trustedHeader.ConsensusState()) {
-//			continue
-//		}
-//
-//		trustedHeader.TrustedValidators = emittedHeader.TrustedValidators
-//		trustedHeader.TrustedHeight = emittedHeader.TrustedHeight
-//
-//		misbehaviour := tmclient.NewMisbehaviour(emittedClientID, emittedHeader, trustedHeader)
-//		msg, err := clienttypes.NewMsgSubmitMisbehaviour(emittedClientID, misbehaviour, src.MustGetAddress())
-//		if err != nil {
-//			return err
-//		}
-//		if err := msg.ValidateBasic(); err != nil {
-//			return err
-//		}
-//		res, success, err := src.SendMsg(msg)
-//		if err != nil {
-//			return err
-//		}
-//		if !success {
-//			return fmt.Errorf("submit misbehaviour tx failed: %s", res.RawLog)
-//		}
-//		src.Log(fmt.Sprintf("Submitted misbehaviour for emitted header with height: %d",
-//			emittedHeader.Header.Height))
-//	}
-//
-//	return nil
-//}
+// the IBC client on the source chain. Updates of other clients are skipped.
+func checkAndSubmitMisbehaviour(src, counterparty *Chain, events map[string][]string) error {
+	hdrs, ok := events[fmt.Sprintf("%s.%s", updateCliTag, headerTag)]
+	if !ok {
+		return nil
+	}
+	for i, hdr := range hdrs {
+		clientIDs := events[fmt.Sprintf("%s.%s", updateCliTag, clientIDTag)]
+		if len(clientIDs) <= i {
+			return fmt.Errorf("emitted client-ids count is less than emitted headers count")
+		}
+
+		emittedClientID := clientIDs[i]
+		if src.PathEnd.ClientID != emittedClientID {
+			continue
+		}
+
+		hdrBytes, err := hex.DecodeString(hdr)
+		if err != nil {
+			return sdkerrors.Wrapf(err, "failed decoding hexadecimal string of header with client-id: %s",
+				emittedClientID)
+		}
+
+		exportedHeader, err := clienttypes.UnmarshalHeader(src.Encoding.Marshaler, hdrBytes)
+		if err != nil {
+			return sdkerrors.Wrapf(err, "failed unmarshaling header with client-id: %s", emittedClientID)
+		}
+
+		emittedHeader, ok := exportedHeader.(*tmclient.Header)
+		if !ok {
+			return fmt.Errorf("emitted header is not tendermint type")
+		}
+
+		h, err := counterparty.ChainProvider.GetLightSignedHeaderAtHeight(emittedHeader.Header.Height)
+		if err != nil {
+			return err
+		}
+
+		trustedHeader, ok := h.(*tmclient.Header)
+		if !ok {
+			return fmt.Errorf("trusted header is not tendermint type")
+		}
+
+		if isMatchingConsensusState(emittedHeader.ConsensusState(), trustedHeader.ConsensusState()) {
+			continue
+		}
+
+		trustedHeader.TrustedValidators = emittedHeader.TrustedValidators
+		trustedHeader.TrustedHeight = emittedHeader.TrustedHeight
+
+		misbehaviour := tmclient.NewMisbehaviour(emittedClientID, emittedHeader, trustedHeader)
+		msg, err := src.ChainProvider.SubmitMisbehavior(emittedClientID, misbehaviour)
+		if err != nil {
+			return err
+		}
+		res, success, err := src.ChainProvider.SendMessage(msg)
+		if err != nil {
+			return err
+		}
+		if !success {
+			if res == nil {
+				return fmt.Errorf("submit misbehaviour tx failed")
+			}
+			return fmt.Errorf("submit misbehaviour tx failed: %d:%s", res.Code, res.Data)
+		}
+		src.Log(fmt.Sprintf("★ Submitted misbehaviour for emitted header with height: %d",
+			emittedHeader.Header.Height))
+	}
+
+	return nil
+}
+
+// isMatchingConsensusState returns true if both consensus states commit to the same block
+func isMatchingConsensusState(a, b *tmclient.ConsensusState) bool {
+	return a.Timestamp.Equal(b.Timestamp) &&
+		bytes.Equal(a.Root.Hash, b.Root.Hash) &&
+		bytes.Equal(a.NextValidatorsHash, b.NextValidatorsHash)
+}
@@ -0,0 +1,56 @@
+package relayer
+
+import (
+	"encoding/hex"
+	"fmt"
+	"testing"
+
+	clienttypes "github.com/cosmos/ibc-go/v2/modules/core/02-client/types"
+	tmclient "github.com/cosmos/ibc-go/v2/modules/light-clients/07-tendermint/types"
+	"github.com/stretchr/testify/require"
+)
+
+// updateClientEvents returns the events of a tx updating the client with the given id with header
+func updateClientEvents(t *testing.T, c *Chain, clientID string, header *tmclient.Header) map[string][]string {
+	t.Helper()
+	bz, err := clienttypes.MarshalHeader(c.Encoding.Marshaler, header)
+	require.NoError(t, err)
+	return map[string][]string{
+		fmt.Sprintf("%s.%s", updateCliTag, headerTag):   {hex.EncodeToString(bz)},
+		fmt.Sprintf("%s.%s", updateCliTag, clientIDTag): {clientID},
+	}
+}
+
+func TestCheckAndSubmitMisbehaviour(t *testing.T) {
+	src, dst := newMockPath(t)
+	require.NoError(t, dst.ChainProvider.WaitForNBlocks(1))
+	dsth, err := dst.ChainProvider.QueryLatestHeight()
+	require.NoError(t, err)
+	h, err := dst.ChainProvider.GetLightSignedHeaderAtHeight(dsth)
+	require.NoError(t, err)
+	header := h.(*tmclient.Header)
+
+	frozen := func() bool {
+		require.NoError(t, src.ChainProvider.WaitForNBlocks(1))
+		cs, err := src.ChainProvider.QueryClientState(0, src.ClientID())
+		require.NoError(t, err)
+		return !cs.(*tmclient.ClientState).FrozenHeight.IsZero()
+	}
+
+	// the header dst really produced is no misbehaviour, nor is a header for another client
+	require.NoError(t, checkAndSubmitMisbehaviour(src, dst, updateClientEvents(t, src, src.ClientID(), header)))
+	require.False(t, frozen())
+
+	conflicting := *header
+	signed := *header.SignedHeader
+	tmHeader := *header.Header
+	tmHeader.AppHash = []byte("conflicting app hash of 32 bytes")
+	signed.Header = &tmHeader
+	conflicting.SignedHeader = &signed
+	require.NoError(t, checkAndSubmitMisbehaviour(src, dst, updateClientEvents(t, src, "07-tendermint-99", &conflicting)))
+	require.False(t, frozen())
+
+	// a header that conflicts with the one of dst at the same height gets the client frozen
+	require.NoError(t, checkAndSubmitMisbehaviour(src, dst, updateClientEvents(t, src, src.ClientID(), &conflicting)))
+	require.True(t, frozen())
+}
@@ -107,6 +107,10 @@ func UnrelayedSequences(src, dst *Chain) (*RelaySequences, error) {
 		return nil, err
 	}
 
+	path := pathLabel(src, dst)
+	Metrics.UnrelayedPackets.WithLabelValues(path, src.ChainID()).Set(float64(len(rs.Src)))
+	Metrics.UnrelayedPackets.WithLabelValues(path, dst.ChainID()).Set(float64(len(rs.Dst)))
+
 	return rs, nil
 }
 
@@ -207,6 +211,10 @@ func UnrelayedAcknowledgements(src, dst *Chain) (*RelaySequences, error) {
 		return nil, err
 	}
 
+	path := pathLabel(src, dst)
+	Metrics.UnrelayedAcknowledgements.WithLabelValues(path, src.ChainID()).Set(float64(len(rs.Src)))
+	Metrics.UnrelayedAcknowledgements.WithLabelValues(path, dst.ChainID()).Set(float64(len(rs.Dst)))
+
 	return rs, nil
 }
 
@@ -225,156 +233,25 @@ func (rs *RelaySequences) Empty() bool {
 
 // RelayAcknowledgements creates transactions to relay acknowledgements from src to dst and from dst to src
 func RelayAcknowledgements(src, dst *Chain, sp *RelaySequences, maxTxSize, maxMsgLength uint64) error {
-	// set the maximum relay transaction constraints
-	msgs := &RelayMsgs{
-		Src:          []provider.RelayerMessage{},
-		Dst:          []provider.RelayerMessage{},
-		MaxTxSize:    maxTxSize,
-		MaxMsgLength: maxMsgLength,
-	}
-
-	srch, dsth, err := QueryLatestHeights(src, dst)
-	if err != nil {
-		return err
-	}
-
-	var (
-		eg                   errgroup.Group
-		srcHeader, dstHeader ibcexported.Header
-	)
-	eg.Go(func() error {
-		var err error
-		srcHeader, err = src.ChainProvider.GetIBCUpdateHeader(srch, dst.ChainProvider, dst.PathEnd.ClientID)
-		return err
-	})
-	eg.Go(func() error {
-		var err error
-		dstHeader, err = dst.ChainProvider.GetIBCUpdateHeader(dsth, src.ChainProvider, src.PathEnd.ClientID)
-		return err
-	})
-	if err := eg.Wait(); err != nil {
-		return err
-	}
-
-	srcUpdateMsg, err := src.ChainProvider.UpdateClient(src.PathEnd.ClientID, dstHeader)
-	if err != nil {
-		return err
-	}
-	dstUpdateMsg, err := dst.ChainProvider.UpdateClient(dst.PathEnd.ClientID, srcHeader)
-	if err != nil {
+	ch := &ChannelSequences{Src: src, Dst: dst, Acks: sp}
+	if err := RelayChannels([]*ChannelSequences{ch}, maxTxSize, maxMsgLength); err != nil {
 		return err
 	}
-
-	// add messages for received packets on dst
-	for _, seq := range sp.Dst {
-		// dst wrote the ack. acknowledgementFromSequence will query the acknowledgement
-		// from the counterparty chain (second chain provided in the arguments). The message
-		// should be sent to src.
-		relayAckMsgs, err := src.ChainProvider.AcknowledgementFromSequence(dst.ChainProvider, uint64(dsth), seq, dst.PathEnd.ChannelID, dst.PathEnd.PortID, src.PathEnd.ChannelID, src.PathEnd.PortID)
-		if err != nil {
-			return err
-		}
-
-		msgs.Src = append(msgs.Src, relayAckMsgs)
-	}
-
-	// add messages for received packets on src
-	for _, seq := range sp.Src {
-		// src wrote the ack. acknowledgementFromSequence will query the acknowledgement
-		// from the counterparty chain (second chain provided in the arguments). The message
-		// should be sent to dst.
-		relayAckMsgs, err := dst.ChainProvider.AcknowledgementFromSequence(src.ChainProvider, uint64(srch), seq, src.PathEnd.ChannelID, src.PathEnd.PortID, dst.PathEnd.ChannelID, dst.PathEnd.PortID)
-		if err != nil {
-			return err
-		}
-
-		msgs.Dst = append(msgs.Dst, relayAckMsgs)
+	if !ch.FailedAcks.Empty() {
+		return &RelayIncompleteError{Sequences: ch.FailedAcks}
 	}
-
-	if !msgs.Ready() {
-		src.Log(fmt.Sprintf("- No acknowledgements to relay between [%s]port{%s} and [%s]port{%s}",
-			src.ChainID(), src.PathEnd.PortID, dst.ChainID(), dst.PathEnd.PortID))
-		return nil
-	}
-
-	// Prepend non-empty msg lists with UpdateClient
-	if len(msgs.Dst) != 0 {
-		msgs.Dst = append([]provider.RelayerMessage{dstUpdateMsg}, msgs.Dst...)
-	}
-
-	if len(msgs.Src) != 0 {
-		msgs.Src = append([]provider.RelayerMessage{srcUpdateMsg}, msgs.Src...)
-	}
-
-	// send messages to their respective chains
-	if msgs.Send(src, dst); msgs.Success() {
-		if len(msgs.Dst) > 1 {
-			dst.logPacketsRelayed(src, len(msgs.Dst)-1)
-		}
-		if len(msgs.Src) > 1 {
-			src.logPacketsRelayed(dst, len(msgs.Src)-1)
-		}
-	}
-
 	return nil
 }
 
 // RelayPackets creates transactions to relay packets from src to dst and from dst to src
 func RelayPackets(src, dst *Chain, sp *RelaySequences, maxTxSize, maxMsgLength uint64) error {
-	// set the maximum relay transaction constraints
-	msgs := &RelayMsgs{
-		Src:          []provider.RelayerMessage{},
-		Dst:          []provider.RelayerMessage{},
-		MaxTxSize:    maxTxSize,
-		MaxMsgLength: maxMsgLength,
-	}
-
-	srch, dsth, err := QueryLatestHeights(src, dst)
-	if err != nil {
-		return err
-	}
-
-	// add messages for sequences on src
-	if err = AddMessagesForSequences(sp.Src, src, dst, srch, dsth, &msgs.Src, &msgs.Dst); err != nil {
+	ch := &ChannelSequences{Src: src, Dst: dst, Packets: sp}
+	if err := RelayChannels([]*ChannelSequences{ch}, maxTxSize, maxMsgLength); err != nil {
 		return err
 	}
-
-	// add messages for sequences on dst
-	if err = AddMessagesForSequences(sp.Dst, dst, src, dsth, srch, &msgs.Dst, &msgs.Src); err != nil {
-		return err
+	if !ch.FailedPackets.Empty() {
+		return &RelayIncompleteError{Sequences: ch.FailedPackets}
 	}
-
-	if !msgs.Ready() {
-		src.Log(fmt.Sprintf("- No packets to relay between [%s]port{%s} and [%s]port{%s}",
-			src.ChainID(), src.PathEnd.PortID, dst.ChainID(), dst.PathEnd.PortID))
-		return nil
-	}
-
-	// Prepend non-empty msg lists with UpdateClient
-	eg := new(errgroup.Group)
-
-	eg.Go(func() error {
-		return PrependUpdateClientMsg(&msgs.Dst, src, dst, srch)
-	})
-
-	eg.Go(func() error {
-		return PrependUpdateClientMsg(&msgs.Src, dst, src, dsth)
-	})
-
-	if err = eg.Wait(); err != nil {
-		return err
-	}
-
-	// send messages to their respective chains
-	if msgs.Send(src, dst); msgs.Success() {
-		if len(msgs.Dst) > 1 {
-			dst.logPacketsRelayed(src, len(msgs.Dst)-1)
-		}
-		if len(msgs.Src) > 1 {
-			src.logPacketsRelayed(dst, len(msgs.Src)-1)
-		}
-	}
-
 	return nil
 }
 
@@ -454,6 +331,12 @@ func RelayPacket(src, dst *Chain, sp *RelaySequences, maxTxSize, maxMsgLength, s
 		MaxMsgLength: maxMsgLength,
 	}
 
+	// the packet is only relayed if the path's filter allows it
+	sp, err := FilterPackets(src, dst, &RelaySequences{Src: withSequence(sp.Src, seqNum), Dst: withSequence(sp.Dst, seqNum)})
+	if err != nil {
+		return err
+	}
+
 	srch, dsth, err := QueryLatestHeights(src, dst)
 	if err != nil {
 		return err
@@ -552,15 +435,34 @@ func RelayPacket(src, dst *Chain, sp *RelaySequences, maxTxSize, maxMsgLength, s
 
 	// send messages to their respective chains
 	if msgs.Send(src, dst); msgs.Success() {
+		path := pathLabel(src, dst)
+		state.setPathHeights(src, dst, srch, dsth)
 		if len(msgs.Dst) > 1 {
 			dst.logPacketsRelayed(src, len(msgs.Dst)-1)
+			Metrics.PacketsRelayed.WithLabelValues(path, dst.ChainID()).Add(float64(len(msgs.Dst) - 1))
 		}
 		if len(msgs.Src) > 1 {
 			src.logPacketsRelayed(dst, len(msgs.Src)-1)
+			Metrics.PacketsRelayed.WithLabelValues(path, src.ChainID()).Add(float64(len(msgs.Src) - 1))
 		}
+		logTimeouts(path, src, msgs.Src)
+		logTimeouts(path, dst, msgs.Dst)
 	} else {
 		fmt.Println()
 	}
 
+	if failed := msgs.failedSequences(src, dst, false); !failed.Empty() {
+		return &RelayIncompleteError{Sequences: failed}
+	}
 	return nil
 }
+
+// withSequence returns seq in a list if it is one of seqs, or an empty list otherwise
+func withSequence(seqs []uint64, seq uint64) []uint64 {
+	for _, s := range seqs {
+		if s == seq {
+			return []uint64{seq}
+		}
+	}
+	return []uint64{}
+}
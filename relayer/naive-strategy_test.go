@@ -0,0 +1,147 @@
+package relayer
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	transfertypes "github.com/cosmos/ibc-go/v2/modules/apps/transfer/types"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testMaxTxSize    = 2 * 1024 * 1024
+	testMaxMsgLength = 5
+)
+
+// voucherDenom returns the denom of the vouchers minted on c for denom tokens sent over its path
+func voucherDenom(c *Chain, denom string) string {
+	return transfertypes.ParseDenomTrace(fmt.Sprintf("%s/%s/%s", c.PathEnd.PortID, c.PathEnd.ChannelID, denom)).IBCDenom()
+}
+
+// requireBalance waits for the key of c to hold amount of denom
+func requireBalance(t *testing.T, c *Chain, denom string, amount int64) {
+	t.Helper()
+	addr, err := c.ChainProvider.Address()
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		coins, err := c.ChainProvider.QueryBalanceWithAddress(addr)
+		return err == nil && coins.AmountOf(denom).Equal(sdk.NewInt(amount))
+	}, 10*time.Second, testBlockTime, "%s balance of %s", c.ChainID(), denom)
+}
+
+// requireRelayed waits for every packet and acknowledgement on the path between src and dst to be relayed
+func requireRelayed(t *testing.T, src, dst *Chain) {
+	t.Helper()
+	require.Eventually(t, func() bool {
+		sp, err := UnrelayedSequences(src, dst)
+		if err != nil || !sp.Empty() {
+			return false
+		}
+		ap, err := UnrelayedAcknowledgements(src, dst)
+		return err == nil && ap.Empty()
+	}, 10*time.Second, testBlockTime)
+}
+
+func TestStartRelayer(t *testing.T) {
+	src, dst := newMockLink(t)
+
+	stop, err := StartRelayer(src, dst, testMaxTxSize, testMaxMsgLength)
+	require.NoError(t, err)
+	defer stop()
+
+	dstAddr, err := dst.ChainProvider.Address()
+	require.NoError(t, err)
+	require.NoError(t, src.SendTransferMsg(dst, sdk.NewInt64Coin(testDenom, 1000), dstAddr, 0, 0))
+
+	requireBalance(t, src, testDenom, 9000)
+	requireBalance(t, dst, voucherDenom(dst, testDenom), 1000)
+	requireRelayed(t, src, dst)
+
+	// sending the vouchers back burns them and unescrows the tokens on src
+	srcAddr, err := src.ChainProvider.Address()
+	require.NoError(t, err)
+	require.NoError(t, dst.SendTransferMsg(src, sdk.NewInt64Coin(voucherDenom(dst, testDenom), 400), srcAddr, 0, 0))
+
+	requireBalance(t, dst, voucherDenom(dst, testDenom), 600)
+	requireBalance(t, src, testDenom, 9400)
+	requireRelayed(t, src, dst)
+}
+
+func TestRelayPacketsTimeout(t *testing.T) {
+	src, dst := newMockLink(t)
+
+	dstAddr, err := dst.ChainProvider.Address()
+	require.NoError(t, err)
+	require.NoError(t, src.SendTransferMsg(dst, sdk.NewInt64Coin(testDenom, 1000), dstAddr, 2, 0))
+	requireBalance(t, src, testDenom, 9000)
+
+	// let the packet time out on dst before relaying it
+	require.NoError(t, dst.ChainProvider.WaitForNBlocks(4))
+
+	sp, err := UnrelayedSequences(src, dst)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{1}, sp.Src)
+	require.Empty(t, sp.Dst)
+	require.NoError(t, RelayPackets(src, dst, sp, testMaxTxSize, testMaxMsgLength))
+
+	// the timeout refunds the tokens on src and nothing is received on dst
+	requireBalance(t, src, testDenom, 10000)
+	requireBalance(t, dst, voucherDenom(dst, testDenom), 0)
+
+	commitments, err := src.ChainProvider.QueryPacketCommitments(0, src.PathEnd.ChannelID, src.PathEnd.PortID)
+	require.NoError(t, err)
+	require.Empty(t, commitments.Commitments)
+}
+
+func TestStartSeveralPaths(t *testing.T) {
+	src, dst := newMockLink(t)
+
+	// a second path between the chains relays a channel of its own over the same connection
+	srcEnd, dstEnd := *src.PathEnd, *dst.PathEnd
+	srcEnd.ChannelID, dstEnd.ChannelID = "", ""
+	srcEnd.Version, dstEnd.Version = "ics20-2", "ics20-2"
+	srcB, err := src.WithPath("path-b", &srcEnd)
+	require.NoError(t, err)
+	dstB, err := dst.WithPath("path-b", &dstEnd)
+	require.NoError(t, err)
+	_, err = srcB.CreateOpenChannels(dstB, 3, testStepTimeout)
+	require.NoError(t, err)
+	srcA, err := src.WithPath("path-a", src.PathEnd)
+	require.NoError(t, err)
+	dstA, err := dst.WithPath("path-a", dst.PathEnd)
+	require.NoError(t, err)
+
+	stops := make(map[string]func())
+	for _, p := range [][2]*Chain{{srcA, dstA}, {srcB, dstB}} {
+		name := p[0].PathName()
+		cs, err := NewChannelSet(name, &Path{Src: p[0].PathEnd, Dst: p[1].PathEnd}, p[0], p[1])
+		require.NoError(t, err)
+		stop, err := StartChannelSetRelayer(cs, testMaxTxSize, testMaxMsgLength)
+		require.NoError(t, err)
+		stops[name] = stop
+	}
+	defer stops["path-b"]()
+
+	dstAddr, err := dst.ChainProvider.Address()
+	require.NoError(t, err)
+	require.NoError(t, srcA.SendTransferMsg(dstA, sdk.NewInt64Coin(testDenom, 1000), dstAddr, 0, 0))
+	require.NoError(t, srcB.SendTransferMsg(dstB, sdk.NewInt64Coin(testDenom, 500), dstAddr, 0, 0))
+	requireBalance(t, dst, voucherDenom(dstA, testDenom), 1000)
+	requireBalance(t, dst, voucherDenom(dstB, testDenom), 500)
+	requireRelayed(t, srcA, dstA)
+	requireRelayed(t, srcB, dstB)
+
+	// stopping one path leaves the other relaying
+	stops["path-a"]()
+	require.NoError(t, srcA.SendTransferMsg(dstA, sdk.NewInt64Coin(testDenom, 100), dstAddr, 0, 0))
+	require.NoError(t, srcB.SendTransferMsg(dstB, sdk.NewInt64Coin(testDenom, 100), dstAddr, 0, 0))
+	requireBalance(t, dst, voucherDenom(dstB, testDenom), 600)
+	requireRelayed(t, srcB, dstB)
+	time.Sleep(5 * testBlockTime)
+	requireBalance(t, dst, voucherDenom(dstA, testDenom), 1000)
+	sp, err := UnrelayedSequences(srcA, dstA)
+	require.NoError(t, err)
+	require.Len(t, sp.Src, 1)
+}
@@ -0,0 +1,305 @@
+package relayer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/tendermint/tendermint/libs/log"
+)
+
+// Events that notifications are sent for
+const (
+	EventClientExpiry  = "client-expiry"
+	EventRelayFailures = "relay-failures"
+	EventLowBalance    = "low-balance"
+	EventPathStatus    = "path-status"
+)
+
+const (
+	// defaultNotifyRateLimit is how long notifications about the same subject are held back when none is configured
+	defaultNotifyRateLimit = 10 * time.Minute
+
+	// defaultFailureThreshold is how many relay txs in a row must fail before a notification is sent
+	defaultFailureThreshold = 5
+
+	notifyTimeout = 10 * time.Second
+)
+
+// NotifierConfig configures where notifications about relayer incidents are sent
+type NotifierConfig struct {
+	Targets []*NotifyTarget `yaml:"targets" json:"targets"`
+
+	// RateLimit is the least time between two notifications about the same subject, 10m if unset.
+	// Notifications held back in between are counted in the next one that is sent.
+	RateLimit string `yaml:"rate-limit,omitempty" json:"rate-limit,omitempty"`
+
+	// FailureThreshold is how many relay txs to a chain must fail in a row for a notification, 5 if unset
+	FailureThreshold int `yaml:"failure-threshold,omitempty" json:"failure-threshold,omitempty"`
+}
+
+// NotifyTarget is an HTTP endpoint that notifications are posted to. Template is a text/template
+// executed with the Notification as the request body, which is the notification as JSON if empty.
+// Fields are not escaped by the template, so a JSON body quotes them with the json function, e.g.
+// {"text": {{json .Message}}}.
+type NotifyTarget struct {
+	Name     string            `yaml:"name" json:"name"`
+	URL      string            `yaml:"url" json:"url"`
+	Events   []string          `yaml:"events,omitempty" json:"events,omitempty"`
+	Template string            `yaml:"template,omitempty" json:"template,omitempty"`
+	Headers  map[string]string `yaml:"headers,omitempty" json:"headers,omitempty"`
+
+	// ChainIDs limits the target to notifications about the listed chains, all chains if empty
+	ChainIDs []string `yaml:"chain-ids,omitempty" json:"chain-ids,omitempty"`
+}
+
+// Notification describes a relayer incident
+type Notification struct {
+	Event      string    `json:"event"`
+	ChainID    string    `json:"chain-id,omitempty"`
+	Path       string    `json:"path,omitempty"`
+	Subject    string    `json:"subject"`
+	Message    string    `json:"message"`
+	Time       time.Time `json:"time"`
+	Suppressed int       `json:"suppressed,omitempty"`
+}
+
+// Validate returns an error if a target has no url, listens to an unknown event or has a template
+// that can not be parsed
+func (nc *NotifierConfig) Validate() error {
+	if _, err := nc.rateLimit(); err != nil {
+		return err
+	}
+	if nc.FailureThreshold < 0 {
+		return fmt.Errorf("invalid failure-threshold %d", nc.FailureThreshold)
+	}
+	for _, t := range nc.Targets {
+		if t.URL == "" {
+			return fmt.Errorf("notify target %s has no url", t.Name)
+		}
+		for _, ev := range t.Events {
+			switch ev {
+			case EventClientExpiry, EventRelayFailures, EventLowBalance, EventPathStatus:
+			default:
+				return fmt.Errorf("notify target %s listens to unknown event %s", t.Name, ev)
+			}
+		}
+		if _, err := t.template(); err != nil {
+			return fmt.Errorf("invalid template of notify target %s: %w", t.Name, err)
+		}
+	}
+	return nil
+}
+
+func (nc *NotifierConfig) rateLimit() (time.Duration, error) {
+	if nc.RateLimit == "" {
+		return defaultNotifyRateLimit, nil
+	}
+	d, err := time.ParseDuration(nc.RateLimit)
+	if err != nil {
+		return 0, fmt.Errorf("invalid notify rate-limit %s: %w", nc.RateLimit, err)
+	}
+	return d, nil
+}
+
+func (t *NotifyTarget) template() (*template.Template, error) {
+	if t.Template == "" {
+		return nil, nil
+	}
+	return template.New(t.Name).Funcs(templateFuncs).Parse(t.Template)
+}
+
+// templateFuncs are the functions available to the templates of notify targets
+var templateFuncs = template.FuncMap{
+	// json renders a value as JSON, quoting and escaping strings
+	"json": func(v interface{}) (string, error) {
+		bz, err := json.Marshal(v)
+		return string(bz), err
+	},
+}
+
+// listens returns true if the target is sent note
+func (t *NotifyTarget) listens(note *Notification) bool {
+	return listed(t.Events, note.Event, true) && listed(t.ChainIDs, note.ChainID, true)
+}
+
+// notifier is the notifier used by the relayer, it stays nil unless SetNotifier is called
+var notifier *Notifier
+
+// SetNotifier sets the notifier that relayer incidents are reported to
+func SetNotifier(n *Notifier) {
+	notifier = n
+}
+
+// Notify sends a notification through the notifier set with SetNotifier, if any
+func Notify(note *Notification) {
+	notifier.Notify(note)
+}
+
+// WaitNotifications blocks until the notifications being sent have been posted, so a command can
+// report an incident right before it exits
+func WaitNotifications() {
+	if notifier != nil {
+		notifier.sending.Wait()
+	}
+}
+
+// Notifier posts notifications to the configured targets, holding back repeated notifications about
+// the same subject for the rate limit
+type Notifier struct {
+	targets          []*notifyTarget
+	rateLimit        time.Duration
+	failureThreshold int
+	client           *http.Client
+	logger           log.Logger
+	sending          sync.WaitGroup
+
+	mu         sync.Mutex
+	last       map[string]time.Time
+	suppressed map[string]int
+	failures   map[string]int
+}
+
+type notifyTarget struct {
+	*NotifyTarget
+	tmpl *template.Template
+}
+
+// NewNotifier returns a notifier for the given config that logs the notifications it fails to send
+// to logger, or to stdout if logger is nil
+func NewNotifier(nc *NotifierConfig, logger log.Logger) (*Notifier, error) {
+	if err := nc.Validate(); err != nil {
+		return nil, err
+	}
+	rateLimit, _ := nc.rateLimit()
+	n := &Notifier{
+		rateLimit:        rateLimit,
+		failureThreshold: nc.FailureThreshold,
+		client:           &http.Client{Timeout: notifyTimeout},
+		logger:           logger,
+		last:             make(map[string]time.Time),
+		suppressed:       make(map[string]int),
+		failures:         make(map[string]int),
+	}
+	if n.failureThreshold == 0 {
+		n.failureThreshold = defaultFailureThreshold
+	}
+	if n.logger == nil {
+		n.logger = defaultChainLogger()
+	}
+	for _, t := range nc.Targets {
+		tmpl, _ := t.template()
+		n.targets = append(n.targets, &notifyTarget{NotifyTarget: t, tmpl: tmpl})
+	}
+	return n, nil
+}
+
+// Notify sends a notification to every target listening to its event in the background, unless a
+// notification about the same subject was sent within the rate limit
+func (n *Notifier) Notify(note *Notification) {
+	if n == nil {
+		return
+	}
+
+	key := note.Event + "/" + note.Subject
+	n.mu.Lock()
+	if time.Since(n.last[key]) < n.rateLimit {
+		n.suppressed[key]++
+		n.mu.Unlock()
+		return
+	}
+	n.last[key] = time.Now()
+	note.Suppressed = n.suppressed[key]
+	delete(n.suppressed, key)
+	n.mu.Unlock()
+
+	if note.Time.IsZero() {
+		note.Time = time.Now()
+	}
+	for _, t := range n.targets {
+		if !t.listens(note) {
+			continue
+		}
+		n.sending.Add(1)
+		go func(t *notifyTarget) {
+			defer n.sending.Done()
+			if err := n.post(t, note); err != nil {
+				n.logger.Error("failed to send notification", "event", note.Event, "target", t.Name, "err", err)
+			}
+		}(t)
+	}
+}
+
+// post renders note for t and posts it to the url of t
+func (n *Notifier) post(t *notifyTarget, note *Notification) error {
+	var body bytes.Buffer
+	if t.tmpl != nil {
+		if err := t.tmpl.Execute(&body, note); err != nil {
+			return err
+		}
+	} else if err := json.NewEncoder(&body).Encode(note); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, t.URL, &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range t.Headers {
+		req.Header.Set(k, v)
+	}
+
+	res, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode >= 300 {
+		return fmt.Errorf("%s returned %s", t.URL, res.Status)
+	}
+	return nil
+}
+
+// recordTxOutcome counts the relay txs on a path to c that failed in a row and sends a notification
+// once the failure threshold is reached
+func (n *Notifier) recordTxOutcome(path string, c *Chain, success bool) {
+	if n == nil {
+		return
+	}
+
+	key := path + "/" + c.ChainID()
+	n.mu.Lock()
+	if success {
+		delete(n.failures, key)
+		n.mu.Unlock()
+		return
+	}
+	n.failures[key]++
+	failures := n.failures[key]
+	n.mu.Unlock()
+
+	if failures >= n.failureThreshold {
+		n.Notify(&Notification{
+			Event:   EventRelayFailures,
+			ChainID: c.ChainID(),
+			Path:    path,
+			Subject: key,
+			Message: fmt.Sprintf("%d relay txs in a row failed on chain %s for path %s", failures, c.ChainID(), path),
+		})
+	}
+}
+
+// notifyPathStatus reports a change in the status of a path
+func notifyPathStatus(path, message string) {
+	notifier.Notify(&Notification{
+		Event:   EventPathStatus,
+		Path:    path,
+		Subject: path + "/" + message,
+		Message: fmt.Sprintf("path %s %s", path, message),
+	})
+}
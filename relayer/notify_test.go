@@ -0,0 +1,112 @@
+package relayer
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// notifyServer records the bodies of the notifications posted to it
+type notifyServer struct {
+	*httptest.Server
+	mu     sync.Mutex
+	bodies [][]byte
+}
+
+func newNotifyServer(t *testing.T) *notifyServer {
+	s := &notifyServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		s.mu.Lock()
+		s.bodies = append(s.bodies, body)
+		s.mu.Unlock()
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// notifications returns the notifications posted so far, once n has sent them
+func (s *notifyServer) notifications(t *testing.T, n *Notifier) []Notification {
+	n.sending.Wait()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	notes := make([]Notification, len(s.bodies))
+	for i, body := range s.bodies {
+		require.NoError(t, json.Unmarshal(body, &notes[i]), string(body))
+	}
+	return notes
+}
+
+func TestNotifierTemplateEscapesJSON(t *testing.T) {
+	s := newNotifyServer(t)
+	n, err := NewNotifier(&NotifierConfig{Targets: []*NotifyTarget{{
+		Name:     "chat",
+		URL:      s.URL,
+		Template: `{"subject": {{json .Subject}}, "message": {{json .Message}}}`,
+	}}}, nil)
+	require.NoError(t, err)
+
+	n.Notify(&Notification{Event: EventPathStatus, Subject: "demo", Message: "path \"demo\" was paused\nfee budget exceeded"})
+	notes := s.notifications(t, n)
+	require.Len(t, notes, 1)
+	require.Equal(t, "path \"demo\" was paused\nfee budget exceeded", notes[0].Message)
+}
+
+func TestNotifierRateLimit(t *testing.T) {
+	s := newNotifyServer(t)
+	n, err := NewNotifier(&NotifierConfig{RateLimit: "1h", Targets: []*NotifyTarget{{Name: "hook", URL: s.URL}}}, nil)
+	require.NoError(t, err)
+
+	note := func(subject string) *Notification {
+		return &Notification{Event: EventLowBalance, Subject: subject, Message: subject + " is low"}
+	}
+	n.Notify(note("ibc-0/key"))
+	n.Notify(note("ibc-0/key"))
+	n.Notify(note("ibc-0/key"))
+	n.Notify(note("ibc-1/key"))
+	notes := s.notifications(t, n)
+	require.Len(t, notes, 2, "repeated notifications about a subject are held back")
+	require.Zero(t, notes[0].Suppressed)
+
+	// once the rate limit has passed the next notification counts the ones held back
+	n.mu.Lock()
+	n.last[EventLowBalance+"/ibc-0/key"] = time.Now().Add(-2 * time.Hour)
+	n.mu.Unlock()
+	n.Notify(note("ibc-0/key"))
+	notes = s.notifications(t, n)
+	require.Len(t, notes, 3)
+	require.Equal(t, "ibc-0/key", notes[2].Subject)
+	require.Equal(t, 2, notes[2].Suppressed)
+}
+
+func TestNotifierRecordTxOutcome(t *testing.T) {
+	s := newNotifyServer(t)
+	n, err := NewNotifier(&NotifierConfig{FailureThreshold: 2, Targets: []*NotifyTarget{{
+		Name:   "failures",
+		URL:    s.URL,
+		Events: []string{EventRelayFailures},
+	}}}, nil)
+	require.NoError(t, err)
+	c := newMockChain(t, "ibc-0")
+
+	// a success in between resets the count of failures in a row
+	n.recordTxOutcome("demo", c, false)
+	n.recordTxOutcome("demo", c, true)
+	n.recordTxOutcome("demo", c, false)
+	require.Empty(t, s.notifications(t, n))
+
+	n.recordTxOutcome("demo", c, false)
+	notes := s.notifications(t, n)
+	require.Len(t, notes, 1)
+	require.Equal(t, EventRelayFailures, notes[0].Event)
+	require.Equal(t, "demo", notes[0].Path)
+	require.Equal(t, "ibc-0", notes[0].ChainID)
+	require.Contains(t, notes[0].Message, "2 relay txs in a row failed")
+}
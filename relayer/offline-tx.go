@@ -0,0 +1,109 @@
+package relayer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sync"
+
+	"github.com/cosmos/relayer/relayer/provider"
+)
+
+// OfflineTxs are txs generated for signing out of band, in the order they are to be broadcast
+type OfflineTxs struct {
+	Txs []*provider.OfflineTx `json:"txs"`
+
+	mu sync.Mutex
+}
+
+// EnableGenerateOnly puts c in generate-only mode: the txs it would send are generated unsigned and
+// added to txs instead, and fail with provider.ErrDryRun
+func (c *Chain) EnableGenerateOnly(txs *OfflineTxs) {
+	c.EnableDryRun()
+	c.ChainProvider.(*dryRunProvider).txs = txs
+}
+
+// generate adds an unsigned tx of msgs to the generated txs
+func (p *dryRunProvider) generate(msgs []provider.RelayerMessage) error {
+	tx, err := p.ChainProvider.GenerateTx(msgs)
+	if err != nil {
+		return fmt.Errorf("failed to generate tx: %w", err)
+	}
+	p.txs.add(tx)
+	p.chain.Log(fmt.Sprintf("- [%s] generated unsigned tx of %d msgs for %s: %s",
+		p.ChainId(), len(msgs), tx.Signer, getMsgTypes(msgs)))
+	return provider.ErrDryRun
+}
+
+// add appends tx, signed after the earlier txs of its signer. The sequence of a generated tx is the
+// committed one, which the earlier txs will have used up by the time it is broadcast.
+func (o *OfflineTxs) add(tx *provider.OfflineTx) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for _, prev := range o.Txs {
+		if prev.ChainID == tx.ChainID && prev.Signer == tx.Signer {
+			tx.Sequence++
+		}
+	}
+	o.Txs = append(o.Txs, tx)
+}
+
+// ReadOfflineTxs reads the txs written to file by WriteFile
+func ReadOfflineTxs(file string) (*OfflineTxs, error) {
+	bz, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	txs := &OfflineTxs{}
+	if err = json.Unmarshal(bz, txs); err != nil {
+		return nil, fmt.Errorf("failed to read txs from %s: %w", file, err)
+	}
+	return txs, nil
+}
+
+// WriteFile writes the txs to file as JSON
+func (o *OfflineTxs) WriteFile(file string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	bz, err := json.MarshalIndent(o, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(file, bz, 0600)
+}
+
+// Sign signs each tx with the key of its chain in chains. Txs of chains missing from chains are left
+// unsigned, so the txs of a path can be signed on separate hosts, and the number of txs signed is returned.
+func (o *OfflineTxs) Sign(chains Chains) (int, error) {
+	signed := 0
+	for i, tx := range o.Txs {
+		c, err := chains.Get(tx.ChainID)
+		if err != nil {
+			continue
+		}
+		if err = c.ChainProvider.SignTx(tx); err != nil {
+			return signed, fmt.Errorf("failed to sign tx %d for %s on %s: %w", i, tx.Signer, tx.ChainID, err)
+		}
+		signed++
+	}
+	return signed, nil
+}
+
+// Broadcast broadcasts the signed txs to their chains in order, stopping at the first one that fails
+func (o *OfflineTxs) Broadcast(chains Chains) error {
+	for i, tx := range o.Txs {
+		c, err := chains.Get(tx.ChainID)
+		if err != nil {
+			return err
+		}
+		res, success, err := c.ChainProvider.BroadcastTx(tx)
+		switch {
+		case err != nil:
+			return fmt.Errorf("failed to broadcast tx %d for %s on %s: %w", i, tx.Signer, tx.ChainID, err)
+		case !success:
+			return fmt.Errorf("tx %d for %s on %s failed with code %d", i, tx.Signer, tx.ChainID, res.Code)
+		}
+		c.Log(fmt.Sprintf("★ [%s]@{%d} broadcast tx(%s) of %s", c.ChainID(), res.Height, res.TxHash, tx.Signer))
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package relayer
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/cosmos/relayer/relayer/provider"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateOnlyCreateClients(t *testing.T) {
+	src, dst := newMockChain(t, "ibc-0"), newMockChain(t, "ibc-1")
+	generated := &OfflineTxs{}
+	src.EnableGenerateOnly(generated)
+	dst.EnableGenerateOnly(generated)
+
+	_, err := src.CreateClients(dst, true, true, false)
+	require.ErrorIs(t, err, provider.ErrDryRun)
+	require.Len(t, generated.Txs, 2)
+	require.Equal(t, src.ChainID(), generated.Txs[0].ChainID)
+	require.Equal(t, dst.ChainID(), generated.Txs[1].ChainID)
+
+	file := filepath.Join(t.TempDir(), "txs.json")
+	require.NoError(t, generated.WriteFile(file))
+	txs, err := ReadOfflineTxs(file)
+	require.NoError(t, err)
+
+	// the txs are only accepted once signed by the keys of their chains
+	require.Error(t, txs.Broadcast(Chains{src, dst}))
+
+	signed, err := txs.Sign(Chains{src})
+	require.NoError(t, err)
+	require.Equal(t, 1, signed)
+	signed, err = txs.Sign(Chains{dst})
+	require.NoError(t, err)
+	require.Equal(t, 1, signed)
+	require.NoError(t, txs.Broadcast(Chains{src, dst}))
+
+	for _, c := range []*Chain{src, dst} {
+		clients, err := c.ChainProvider.QueryClients()
+		require.NoError(t, err)
+		require.Len(t, clients, 1)
+	}
+}
+
+func TestOfflineTxsSequences(t *testing.T) {
+	txs := &OfflineTxs{}
+	txs.add(&provider.OfflineTx{ChainID: "ibc-0", Signer: "a", Sequence: 4})
+	txs.add(&provider.OfflineTx{ChainID: "ibc-1", Signer: "a", Sequence: 7})
+	txs.add(&provider.OfflineTx{ChainID: "ibc-0", Signer: "a", Sequence: 4})
+	txs.add(&provider.OfflineTx{ChainID: "ibc-0", Signer: "b", Sequence: 1})
+
+	var seqs []uint64
+	for _, tx := range txs.Txs {
+		seqs = append(seqs, tx.Sequence)
+	}
+	require.Equal(t, []uint64{4, 7, 5, 1}, seqs)
+}
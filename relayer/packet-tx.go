@@ -53,7 +53,9 @@ func (c *Chain) SendTransferMsg(dst *Chain, amount sdk.Coin, dstAddr string, toH
 		Dst: []provider.RelayerMessage{},
 	}
 
-	if txs.Send(c, dst); !txs.Success() {
+	if txs.Send(c, dst); txs.simulated() {
+		return provider.ErrDryRun
+	} else if !txs.Success() {
 		return fmt.Errorf("failed to send transfer message")
 	}
 	return nil
@@ -89,10 +89,21 @@ type PathAction struct {
 // Path represents a pair of chains and the identifiers needed to
 // relay over them
 type Path struct {
-	Src *PathEnd `yaml:"src" json:"src"`
-	Dst *PathEnd `yaml:"dst" json:"dst"`
+	Src       *PathEnd      `yaml:"src" json:"src"`
+	Dst       *PathEnd      `yaml:"dst" json:"dst"`
+	FeeBudget *FeeBudget    `yaml:"fee-budget,omitempty" json:"fee-budget,omitempty"`
+	Filter    *PacketFilter `yaml:"filter,omitempty" json:"filter,omitempty"`
+
+	// Channels lists more channels on the src end of the connection to relay along with the
+	// channel of the path ends, ChannelWildcard stands for every open channel on the connection.
+	// They are relayed by rly start and the tx relay-packets, relay-acknowledgements and relay-timeouts
+	// commands, while tx relay-packet only looks for its sequence on the channel of the path ends.
+	Channels []string `yaml:"channels,omitempty" json:"channels,omitempty"`
 }
 
+// ChannelWildcard is the Channels entry for every open channel on the connection of a path
+const ChannelWildcard = "*"
+
 // Ordered returns true if the path is ordered and false if otherwise
 func (p *Path) Ordered() bool {
 	return p.Src.GetOrder() == chantypes.ORDERED
@@ -113,6 +124,70 @@ func (p *Path) String() string {
 	return fmt.Sprintf("[ ] %s ->\n %s", p.Src.String(), p.Dst.String())
 }
 
+// ValidateChannels returns an error if an entry of Channels is neither a channel identifier nor the wildcard
+func (p *Path) ValidateChannels() error {
+	for _, ch := range p.Channels {
+		if ch == ChannelWildcard {
+			continue
+		}
+		if err := (&PathEnd{ChannelID: ch}).Vchan(); err != nil {
+			return fmt.Errorf("invalid channel %s: %w", ch, err)
+		}
+	}
+	return nil
+}
+
+// ChannelPaths returns a copy of the path for every channel it relays, sharing the client and connection
+// of the path. The channel of the path ends comes first and is followed by the open channels on the
+// connection of src that are listed in Channels, as found by querying src at its latest height.
+func (p *Path) ChannelPaths(src *Chain) ([]*Path, error) {
+	var paths []*Path
+	if p.Src.ChannelID != "" {
+		paths = append(paths, &Path{Src: p.Src, Dst: p.Dst, FeeBudget: p.FeeBudget, Filter: p.Filter})
+	}
+	if len(p.Channels) == 0 {
+		return paths, nil
+	}
+
+	h, err := src.ChainProvider.QueryLatestHeight()
+	if err != nil {
+		return nil, err
+	}
+	channels, err := src.ChainProvider.QueryConnectionChannels(h, p.Src.ConnectionID)
+	if err != nil {
+		return nil, err
+	}
+
+	wanted := make(map[string]bool)
+	for _, ch := range p.Channels {
+		wanted[ch] = true
+	}
+	found := make(map[string]bool)
+	for _, ch := range channels {
+		found[ch.ChannelId] = true
+		if ch.ChannelId == p.Src.ChannelID || ch.State != chantypes.OPEN {
+			continue
+		}
+		if !wanted[ChannelWildcard] && !wanted[ch.ChannelId] {
+			continue
+		}
+
+		srcEnd, dstEnd := *p.Src, *p.Dst
+		srcEnd.ChannelID, srcEnd.PortID = ch.ChannelId, ch.PortId
+		dstEnd.ChannelID, dstEnd.PortID = ch.Counterparty.ChannelId, ch.Counterparty.PortId
+		srcEnd.Order, dstEnd.Order = orderToString(ch.Ordering), orderToString(ch.Ordering)
+		srcEnd.Version, dstEnd.Version = ch.Version, ch.Version
+		paths = append(paths, &Path{Src: &srcEnd, Dst: &dstEnd, FeeBudget: p.FeeBudget, Filter: p.Filter})
+	}
+
+	for _, ch := range p.Channels {
+		if ch != ChannelWildcard && !found[ch] {
+			return nil, fmt.Errorf("channel %s not found on connection %s of %s", ch, p.Src.ConnectionID, src.ChainID())
+		}
+	}
+	return paths, nil
+}
+
 // GenPath generates a path with unspecified client, connection and channel identifiers
 // given chainIDs and portIDs.
 func GenPath(srcChainID, dstChainID, srcPortID, dstPortID, order string, version string) *Path {
@@ -40,6 +40,18 @@ func OrderFromString(order string) chantypes.Order {
 	}
 }
 
+// orderToString returns the path end representation of a channel order
+func orderToString(order chantypes.Order) string {
+	switch order {
+	case chantypes.UNORDERED:
+		return "UNORDERED"
+	case chantypes.ORDERED:
+		return "ORDERED"
+	default:
+		return ""
+	}
+}
+
 // GetOrder returns the channel order for the path end
 func (pe *PathEnd) GetOrder() chantypes.Order {
 	return OrderFromString(strings.ToUpper(pe.Order))
@@ -0,0 +1,67 @@
+package cosmos
+
+import (
+	"time"
+
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/authz"
+	clienttypes "github.com/cosmos/ibc-go/v2/modules/core/02-client/types"
+	chantypes "github.com/cosmos/ibc-go/v2/modules/core/04-channel/types"
+	"github.com/cosmos/relayer/relayer/provider"
+)
+
+// relayMsgTypes are the msgs that a hot key can send on behalf of an authz granter, matching the msgs
+// whose signer can be replaced by withSigner
+var relayMsgTypes = []string{
+	sdk.MsgTypeURL(&clienttypes.MsgUpdateClient{}),
+	sdk.MsgTypeURL(&chantypes.MsgRecvPacket{}),
+	sdk.MsgTypeURL(&chantypes.MsgAcknowledgement{}),
+	sdk.MsgTypeURL(&chantypes.MsgTimeout{}),
+	sdk.MsgTypeURL(&chantypes.MsgTimeoutOnClose{}),
+}
+
+// SetAuthzGranter sets the address that relay msgs are sent on behalf of through authz, an empty address
+// makes the signing key send them itself
+func (cc *CosmosProvider) SetAuthzGranter(granter string) {
+	cc.PCfg.AuthzGranter = granter
+}
+
+// MsgGrantRelayAuthorizations constructs a MsgGrant from the key of the provider to grantee for every
+// relay msg, so grantee can relay on its behalf until expiration
+func (cc *CosmosProvider) MsgGrantRelayAuthorizations(grantee string, expiration time.Time) ([]provider.RelayerMessage, error) {
+	granter, err := cc.Address()
+	if err != nil {
+		return nil, err
+	}
+
+	msgs := make([]provider.RelayerMessage, 0, len(relayMsgTypes))
+	for _, msgType := range relayMsgTypes {
+		authorization, err := codectypes.NewAnyWithValue(authz.NewGenericAuthorization(msgType))
+		if err != nil {
+			return nil, err
+		}
+		msgs = append(msgs, NewCosmosMessage(&authz.MsgGrant{
+			Granter: granter,
+			Grantee: grantee,
+			Grant: authz.Grant{
+				Authorization: authorization,
+				Expiration:    expiration,
+			},
+		}))
+	}
+	return msgs, nil
+}
+
+// newMsgExec wraps msgs in a MsgExec sent by grantee
+func newMsgExec(grantee string, msgs []provider.RelayerMessage) (provider.RelayerMessage, error) {
+	anys := make([]*codectypes.Any, 0, len(msgs))
+	for _, msg := range CosmosMsgs(msgs...) {
+		msgAny, err := codectypes.NewAnyWithValue(msg)
+		if err != nil {
+			return nil, err
+		}
+		anys = append(anys, msgAny)
+	}
+	return NewCosmosMessage(&authz.MsgExec{Grantee: grantee, Msgs: anys}), nil
+}
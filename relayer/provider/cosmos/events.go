@@ -0,0 +1,112 @@
+package cosmos
+
+import (
+	"context"
+	"fmt"
+
+	ctypes "github.com/tendermint/tendermint/rpc/core/types"
+)
+
+// eventBufferSize is the capacity of every event channel handed out by SubscribeEvents
+const eventBufferSize = 1000
+
+// eventFeed fans the events of a single websocket subscription out to all of its listeners.
+// The tendermint websocket client only keeps one subscription per query, so paths that share
+// a provider must share the subscription as well.
+type eventFeed struct {
+	listeners map[chan ctypes.ResultEvent]struct{}
+	quit      chan struct{}
+}
+
+// SubscribeEvents subscribes to events matching the given tendermint query and returns a channel
+// that receives them until ctx is cancelled, at which point the channel is closed.
+func (cc *CosmosProvider) SubscribeEvents(ctx context.Context, query string) (<-chan ctypes.ResultEvent, error) {
+	cc.eventsMu.Lock()
+	defer cc.eventsMu.Unlock()
+
+	if !cc.RPCClient.IsRunning() {
+		if err := cc.RPCClient.Start(); err != nil {
+			return nil, fmt.Errorf("failed to start websocket client for %s: %w", cc.ChainId(), err)
+		}
+	}
+
+	if cc.eventFeeds == nil {
+		cc.eventFeeds = make(map[string]*eventFeed)
+	}
+
+	feed, ok := cc.eventFeeds[query]
+	if !ok {
+		in, err := cc.RPCClient.Subscribe(context.Background(), cc.subscriberName(), query, eventBufferSize)
+		if err != nil {
+			return nil, err
+		}
+		feed = &eventFeed{
+			listeners: make(map[chan ctypes.ResultEvent]struct{}),
+			quit:      make(chan struct{}),
+		}
+		cc.eventFeeds[query] = feed
+		go cc.fanOutEvents(query, feed, in)
+	}
+
+	out := make(chan ctypes.ResultEvent, eventBufferSize)
+	feed.listeners[out] = struct{}{}
+
+	go func() {
+		<-ctx.Done()
+		cc.eventsMu.Lock()
+		defer cc.eventsMu.Unlock()
+
+		if _, ok := feed.listeners[out]; !ok {
+			return
+		}
+		delete(feed.listeners, out)
+		close(out)
+
+		if len(feed.listeners) == 0 && cc.eventFeeds[query] == feed {
+			delete(cc.eventFeeds, query)
+			close(feed.quit)
+			if err := cc.RPCClient.Unsubscribe(context.Background(), cc.subscriberName(), query); err != nil && cc.PCfg.Debug {
+				cc.Log(fmt.Sprintf("- [%s] -> failed to unsubscribe from %s: %s", cc.ChainId(), query, err))
+			}
+		}
+	}()
+
+	return out, nil
+}
+
+// fanOutEvents copies every event received for query to the listeners of feed. Listeners that
+// are not keeping up have events dropped rather than stalling the other listeners.
+func (cc *CosmosProvider) fanOutEvents(query string, feed *eventFeed, in <-chan ctypes.ResultEvent) {
+	for {
+		select {
+		case <-feed.quit:
+			return
+		case ev, ok := <-in:
+			cc.eventsMu.Lock()
+			if !ok {
+				// the subscription was terminated, close every listener so they resubscribe
+				for l := range feed.listeners {
+					delete(feed.listeners, l)
+					close(l)
+				}
+				if cc.eventFeeds[query] == feed {
+					delete(cc.eventFeeds, query)
+				}
+				cc.eventsMu.Unlock()
+				return
+			}
+			for l := range feed.listeners {
+				select {
+				case l <- ev:
+				default:
+					cc.Log(fmt.Sprintf("- [%s] -> dropped event for %s, listener is full", cc.ChainId(), query))
+				}
+			}
+			cc.eventsMu.Unlock()
+		}
+	}
+}
+
+func (cc *CosmosProvider) subscriberName() string {
+	return fmt.Sprintf("rly-%s", cc.ChainId())
+}
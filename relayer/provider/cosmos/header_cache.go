@@ -0,0 +1,82 @@
+package cosmos
+
+import (
+	"container/list"
+	"sync"
+
+	tmclient "github.com/cosmos/ibc-go/v2/modules/light-clients/07-tendermint/types"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+)
+
+// headerCache is a bounded, least recently used cache of the signed headers and validator sets
+// of a chain keyed by height. It is safe for concurrent use, so one cache can serve every path
+// that uses the chain.
+type headerCache struct {
+	mu      sync.Mutex
+	size    int
+	entries map[int64]*list.Element
+	order   *list.List
+}
+
+type headerCacheEntry struct {
+	height       int64
+	signedHeader *tmproto.SignedHeader
+	validatorSet *tmproto.ValidatorSet
+}
+
+// newHeaderCache returns a cache holding at most size headers, a size of zero disables the cache
+func newHeaderCache(size int) *headerCache {
+	return &headerCache{
+		size:    size,
+		entries: make(map[int64]*list.Element),
+		order:   list.New(),
+	}
+}
+
+// get returns the header cached for the height. The header is a new value on every call so
+// callers are free to set its trusted fields.
+func (c *headerCache) get(height int64) (*tmclient.Header, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[height]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(el)
+	entry := el.Value.(*headerCacheEntry)
+	return &tmclient.Header{
+		SignedHeader: entry.signedHeader,
+		ValidatorSet: entry.validatorSet,
+	}, true
+}
+
+// add caches the signed header and validator set of the header, evicting the least recently
+// used height when the cache is full
+func (c *headerCache) add(height int64, header *tmclient.Header) {
+	if c == nil || c.size <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.entries[height]; ok {
+		c.order.MoveToFront(el)
+		return
+	}
+
+	c.entries[height] = c.order.PushFront(&headerCacheEntry{
+		height:       height,
+		signedHeader: header.SignedHeader,
+		validatorSet: header.ValidatorSet,
+	})
+
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*headerCacheEntry).height)
+	}
+}
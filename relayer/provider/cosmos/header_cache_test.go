@@ -0,0 +1,71 @@
+package cosmos
+
+import (
+	"testing"
+
+	tmclient "github.com/cosmos/ibc-go/v2/modules/light-clients/07-tendermint/types"
+	"github.com/stretchr/testify/require"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+)
+
+func testHeader(height int64) *tmclient.Header {
+	return &tmclient.Header{
+		SignedHeader: &tmproto.SignedHeader{Header: &tmproto.Header{ChainID: "ibc-0", Height: height}},
+		ValidatorSet: &tmproto.ValidatorSet{},
+	}
+}
+
+func TestHeaderCacheHit(t *testing.T) {
+	c := newHeaderCache(2)
+	_, ok := c.get(1)
+	require.False(t, ok)
+
+	c.add(1, testHeader(1))
+	h, ok := c.get(1)
+	require.True(t, ok)
+	require.Equal(t, int64(1), h.Header.Height)
+
+	// every hit is a new header, setting the trusted fields of one leaves the cached entry alone
+	h.TrustedValidators = &tmproto.ValidatorSet{}
+	again, ok := c.get(1)
+	require.True(t, ok)
+	require.Nil(t, again.TrustedValidators)
+}
+
+func TestHeaderCacheEviction(t *testing.T) {
+	c := newHeaderCache(2)
+	c.add(1, testHeader(1))
+	c.add(2, testHeader(2))
+
+	// a hit makes 1 the most recently used height, so adding 3 evicts 2
+	_, ok := c.get(1)
+	require.True(t, ok)
+	c.add(3, testHeader(3))
+	require.Equal(t, 2, c.order.Len())
+
+	_, ok = c.get(2)
+	require.False(t, ok)
+	for _, height := range []int64{1, 3} {
+		_, ok = c.get(height)
+		require.True(t, ok, height)
+	}
+
+	// adding a cached height again neither duplicates it nor evicts another
+	c.add(3, testHeader(3))
+	require.Equal(t, 2, c.order.Len())
+	_, ok = c.get(1)
+	require.True(t, ok)
+}
+
+func TestHeaderCacheDisabled(t *testing.T) {
+	c := newHeaderCache(0)
+	c.add(1, testHeader(1))
+	_, ok := c.get(1)
+	require.False(t, ok)
+	require.Zero(t, c.order.Len())
+
+	var unset *headerCache
+	unset.add(1, testHeader(1))
+	_, ok = unset.get(1)
+	require.False(t, ok)
+}
@@ -0,0 +1,125 @@
+package cosmos
+
+import (
+	"sync/atomic"
+
+	"github.com/avast/retry-go"
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/client/tx"
+	clienttypes "github.com/cosmos/ibc-go/v2/modules/core/02-client/types"
+	chantypes "github.com/cosmos/ibc-go/v2/modules/core/04-channel/types"
+	"github.com/cosmos/relayer/relayer/provider"
+)
+
+// KeyPool returns the names of the keys that relay transactions are signed with in turn
+func (cc *CosmosProvider) KeyPool() []string {
+	return cc.PCfg.Keys
+}
+
+// SetKeyPool replaces the key pool of the provider, an empty pool signs every transaction with its key
+func (cc *CosmosProvider) SetKeyPool(keys []string) {
+	cc.PCfg.Keys = keys
+}
+
+// NextKey returns the key to sign the txs of the next relay round with. The keys of the pool are taken
+// in turn, so separate rounds and paths are spread over the pool while the txs of one round share an
+// account and enter the mempool in order. Without a pool it is the key of the provider.
+func (cc *CosmosProvider) NextKey() string {
+	if len(cc.PCfg.Keys) == 0 {
+		return cc.PCfg.Key
+	}
+	i := atomic.AddUint64(&cc.keyIndex, 1) - 1
+	return cc.PCfg.Keys[i%uint64(len(cc.PCfg.Keys))]
+}
+
+// signingKey returns the key to sign msgs with along with msgs signed by that key. The msgs are signed
+// with the given key, or the key of the provider if it is empty, unless one of them can not be sent by
+// a pool key, in which case they are signed with the key of the provider. With an authz granter
+// configured, msgs that can be sent on its behalf are wrapped in a MsgExec signed by the chosen key.
+func (cc *CosmosProvider) signingKey(msgs []provider.RelayerMessage, key string) (string, []provider.RelayerMessage, error) {
+	if key == "" {
+		key = cc.PCfg.Key
+	}
+	if key == cc.PCfg.Key && cc.PCfg.AuthzGranter == "" {
+		return key, msgs, nil
+	}
+
+	addr, err := cc.ShowAddress(key)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if cc.PCfg.AuthzGranter != "" {
+		if inner, ok := withSigner(msgs, cc.PCfg.AuthzGranter); ok {
+			exec, err := newMsgExec(addr, inner)
+			if err != nil {
+				return "", nil, err
+			}
+			return key, []provider.RelayerMessage{exec}, nil
+		}
+	}
+
+	if key == cc.PCfg.Key {
+		return key, msgs, nil
+	}
+	signed, ok := withSigner(msgs, addr)
+	if !ok {
+		return cc.PCfg.Key, msgs, nil
+	}
+	return key, signed, nil
+}
+
+// withSigner returns copies of msgs signed by addr. Only client updates and packet msgs, whose signer
+// is nothing more than the account paying for them, can be moved to another key.
+func withSigner(msgs []provider.RelayerMessage, addr string) ([]provider.RelayerMessage, bool) {
+	out := make([]provider.RelayerMessage, 0, len(msgs))
+	for _, msg := range msgs {
+		switch m := CosmosMsg(msg).(type) {
+		case *clienttypes.MsgUpdateClient:
+			c := *m
+			c.Signer = addr
+			out = append(out, NewCosmosMessage(&c))
+		case *chantypes.MsgRecvPacket:
+			c := *m
+			c.Signer = addr
+			out = append(out, NewCosmosMessage(&c))
+		case *chantypes.MsgAcknowledgement:
+			c := *m
+			c.Signer = addr
+			out = append(out, NewCosmosMessage(&c))
+		case *chantypes.MsgTimeout:
+			c := *m
+			c.Signer = addr
+			out = append(out, NewCosmosMessage(&c))
+		case *chantypes.MsgTimeoutOnClose:
+			c := *m
+			c.Signer = addr
+			out = append(out, NewCosmosMessage(&c))
+		default:
+			return nil, false
+		}
+	}
+	return out, true
+}
+
+// prepareFactory sets the account number and the committed account sequence of the named key on txf
+func (cc *CosmosProvider) prepareFactory(txf tx.Factory, key string) (tx.Factory, error) {
+	info, err := cc.Keybase.Key(key)
+	if err != nil {
+		return txf, err
+	}
+
+	cliCtx := client.Context{}.WithClient(cc.RPCClient).
+		WithInterfaceRegistry(cc.Codec.InterfaceRegistry).
+		WithChainID(cc.PCfg.ChainID).
+		WithCodec(cc.Codec.Marshaler)
+
+	var num, seq uint64
+	if err = retry.Do(func() error {
+		num, seq, err = txf.AccountRetriever().GetAccountNumberSequence(cliCtx, info.GetAddress())
+		return err
+	}, RtyAtt, RtyDel, RtyErr); err != nil {
+		return txf, err
+	}
+	return txf.WithAccountNumber(num).WithSequence(seq), nil
+}
@@ -0,0 +1,98 @@
+package cosmos
+
+import (
+	"testing"
+
+	"github.com/cosmos/cosmos-sdk/crypto/hd"
+	"github.com/cosmos/cosmos-sdk/crypto/keyring"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/authz"
+	transfertypes "github.com/cosmos/ibc-go/v2/modules/apps/transfer/types"
+	clienttypes "github.com/cosmos/ibc-go/v2/modules/core/02-client/types"
+	chantypes "github.com/cosmos/ibc-go/v2/modules/core/04-channel/types"
+	"github.com/cosmos/relayer/relayer/provider"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestKeyProvider returns a provider holding the key "relayer" and a pool of the keys "pool-1" and
+// "pool-2", along with their addresses
+func newTestKeyProvider(t *testing.T) (*CosmosProvider, map[string]string) {
+	t.Helper()
+	cc := newTestProvider(t)
+	cc.Keybase = keyring.NewInMemory()
+	addrs := make(map[string]string)
+	for _, key := range []string{"relayer", "pool-1", "pool-2"} {
+		_, _, err := cc.Keybase.NewMnemonic(key, keyring.English, hd.CreateHDPath(118, 0, 0).String(), "", hd.Secp256k1)
+		require.NoError(t, err)
+		addrs[key], err = cc.ShowAddress(key)
+		require.NoError(t, err)
+	}
+	cc.SetKeyPool([]string{"pool-1", "pool-2"})
+	return cc, addrs
+}
+
+// relayMsgs returns a client update and a packet receipt signed by signer
+func relayMsgs(signer string) []provider.RelayerMessage {
+	return []provider.RelayerMessage{
+		NewCosmosMessage(&clienttypes.MsgUpdateClient{ClientId: "07-tendermint-0", Signer: signer}),
+		NewCosmosMessage(&chantypes.MsgRecvPacket{Signer: signer}),
+	}
+}
+
+func TestSigningKeyPool(t *testing.T) {
+	cc, addrs := newTestKeyProvider(t)
+
+	// the msgs are signed by the provider key when no key is given
+	key, msgs, err := cc.signingKey(relayMsgs(addrs["relayer"]), "")
+	require.NoError(t, err)
+	require.Equal(t, "relayer", key)
+	require.Equal(t, addrs["relayer"], CosmosMsg(msgs[0]).(*clienttypes.MsgUpdateClient).Signer)
+
+	// relay msgs are moved to a pool key, leaving the msgs passed in alone
+	orig := relayMsgs(addrs["relayer"])
+	key, msgs, err = cc.signingKey(orig, "pool-2")
+	require.NoError(t, err)
+	require.Equal(t, "pool-2", key)
+	require.Equal(t, addrs["pool-2"], CosmosMsg(msgs[0]).(*clienttypes.MsgUpdateClient).Signer)
+	require.Equal(t, addrs["pool-2"], CosmosMsg(msgs[1]).(*chantypes.MsgRecvPacket).Signer)
+	require.Equal(t, addrs["relayer"], CosmosMsg(orig[0]).(*clienttypes.MsgUpdateClient).Signer)
+
+	// a transfer spends the funds of its signer, so it stays with the provider key
+	transfer := append(relayMsgs(addrs["relayer"]), NewCosmosMessage(&transfertypes.MsgTransfer{Sender: addrs["relayer"]}))
+	key, msgs, err = cc.signingKey(transfer, "pool-1")
+	require.NoError(t, err)
+	require.Equal(t, "relayer", key)
+	require.Equal(t, transfer, msgs)
+}
+
+func TestSigningKeyAuthzGranter(t *testing.T) {
+	cc, addrs := newTestKeyProvider(t)
+	granterAddr, granter := testAddress(t, cc, 9)
+	cc.SetAuthzGranter(granter)
+
+	// relay msgs are sent on behalf of the granter in a MsgExec signed by the chosen key
+	for _, signer := range []string{"relayer", "pool-1"} {
+		key, msgs, err := cc.signingKey(relayMsgs(addrs["relayer"]), signer)
+		require.NoError(t, err)
+		require.Equal(t, signer, key)
+		require.Len(t, msgs, 1)
+		exec, ok := CosmosMsg(msgs[0]).(*authz.MsgExec)
+		require.True(t, ok)
+		require.Equal(t, addrs[signer], exec.Grantee)
+
+		require.NoError(t, exec.UnpackInterfaces(cc.Codec.InterfaceRegistry))
+		inner, err := exec.GetMessages()
+		require.NoError(t, err)
+		require.Len(t, inner, 2)
+		for _, msg := range inner {
+			require.Equal(t, []sdk.AccAddress{granterAddr}, msg.GetSigners())
+		}
+	}
+
+	// msgs that can not be sent on behalf of the granter are signed by the provider key itself
+	transfer := []provider.RelayerMessage{NewCosmosMessage(&transfertypes.MsgTransfer{Sender: addrs["relayer"]})}
+	key, msgs, err := cc.signingKey(transfer, "pool-1")
+	require.NoError(t, err)
+	require.Equal(t, "relayer", key)
+	require.Equal(t, transfer, msgs)
+}
@@ -0,0 +1,26 @@
+package cosmos
+
+import (
+	"fmt"
+
+	"github.com/cosmos/cosmos-sdk/crypto/hd"
+)
+
+// signModeAminoJSON is the only sign mode the cosmos app of a Ledger device signs with
+const signModeAminoJSON = "amino-json"
+
+// AddLedgerKey adds a key held by a Ledger device under name, derived with the given coin type, account
+// and index. Only its public key is kept in the keyring, every tx signed with it has to be confirmed on
+// the device, which suits keys that sign now and then, such as a fee granter or the keys of a signer
+// host, rather than relay rounds. The relayer has to be built with the ledger build tag.
+func (cc *CosmosProvider) AddLedgerKey(name string, coinType, account, index uint32) (string, error) {
+	if cc.PCfg.SignModeStr != signModeAminoJSON {
+		return "", fmt.Errorf("a Ledger device only signs in sign mode %s, set \"sign-mode\": %q in the config of chain %s",
+			signModeAminoJSON, signModeAminoJSON, cc.PCfg.ChainID)
+	}
+	info, err := cc.Keybase.SaveLedgerKey(name, hd.Secp256k1, cc.PCfg.AccountPrefix, coinType, account, index)
+	if err != nil {
+		return "", err
+	}
+	return cc.EncodeBech32AccAddr(info.GetAddress())
+}
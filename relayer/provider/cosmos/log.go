@@ -18,14 +18,16 @@ func (cc *CosmosProvider) LogFailedTx(res *provider.RelayerTxResponse, err error
 	}
 
 	if err != nil {
-		cc.Logger.Error(fmt.Errorf("- [%s] -> err(%v)", cc.ChainId(), err).Error())
+		cc.Logger.Error(fmt.Errorf("- [%s] -> err(%v)", cc.ChainId(), err).Error(),
+			"msg_types", getMsgTypes(msgs), "error", err.Error())
 		if res == nil {
 			return
 		}
 	}
 
 	if res.Code != 0 && res.Data != "" {
-		cc.Log(fmt.Sprintf("✘ [%s]@{%d} - msg(%s) err(%d:%s)", cc.ChainId(), res.Height, getMsgTypes(msgs), res.Code, res.Data))
+		cc.Logger.Error(fmt.Sprintf("✘ [%s]@{%d} - msg(%s) err(%d:%s)", cc.ChainId(), res.Height, getMsgTypes(msgs), res.Code, res.Data),
+			"msg_types", getMsgTypes(msgs), "tx_hash", res.TxHash, "height", res.Height, "gas_used", res.GasUsed, "code", res.Code)
 	}
 
 	if cc.PCfg.Debug && res != nil {
@@ -36,7 +38,8 @@ func (cc *CosmosProvider) LogFailedTx(res *provider.RelayerTxResponse, err error
 
 // LogSuccessTx take the transaction and the messages to create it and logs the appropriate data
 func (cc *CosmosProvider) LogSuccessTx(res *sdk.TxResponse, msgs []provider.RelayerMessage) {
-	cc.Logger.Info(fmt.Sprintf("✔ [%s]@{%d} - msg(%s) hash(%s)", cc.ChainId(), res.Height, getMsgTypes(msgs), res.TxHash))
+	cc.Logger.Info(fmt.Sprintf("✔ [%s]@{%d} - msg(%s) hash(%s)", cc.ChainId(), res.Height, getMsgTypes(msgs), res.TxHash),
+		"msg_types", getMsgTypes(msgs), "tx_hash", res.TxHash, "height", res.Height, "gas_used", res.GasUsed)
 }
 
 func getMsgTypes(msgs []provider.RelayerMessage) string {
@@ -0,0 +1,122 @@
+package cosmos
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cosmos/cosmos-sdk/client/tx"
+	authsigning "github.com/cosmos/cosmos-sdk/x/auth/signing"
+	"github.com/cosmos/relayer/relayer/provider"
+	tmtypes "github.com/tendermint/tendermint/types"
+)
+
+// GenerateTx builds an unsigned transaction of msgs for the key of the provider, with its gas estimated
+// the way BroadcastMessages does, to be signed out of band. The key may be one holding only the public key,
+// as no signature is made. The key pool and the authz granter are not used for generated transactions.
+func (cc *CosmosProvider) GenerateTx(msgs []provider.RelayerMessage) (*provider.OfflineTx, error) {
+	signer, err := cc.Address()
+	if err != nil {
+		return nil, err
+	}
+
+	txf, err := cc.prepareFactory(cc.TxFactory(), cc.PCfg.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	_, adjusted, err := cc.CalculateGas(txf, CosmosMsgs(msgs...)...)
+	if err != nil {
+		return nil, err
+	}
+	txf = txf.WithGas(adjusted)
+
+	txb, err := tx.BuildUnsignedTx(txf, CosmosMsgs(msgs...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = cc.setFeeGranter(txb); err != nil {
+		return nil, err
+	}
+
+	bz, err := cc.Codec.TxConfig.TxJSONEncoder()(txb.GetTx())
+	if err != nil {
+		return nil, err
+	}
+	return &provider.OfflineTx{
+		ChainID:       cc.PCfg.ChainID,
+		Signer:        signer,
+		AccountNumber: txf.AccountNumber(),
+		Sequence:      txf.Sequence(),
+		Tx:            bz,
+	}, nil
+}
+
+// SignTx signs a generated transaction with the key of the provider, which must be the key of its signer.
+// The account number and sequence are taken from the transaction, so no node is queried.
+func (cc *CosmosProvider) SignTx(otx *provider.OfflineTx) error {
+	if otx.ChainID != cc.PCfg.ChainID {
+		return fmt.Errorf("transaction is for chain %s, not %s", otx.ChainID, cc.PCfg.ChainID)
+	}
+	addr, err := cc.Address()
+	if err != nil {
+		return err
+	}
+	if addr != otx.Signer {
+		return fmt.Errorf("transaction must be signed by %s, but key %s has address %s", otx.Signer, cc.PCfg.Key, addr)
+	}
+
+	stdTx, err := cc.Codec.TxConfig.TxJSONDecoder()(otx.Tx)
+	if err != nil {
+		return err
+	}
+	txb, err := cc.Codec.TxConfig.WrapTxBuilder(stdTx)
+	if err != nil {
+		return err
+	}
+
+	txf := cc.TxFactory().WithAccountNumber(otx.AccountNumber).WithSequence(otx.Sequence)
+	done := cc.SetSDKContext()
+	err = tx.Sign(txf, cc.PCfg.Key, txb, true)
+	done()
+	if err != nil {
+		return err
+	}
+
+	if otx.Tx, err = cc.Codec.TxConfig.TxJSONEncoder()(txb.GetTx()); err != nil {
+		return err
+	}
+	return nil
+}
+
+// BroadcastTx broadcasts a transaction signed by SignTx and waits for it to be committed
+func (cc *CosmosProvider) BroadcastTx(otx *provider.OfflineTx) (*provider.RelayerTxResponse, bool, error) {
+	if otx.ChainID != cc.PCfg.ChainID {
+		return nil, false, fmt.Errorf("transaction is for chain %s, not %s", otx.ChainID, cc.PCfg.ChainID)
+	}
+
+	stdTx, err := cc.Codec.TxConfig.TxJSONDecoder()(otx.Tx)
+	if err != nil {
+		return nil, false, err
+	}
+	sigTx, ok := stdTx.(authsigning.SigVerifiableTx)
+	if !ok {
+		return nil, false, fmt.Errorf("cannot read the signatures of a transaction of type %T", stdTx)
+	}
+	if sigs, err := sigTx.GetSignaturesV2(); err != nil || len(sigs) == 0 {
+		return nil, false, fmt.Errorf("transaction for %s is not signed", otx.Signer)
+	}
+
+	txBytes, err := cc.Codec.TxConfig.TxEncoder()(stdTx)
+	if err != nil {
+		return nil, false, err
+	}
+	res, err := cc.RPCClient.BroadcastTxSync(context.Background(), txBytes)
+	if err != nil {
+		return nil, false, err
+	}
+	if res.Code != 0 {
+		return nil, false, fmt.Errorf("transaction failed check with code: %d: %s", res.Code, res.Log)
+	}
+	return cc.waitForTx(fmt.Sprintf("%X", tmtypes.Tx(txBytes).Hash()), defaultTxCommitTimeout, nil)
+}
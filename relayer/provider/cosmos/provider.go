@@ -2,12 +2,16 @@ package cosmos
 
 import (
 	"context"
+	"encoding/hex"
 	"fmt"
 	"github.com/cosmos/cosmos-sdk/types/module"
 	"math"
 	"os"
 	"reflect"
 	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/cosmos/cosmos-sdk/client"
@@ -19,6 +23,8 @@ import (
 	"github.com/cosmos/cosmos-sdk/crypto/keyring"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	bankTypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/cosmos/cosmos-sdk/x/feegrant"
 	transfertypes "github.com/cosmos/ibc-go/v2/modules/apps/transfer/types"
 	clienttypes "github.com/cosmos/ibc-go/v2/modules/core/02-client/types"
 	conntypes "github.com/cosmos/ibc-go/v2/modules/core/03-connection/types"
@@ -31,6 +37,7 @@ import (
 	"github.com/gogo/protobuf/proto"
 	"github.com/pkg/errors"
 	lens "github.com/strangelove-ventures/lens/client"
+	"github.com/tendermint/tendermint/libs/log"
 	ctypes "github.com/tendermint/tendermint/rpc/core/types"
 	tmtypes "github.com/tendermint/tendermint/types"
 )
@@ -44,6 +51,18 @@ var (
 	defaultChainPrefix = commitmenttypes.NewMerklePrefix([]byte("ibc"))
 	defaultDelayPeriod = uint64(0)
 
+	// defaultTxCommitTimeout is how long SendMessages waits for a broadcast transaction to be committed
+	defaultTxCommitTimeout = time.Minute
+
+	// defaultBlockTime is how often a transaction being waited for is looked up until the block time of
+	// the chain is measured over its last blockTimeSample blocks, and minTxPollInterval the most often
+	defaultBlockTime  = time.Second
+	minTxPollInterval = 100 * time.Millisecond
+	blockTimeSample   = int64(10)
+
+	// maxMempoolTxs is the most unconfirmed transactions a node lists
+	maxMempoolTxs = 100
+
 	// Variables used for retries
 	RtyAttNum = uint(5)
 	RtyAtt    = retry.Attempts(RtyAttNum)
@@ -104,24 +123,58 @@ func (cm CosmosMessage) MsgBytes() ([]byte, error) {
 	return proto.Marshal(cm.Msg)
 }
 
+// CosmosProviderConfig is the configuration of a cosmos chain. When Keys lists a pool of keys, relay
+// rounds are signed with them in turn, each key with its own account sequence, so the txs of separate
+// rounds and paths can be included in the same block while the txs of one round stay on one account.
+// Key is still used for everything else. When FeeGranter is set to
+// the address of a treasury account, the fees of every transaction are paid through the x/feegrant
+// allowance it granted to the signing key. When AuthzGranter is set, client updates and packet msgs are
+// sent on behalf of that address through an authz MsgExec signed by the signing key. When Signer is set,
+// the keys are held by that remote signer rather than by the keyring, which is not used.
 type CosmosProviderConfig struct {
-	Key            string  `json:"key" yaml:"key"`
-	ChainID        string  `json:"chain-id" yaml:"chain-id"`
-	RPCAddr        string  `json:"rpc-addr" yaml:"rpc-addr"`
-	AccountPrefix  string  `json:"account-prefix" yaml:"account-prefix"`
-	KeyringBackend string  `json:"keyring-backend" yaml:"keyring-backend"`
-	GasAdjustment  float64 `json:"gas-adjustment" yaml:"gas-adjustment"`
-	GasPrices      string  `json:"gas-prices" yaml:"gas-prices"`
-	Debug          bool    `json:"debug" yaml:"debug"`
-	Timeout        string  `json:"timeout" yaml:"timeout"`
-	OutputFormat   string  `json:"output-format" yaml:"output-format"`
-	SignModeStr    string  `json:"sign-mode" yaml:"sign-mode"`
+	Key            string        `json:"key" yaml:"key"`
+	Keys           []string      `json:"keys,omitempty" yaml:"keys,omitempty"`
+	FeeGranter     string        `json:"fee-granter,omitempty" yaml:"fee-granter,omitempty"`
+	AuthzGranter   string        `json:"authz-granter,omitempty" yaml:"authz-granter,omitempty"`
+	ChainID        string        `json:"chain-id" yaml:"chain-id"`
+	RPCAddr        string        `json:"rpc-addr" yaml:"rpc-addr"`
+	AccountPrefix  string        `json:"account-prefix" yaml:"account-prefix"`
+	KeyringBackend string        `json:"keyring-backend" yaml:"keyring-backend"`
+	GasAdjustment  float64       `json:"gas-adjustment" yaml:"gas-adjustment"`
+	GasPrices      string        `json:"gas-prices" yaml:"gas-prices"`
+	Debug          bool          `json:"debug" yaml:"debug"`
+	Timeout        string        `json:"timeout" yaml:"timeout"`
+	OutputFormat   string        `json:"output-format" yaml:"output-format"`
+	SignModeStr    string        `json:"sign-mode" yaml:"sign-mode"`
+	Signer         *SignerConfig `json:"signer,omitempty" yaml:"signer,omitempty"`
 }
 
 func (pc CosmosProviderConfig) Validate() error {
 	if _, err := time.ParseDuration(pc.Timeout); err != nil {
 		return err
 	}
+	if pc.FeeGranter != "" {
+		if _, err := sdk.GetFromBech32(pc.FeeGranter, pc.AccountPrefix); err != nil {
+			return fmt.Errorf("invalid fee-granter %s: %w", pc.FeeGranter, err)
+		}
+	}
+	if pc.AuthzGranter != "" {
+		if _, err := sdk.GetFromBech32(pc.AuthzGranter, pc.AccountPrefix); err != nil {
+			return fmt.Errorf("invalid authz-granter %s: %w", pc.AuthzGranter, err)
+		}
+	}
+	if pc.Signer != nil {
+		if err := pc.Signer.Validate(); err != nil {
+			return err
+		}
+	}
+	seen := make(map[string]bool)
+	for _, key := range pc.Keys {
+		if seen[key] {
+			return fmt.Errorf("key %s is listed twice in the key pool", key)
+		}
+		seen[key] = true
+	}
 	return nil
 }
 
@@ -134,6 +187,14 @@ func (pc CosmosProviderConfig) NewProvider(homepath string, debug bool) (provide
 	if err != nil {
 		return nil, err
 	}
+	if pc.Signer != nil {
+		timeout, _ := time.ParseDuration(pc.Timeout)
+		signer, err := NewHTTPSigner(*pc.Signer, timeout)
+		if err != nil {
+			return nil, err
+		}
+		cc.Keybase = newSignerKeyring(signer, append([]string{pc.Key}, pc.Keys...))
+	}
 	return &CosmosProvider{ChainClient: *cc, PCfg: pc}, nil
 }
 
@@ -159,6 +220,17 @@ func ChainClientConfig(pcfg *CosmosProviderConfig) *lens.ChainClientConfig {
 type CosmosProvider struct {
 	lens.ChainClient
 	PCfg CosmosProviderConfig
+
+	eventsMu   sync.Mutex
+	eventFeeds map[string]*eventFeed
+
+	lightCache *headerCache
+
+	sequences sequenceManager
+	keyIndex  uint64
+
+	// measuredBlockTime is the block time of the chain once blockTime measured it
+	measuredBlockTime int64
 }
 
 func (cc *CosmosProvider) ProviderConfig() provider.ProviderConfig {
@@ -256,8 +328,22 @@ func (cc *CosmosProvider) CreateClient(clientState ibcexported.ClientState, dstH
 	return NewCosmosMessage(msg), nil
 }
 
-func (cc *CosmosProvider) SubmitMisbehavior( /*TBD*/ ) (provider.RelayerMessage, error) {
-	return nil, nil
+// SubmitMisbehavior creates an sdk.Msg to submit evidence of misbehaviour of the counterparty to the client on src
+func (cc *CosmosProvider) SubmitMisbehavior(clientId string, misbehaviour ibcexported.Misbehaviour) (provider.RelayerMessage, error) {
+	acc, err := cc.Address()
+	if err != nil {
+		return nil, err
+	}
+
+	msg, err := clienttypes.NewMsgSubmitMisbehaviour(clientId, misbehaviour, acc)
+	if err != nil {
+		return nil, err
+	}
+	if err = msg.ValidateBasic(); err != nil {
+		return nil, err
+	}
+
+	return NewCosmosMessage(msg), nil
 }
 
 func (cc *CosmosProvider) UpdateClient(srcClientId string, dstHeader ibcexported.Header) (provider.RelayerMessage, error) {
@@ -665,27 +751,111 @@ func (cc *CosmosProvider) GetIBCUpdateHeader(srch int64, dst provider.ChainProvi
 	return cc.InjectTrustedFields(h, dst, dstClientId)
 }
 
+// GetLightSignedHeaderAtHeight returns the signed header and validator set at the given height,
+// serving them from the light cache when possible. Light blocks are only cached once they pass
+// basic validation against the chain id and are at the requested height, so a cache hit is as
+// trustworthy as a fresh query. Neither is verified against a trusted header here, that is left to
+// the light client of the counterparty that the header is submitted to.
 func (cc *CosmosProvider) GetLightSignedHeaderAtHeight(h int64) (ibcexported.Header, error) {
 	if h == 0 {
 		return nil, errors.New("height cannot be 0")
 	}
 
+	if header, ok := cc.lightCache.get(h); ok {
+		return header, nil
+	}
+
 	lightBlock, err := cc.LightProvider.LightBlock(context.Background(), h)
 	if err != nil {
 		return nil, err
 	}
 
+	if err = lightBlock.ValidateBasic(cc.PCfg.ChainID); err != nil {
+		return nil, fmt.Errorf("invalid light block at height %d: %w", h, err)
+	}
+	if lightBlock.Height != h {
+		return nil, fmt.Errorf("light block at height %d returned for height %d", lightBlock.Height, h)
+	}
+
 	protoVal, err := tmtypes.NewValidatorSet(lightBlock.ValidatorSet.Validators).ToProto()
 	if err != nil {
 		return nil, err
 	}
 
-	return &tmclient.Header{
+	header := &tmclient.Header{
 		SignedHeader: lightBlock.SignedHeader.ToProto(),
 		ValidatorSet: protoVal,
+	}
+	cc.lightCache.add(h, header)
+
+	return &tmclient.Header{
+		SignedHeader: header.SignedHeader,
+		ValidatorSet: header.ValidatorSet,
 	}, nil
 }
 
+// txFee returns the fee paid by a committed transaction
+func (cc *CosmosProvider) txFee(resTx *ctypes.ResultTx) sdk.Coins {
+	decoded, err := cc.Codec.TxConfig.TxDecoder()(resTx.Tx)
+	if err != nil {
+		return sdk.NewCoins()
+	}
+	feeTx, ok := decoded.(sdk.FeeTx)
+	if !ok {
+		return sdk.NewCoins()
+	}
+	return feeTx.GetFee()
+}
+
+// bumpedGasPrices returns the configured gas prices multiplied by feeBump
+func (cc *CosmosProvider) bumpedGasPrices(feeBump float64) (string, error) {
+	prices, err := sdk.ParseDecCoins(cc.PCfg.GasPrices)
+	if err != nil {
+		return "", err
+	}
+	bump, err := sdk.NewDecFromStr(strconv.FormatFloat(feeBump, 'f', 6, 64))
+	if err != nil {
+		return "", err
+	}
+	return prices.MulDec(bump).String(), nil
+}
+
+// SetFeeGranter sets the address of the account that pays the fees of the transactions signed by the
+// provider, an empty address makes the signing key pay them
+func (cc *CosmosProvider) SetFeeGranter(granter string) {
+	cc.PCfg.FeeGranter = granter
+}
+
+// setFeeGranter lets the treasury account pay the fees of txb through its allowance, if one is set
+func (cc *CosmosProvider) setFeeGranter(txb client.TxBuilder) error {
+	if cc.PCfg.FeeGranter == "" {
+		return nil
+	}
+	granter, err := cc.DecodeBech32AccAddr(cc.PCfg.FeeGranter)
+	if err != nil {
+		return err
+	}
+	txb.SetFeeGranter(granter)
+	return nil
+}
+
+// FeeGranter returns the address of the account that pays the fees of the transactions signed by the
+// provider, or an empty string if the signing key pays them
+func (cc *CosmosProvider) FeeGranter() string {
+	return cc.PCfg.FeeGranter
+}
+
+// SetLogger replaces the logger used for the txs sent by the provider
+func (cc *CosmosProvider) SetLogger(logger log.Logger) {
+	cc.Logger = logger
+}
+
+// SetLightCacheSize replaces the light cache of the provider with an empty cache holding
+// at most size heights, a size of zero disables caching
+func (cc *CosmosProvider) SetLightCacheSize(size int) {
+	cc.lightCache = newHeaderCache(size)
+}
+
 // InjectTrustedFields injects the necessary trusted fields for a header to update a light
 // client stored on the destination chain, using the information provided by the source
 // chain.
@@ -792,24 +962,72 @@ func (cc *CosmosProvider) MsgTransfer(amount sdk.Coin, dstChainId, dstAddr, srcP
 		return nil, err
 	}
 
-	version := clienttypes.ParseChainID(dstChainId)
-
 	msg := &transfertypes.MsgTransfer{
-		SourcePort:    srcPortId,
-		SourceChannel: srcChanId,
-		Token:         amount,
-		Sender:        acc,
-		Receiver:      dstAddr,
-		TimeoutHeight: clienttypes.Height{
-			RevisionNumber: version,
-			RevisionHeight: timeoutHeight,
-		},
+		SourcePort:       srcPortId,
+		SourceChannel:    srcChanId,
+		Token:            amount,
+		Sender:           acc,
+		Receiver:         dstAddr,
 		TimeoutTimestamp: timeoutTimestamp,
 	}
+	// a packet without a timeout height must carry the zero height, a height of zero in the revision of
+	// dst would have it timed out right away
+	if timeoutHeight > 0 {
+		msg.TimeoutHeight = clienttypes.NewHeight(clienttypes.ParseChainID(dstChainId), timeoutHeight)
+	}
 
 	return NewCosmosMessage(msg), nil
 }
 
+// MsgSend constructs a bank MsgSend of amount from the key of the provider to dstAddr
+func (cc *CosmosProvider) MsgSend(dstAddr string, amount sdk.Coins) (provider.RelayerMessage, error) {
+	acc, err := cc.Address()
+	if err != nil {
+		return nil, err
+	}
+	msg := &bankTypes.MsgSend{
+		FromAddress: acc,
+		ToAddress:   dstAddr,
+		Amount:      amount,
+	}
+	return NewCosmosMessage(msg), nil
+}
+
+// MsgGrantAllowance constructs a MsgGrantAllowance from the key of the provider to grantee, allowing it to
+// spend up to spendLimit on fees until expiration. A nil spendLimit or expiration leaves it unbounded.
+func (cc *CosmosProvider) MsgGrantAllowance(grantee string, spendLimit sdk.Coins, expiration *time.Time) (provider.RelayerMessage, error) {
+	granter, err := cc.Address()
+	if err != nil {
+		return nil, err
+	}
+	allowance, err := codectypes.NewAnyWithValue(&feegrant.BasicAllowance{
+		SpendLimit: spendLimit,
+		Expiration: expiration,
+	})
+	if err != nil {
+		return nil, err
+	}
+	msg := &feegrant.MsgGrantAllowance{
+		Granter:   granter,
+		Grantee:   grantee,
+		Allowance: allowance,
+	}
+	return NewCosmosMessage(msg), nil
+}
+
+// MsgRevokeAllowance constructs a MsgRevokeAllowance of the fee allowance the key of the provider granted to grantee
+func (cc *CosmosProvider) MsgRevokeAllowance(grantee string) (provider.RelayerMessage, error) {
+	granter, err := cc.Address()
+	if err != nil {
+		return nil, err
+	}
+	msg := &feegrant.MsgRevokeAllowance{
+		Granter: granter,
+		Grantee: grantee,
+	}
+	return NewCosmosMessage(msg), nil
+}
+
 // MsgRelayTimeout constructs the MsgTimeout which is to be sent to the sending chain.
 // The counterparty represents the receiving chain where the receipts would have been
 // stored.
@@ -1056,6 +1274,9 @@ func relayPacketsFromResultTx(src, dst provider.ChainProvider, dsth int64, res *
 			// If the packet has a timeout height, and it has been reached, return a timeout packet
 			case !rp.timeout.IsZero() && block.GetHeight().GTE(rp.timeout):
 				timeoutPackets = append(timeoutPackets, rp.timeoutPacket())
+			// Likewise if the packet has a timeout timestamp that the block time has reached
+			case rp.timeoutStamp != 0 && timeoutStampReached(block, rp.timeoutStamp):
+				timeoutPackets = append(timeoutPackets, rp.timeoutPacket())
 			// If the packet matches the relay constraints relay it as a MsgReceivePacket
 			case !rp.pass:
 				rcvPackets = append(rcvPackets, rp)
@@ -1071,6 +1292,15 @@ func relayPacketsFromResultTx(src, dst provider.ChainProvider, dsth int64, res *
 	return nil, nil, fmt.Errorf("no packet data found")
 }
 
+// timeoutStampReached returns true if the time of a tendermint header is at or past the timeout timestamp
+func timeoutStampReached(header ibcexported.Header, timeoutStamp uint64) bool {
+	h, ok := header.(*tmclient.Header)
+	if !ok || h.Header == nil {
+		return false
+	}
+	return uint64(h.GetTime().UnixNano()) >= timeoutStamp
+}
+
 // acknowledgementsFromResultTx looks through the events in a *ctypes.ResultTx and returns
 // relayPackets with the appropriate data
 func acknowledgementsFromResultTx(dstChanId, dstPortId, srcChanId, srcPortId string, res *ctypes.ResultTx) ([]provider.RelayPacket, error) {
@@ -1470,29 +1700,256 @@ func (cc *CosmosProvider) SendMessage(msg provider.RelayerMessage) (*provider.Re
 // of that transaction will be logged. A boolean indicating if a transaction was successfully
 // sent and executed successfully is returned.
 func (cc *CosmosProvider) SendMessages(msgs []provider.RelayerMessage) (*provider.RelayerTxResponse, bool, error) {
+	txHash, err := cc.BroadcastMessages(msgs, cc.NextKey(), 0, 1)
+	if err != nil {
+		return nil, false, err
+	}
+	return cc.waitForTx(txHash, defaultTxCommitTimeout, msgs)
+}
+
+// BroadcastMessages signs and encodes a slice of RelayerMessages and broadcasts the resulting transaction,
+// returning its hash once it passed CheckTx without waiting for it to be committed. The transaction is
+// given the gas limit estimated by SimulateMessages, or simulated again if gas is zero. The configured gas
+// prices are multiplied by feeBump, so a resubmitted transaction can outbid the one it replaces.
+// Transactions are signed with the named key, the key of the provider if it is empty, and its locally
+// cached account sequence, so several of them can be in the mempool at once. A transaction rejected for
+// an account sequence mismatch is signed again once with the sequence the node expects.
+func (cc *CosmosProvider) BroadcastMessages(msgs []provider.RelayerMessage, key string, gas uint64, feeBump float64) (string, error) {
+	key, msgs, err := cc.signingKey(msgs, key)
+	if err != nil {
+		return "", err
+	}
+
+	acc := cc.sequences.account(key)
+	acc.mu.Lock()
+	defer acc.mu.Unlock()
+
+	for attempt := 0; ; attempt++ {
+		txBytes, seq, err := cc.signMessages(msgs, gas, feeBump, key, acc)
+		switch {
+		case errors.Is(err, errSequenceMismatch) && attempt == 0:
+			continue
+		case err != nil:
+			return "", err
+		}
+
+		txHash := fmt.Sprintf("%X", tmtypes.Tx(txBytes).Hash())
+		res, err := cc.RPCClient.BroadcastTxSync(context.Background(), txBytes)
+		switch {
+		case err != nil && strings.Contains(err.Error(), "tx already exists in cache"):
+			// the same tx was broadcast before and is still in the mempool
+			acc.accepted(seq)
+			return txHash, nil
+		case err != nil:
+			// the tx may or may not have reached the mempool
+			acc.synced = false
+			return "", err
+		case isSequenceMismatch(res.Codespace, res.Code) && attempt == 0:
+			acc.resync(res.Log)
+			continue
+		case res.Code != 0:
+			if isSequenceMismatch(res.Codespace, res.Code) {
+				acc.resync(res.Log)
+			}
+			return "", fmt.Errorf("transaction failed check with code: %d: %s", res.Code, res.Log)
+		}
+		acc.accepted(seq)
+		return txHash, nil
+	}
+}
+
+// SimulateMessages simulates a transaction of msgs as BroadcastMessages would sign it with the named key,
+// and returns the gas it is estimated to use, raised by the gas adjustment, along with the fee it would
+// pay at the configured gas prices. Nothing is signed or broadcast. Like the transactions it estimates,
+// the simulation uses the sequence that follows the transactions of the key waiting in the mempool.
+func (cc *CosmosProvider) SimulateMessages(msgs []provider.RelayerMessage, key string) (uint64, sdk.Coins, error) {
+	key, msgs, err := cc.signingKey(msgs, key)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	txf, err := cc.prepareFactory(cc.TxFactory(), key)
+	if err != nil {
+		return 0, nil, err
+	}
+	acc := cc.sequences.account(key)
+	acc.mu.Lock()
+	txf = txf.WithSequence(acc.sequence(txf.Sequence()))
+	acc.mu.Unlock()
+
+	_, adjusted, err := cc.CalculateGas(txf, CosmosMsgs(msgs...)...)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if !txf.Fees().Empty() {
+		return adjusted, txf.Fees(), nil
+	}
+	// the fee is rounded up the same way the sdk does when building the transaction
+	fee := sdk.NewCoins()
+	gas := sdk.NewDec(int64(adjusted))
+	for _, price := range txf.GasPrices() {
+		fee = fee.Add(sdk.NewCoin(price.Denom, price.Amount.Mul(gas).Ceil().RoundInt()))
+	}
+	return adjusted, fee, nil
+}
+
+// TxInMempool returns true if the transaction with the given hash is waiting in the mempool of the node.
+// A node neither pages through its unconfirmed transactions nor looks them up by hash, it lists the
+// oldest maxMempoolTxs of them. A transaction that waited for its commit timeout is usually among them, one
+// that is not found in a mempool holding more transactions than that is reported as present.
+func (cc *CosmosProvider) TxInMempool(txHash string) (bool, error) {
+	limit := maxMempoolTxs
+	res, err := cc.RPCClient.UnconfirmedTxs(context.Background(), &limit)
+	if err != nil {
+		return false, err
+	}
+	for _, tx := range res.Txs {
+		if strings.EqualFold(fmt.Sprintf("%X", tx.Hash()), txHash) {
+			return true, nil
+		}
+	}
+	return res.Total > res.Count, nil
+}
+
+// blockTime returns the average time between the latest blocks of the chain, which is how often the
+// txs waited for are looked up. It is measured once, or defaultBlockTime is returned until it can be.
+func (cc *CosmosProvider) blockTime() time.Duration {
+	if d := time.Duration(atomic.LoadInt64(&cc.measuredBlockTime)); d > 0 {
+		return d
+	}
+	ctx := context.Background()
+	status, err := cc.RPCClient.Status(ctx)
+	if err != nil {
+		return defaultBlockTime
+	}
+	latest := status.SyncInfo.LatestBlockHeight
+	if latest <= blockTimeSample {
+		return defaultBlockTime
+	}
+	res, err := cc.RPCClient.BlockchainInfo(ctx, latest-blockTimeSample, latest)
+	if err != nil || len(res.BlockMetas) < 2 {
+		return defaultBlockTime
+	}
+	// the block metas are listed from the newest to the oldest
+	newest, oldest := res.BlockMetas[0].Header, res.BlockMetas[len(res.BlockMetas)-1].Header
+	d := newest.Time.Sub(oldest.Time) / time.Duration(newest.Height-oldest.Height)
+	if d < minTxPollInterval {
+		d = minTxPollInterval
+	}
+	atomic.StoreInt64(&cc.measuredBlockTime, int64(d))
+	return d
+}
+
+// WaitForTx blocks until the transaction with the given hash is committed or the timeout passes
+func (cc *CosmosProvider) WaitForTx(txHash string, timeout time.Duration) (*provider.RelayerTxResponse, bool, error) {
+	return cc.waitForTx(txHash, timeout, nil)
+}
+
+// waitForTx waits for the tx to be committed and logs its outcome when msgs are given. Callers of
+// WaitForTx don't pass the msgs and log the outcome with their own context.
+func (cc *CosmosProvider) waitForTx(txHash string, timeout time.Duration, msgs []provider.RelayerMessage) (*provider.RelayerTxResponse, bool, error) {
+	hash, err := hex.DecodeString(txHash)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var (
+		resTx    *ctypes.ResultTx
+		deadline = time.After(timeout)
+		interval = cc.blockTime()
+	)
+	for resTx == nil {
+		select {
+		case <-time.After(interval):
+			resTx, _ = cc.RPCClient.Tx(context.Background(), hash, false)
+		case <-deadline:
+			return nil, false, fmt.Errorf("%w: %s not committed after %s", provider.ErrTxNotCommitted, txHash, timeout)
+		}
+	}
+
+	res := sdk.NewResponseResultTx(resTx, nil, "")
+
+	// Parse events and build a map where the key is event.Type+"."+attribute.Key
+	events := make(map[string]string, 1)
+	for _, logs := range res.Logs {
+		for _, ev := range logs.Events {
+			for _, attr := range ev.Attributes {
+				key := ev.Type + "." + attr.Key
+				events[key] = attr.Value
+			}
+		}
+	}
+
+	rlyRes := &provider.RelayerTxResponse{
+		Height:  res.Height,
+		TxHash:  res.TxHash,
+		Code:    res.Code,
+		Data:    res.Data,
+		GasUsed: res.GasUsed,
+		Fee:     cc.txFee(resTx),
+		Events:  events,
+	}
+
+	// transaction was executed, log the success or failure using the tx response code
+	// NOTE: error is nil, logic should use the returned error to determine if the
+	// transaction was successfully executed.
+	if rlyRes.Code != 0 {
+		if msgs != nil {
+			cc.LogFailedTx(rlyRes, err, msgs)
+		}
+		return rlyRes, false, fmt.Errorf("transaction failed with code: %d", res.Code)
+	}
+
+	if msgs != nil {
+		cc.LogSuccessTx(res, msgs)
+	}
+	return rlyRes, true, nil
+}
+
+// signMessages builds a transaction out of msgs, signs it with the named key and encodes it.
+// The transaction is signed with the next sequence of acc, which is returned alongside it, and the
+// given gas limit. Without one it is simulated first. Simulations run on top of the mempool, so they
+// too must use the sequence that follows the transactions of the key still waiting there.
+func (cc *CosmosProvider) signMessages(msgs []provider.RelayerMessage, gas uint64, feeBump float64, key string, acc *accountSequence) ([]byte, uint64, error) {
 	var (
 		txb     client.TxBuilder
 		txBytes []byte
-		res     *sdk.TxResponse
 	)
 
 	// Query account details
-	txf, err := cc.PrepareFactory(cc.TxFactory())
+	txf, err := cc.prepareFactory(cc.TxFactory(), key)
 	if err != nil {
-		return nil, false, err
+		return nil, 0, err
 	}
 
+	if feeBump > 1 {
+		gasPrices, err := cc.bumpedGasPrices(feeBump)
+		if err != nil {
+			return nil, 0, err
+		}
+		txf = txf.WithGasPrices(gasPrices)
+	}
+
+	// Set the cached account sequence on the transaction factory
+	seq := acc.sequence(txf.Sequence())
+	txf = txf.WithSequence(seq)
+
 	// TODO: Make this work with new CalculateGas method
 	// TODO: This is related to GRPC client stuff?
 	// https://github.com/cosmos/cosmos-sdk/blob/5725659684fc93790a63981c653feee33ecf3225/client/tx/tx.go#L297
 	// If users pass gas adjustment, then calculate gas
-	_, adjusted, err := cc.CalculateGas(txf, CosmosMsgs(msgs...)...)
-	if err != nil {
-		return nil, false, err
+	if gas == 0 {
+		_, gas, err = cc.CalculateGas(txf, CosmosMsgs(msgs...)...)
+		if err != nil {
+			if strings.Contains(err.Error(), "account sequence mismatch") {
+				acc.resync(err.Error())
+				return nil, 0, fmt.Errorf("%w: %s", errSequenceMismatch, err)
+			}
+			return nil, 0, err
+		}
 	}
-
-	// Set the gas amount on the transaction factory
-	txf = txf.WithGas(adjusted)
+	txf = txf.WithGas(gas)
 
 	// Build the transaction builder & retry on failures
 	if err = retry.Do(func() error {
@@ -1502,7 +1959,11 @@ func (cc *CosmosProvider) SendMessages(msgs []provider.RelayerMessage) (*provide
 		}
 		return err
 	}, RtyAtt, RtyDel, RtyErr); err != nil {
-		return nil, false, err
+		return nil, 0, err
+	}
+
+	if err = cc.setFeeGranter(txb); err != nil {
+		return nil, 0, err
 	}
 
 	// Attach the signature to the transaction
@@ -1514,12 +1975,12 @@ func (cc *CosmosProvider) SendMessages(msgs []provider.RelayerMessage) (*provide
 	done := cc.SetSDKContext()
 
 	if err = retry.Do(func() error {
-		if err = tx.Sign(txf, cc.PCfg.Key, txb, false); err != nil {
+		if err = tx.Sign(txf, key, txb, false); err != nil {
 			return err
 		}
 		return err
 	}, RtyAtt, RtyDel, RtyErr); err != nil {
-		return nil, false, err
+		return nil, 0, err
 	}
 
 	done()
@@ -1532,41 +1993,8 @@ func (cc *CosmosProvider) SendMessages(msgs []provider.RelayerMessage) (*provide
 		}
 		return err
 	}, RtyAtt, RtyDel, RtyErr); err != nil {
-		return nil, false, err
-	}
-
-	res, err = cc.BroadcastTx(context.Background(), txBytes)
-	if err != nil || res == nil {
-		return nil, false, err
-	}
-
-	// Parse events and build a map where the key is event.Type+"."+attribute.Key
-	events := make(map[string]string, 1)
-	for _, logs := range res.Logs {
-		for _, ev := range logs.Events {
-			for _, attr := range ev.Attributes {
-				key := ev.Type + "." + attr.Key
-				events[key] = attr.Value
-			}
-		}
-	}
-
-	rlyRes := &provider.RelayerTxResponse{
-		Height: res.Height,
-		TxHash: res.TxHash,
-		Code:   res.Code,
-		Data:   res.Data,
-		Events: events,
-	}
-
-	// transaction was executed, log the success or failure using the tx response code
-	// NOTE: error is nil, logic should use the returned error to determine if the
-	// transaction was successfully executed.
-	if rlyRes.Code != 0 {
-		cc.LogFailedTx(rlyRes, err, msgs)
-		return rlyRes, false, fmt.Errorf("transaction failed with code: %d", res.Code)
+		return nil, 0, err
 	}
 
-	cc.LogSuccessTx(res, msgs)
-	return rlyRes, true, nil
+	return txBytes, seq, nil
 }
@@ -0,0 +1,60 @@
+package cosmos
+
+import (
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	lens "github.com/strangelove-ventures/lens/client"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestProvider returns a provider of the cosmos chain ibc-0 that is not connected to a node
+func newTestProvider(t *testing.T) *CosmosProvider {
+	t.Helper()
+	cc := &CosmosProvider{PCfg: CosmosProviderConfig{ChainID: "ibc-0", AccountPrefix: "cosmos", Key: "relayer"}}
+	cc.Config = ChainClientConfig(&cc.PCfg)
+	cc.Codec = lens.MakeCodec(cc.Config.Modules)
+	return cc
+}
+
+// testAddress returns the address of the given bytes on the cosmos chain of cc
+func testAddress(t *testing.T, cc *CosmosProvider, b byte) (sdk.AccAddress, string) {
+	t.Helper()
+	addr := sdk.AccAddress(append(make([]byte, 19), b))
+	bech, err := cc.EncodeBech32AccAddr(addr)
+	require.NoError(t, err)
+	return addr, bech
+}
+
+func TestSetFeeGranter(t *testing.T) {
+	cc := newTestProvider(t)
+	from, _ := testAddress(t, cc, 1)
+	granter, granterBech := testAddress(t, cc, 2)
+
+	build := func() sdk.FeeTx {
+		txb := cc.Codec.TxConfig.NewTxBuilder()
+		require.NoError(t, txb.SetMsgs(banktypes.NewMsgSend(from, from, sdk.NewCoins(sdk.NewInt64Coin("stake", 1)))))
+		require.NoError(t, cc.setFeeGranter(txb))
+		return txb.GetTx()
+	}
+
+	// without a granter the signing key pays the fees
+	require.Empty(t, build().FeeGranter())
+
+	cc.SetFeeGranter(granterBech)
+	require.Equal(t, granterBech, cc.FeeGranter())
+	feeTx := build()
+	require.Equal(t, granter, feeTx.FeeGranter())
+	require.Equal(t, from, feeTx.FeePayer())
+
+	// the granter survives the encoding of the tx
+	bz, err := cc.Codec.TxConfig.TxEncoder()(feeTx)
+	require.NoError(t, err)
+	decoded, err := cc.Codec.TxConfig.TxDecoder()(bz)
+	require.NoError(t, err)
+	require.Equal(t, granter, decoded.(sdk.FeeTx).FeeGranter())
+
+	cc.SetFeeGranter("osmo1invalid")
+	require.Error(t, cc.setFeeGranter(cc.Codec.TxConfig.NewTxBuilder()))
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/binary"
 	"encoding/hex"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
@@ -25,6 +26,7 @@ import (
 	ibcexported "github.com/cosmos/ibc-go/v2/modules/core/exported"
 	ibctmtypes "github.com/cosmos/ibc-go/v2/modules/light-clients/07-tendermint/types"
 	tmclient "github.com/cosmos/ibc-go/v2/modules/light-clients/07-tendermint/types"
+	"github.com/cosmos/relayer/relayer/provider"
 	"github.com/pkg/errors"
 	abci "github.com/tendermint/tendermint/abci/types"
 	"github.com/tendermint/tendermint/light"
@@ -383,17 +385,24 @@ func (cc *CosmosProvider) QueryConsensusState(height int64) (ibcexported.Consens
 	return state, height, nil
 }
 
-// QueryClients queries all the clients!
-// TODO add pagination support
+// QueryClients queries all the clients, following the pages of the response until the last one
 func (cc *CosmosProvider) QueryClients() (clienttypes.IdentifiedClientStates, error) {
 	qc := clienttypes.NewQueryClient(cc)
-	state, err := qc.ClientStates(context.Background(), &clienttypes.QueryClientStatesRequest{
-		Pagination: DefaultPageRequest(),
-	})
-	if err != nil {
-		return nil, err
+	p := DefaultPageRequest()
+	var clients clienttypes.IdentifiedClientStates
+	for {
+		res, err := qc.ClientStates(context.Background(), &clienttypes.QueryClientStatesRequest{
+			Pagination: p,
+		})
+		if err != nil {
+			return nil, err
+		}
+		clients = append(clients, res.ClientStates...)
+		if res.Pagination == nil || len(res.Pagination.NextKey) == 0 {
+			return clients, nil
+		}
+		p = &querytypes.PageRequest{Key: res.Pagination.NextKey, Limit: p.Limit}
 	}
-	return state.ClientStates, nil
 }
 
 // QueryConnection returns the remote end of a given connection
@@ -742,6 +751,53 @@ func (cc *CosmosProvider) QueryPacketReceipt(height int64, channelid, portid str
 	}, nil
 }
 
+// QuerySendPacket returns the packet sent on a channel with the given sequence, as found in the send_packet
+// event of the transaction that sent it
+func (cc *CosmosProvider) QuerySendPacket(channelid, portid string, seq uint64) (provider.RelayPacket, error) {
+	txs, err := cc.QueryTxs(1, 1000, rcvPacketQuery(channelid, int(seq)))
+	switch {
+	case err != nil:
+		return nil, err
+	case len(txs) == 0:
+		return nil, fmt.Errorf("no transactions returned with query")
+	}
+
+	for _, tx := range txs {
+		for _, e := range tx.TxResult.Events {
+			if e.Type != spTag {
+				continue
+			}
+			rp := &relayMsgRecvPacket{}
+			var chanID, portID string
+			for _, p := range e.Attributes {
+				switch string(p.Key) {
+				case srcChanTag:
+					chanID = string(p.Value)
+				case srcPortTag:
+					portID = string(p.Value)
+				case dataTag:
+					rp.packetData = p.Value
+				case toHeightTag:
+					timeout, err := clienttypes.ParseHeight(string(p.Value))
+					if err != nil {
+						return nil, err
+					}
+					rp.timeout = timeout
+				case toTSTag:
+					rp.timeoutStamp, _ = strconv.ParseUint(string(p.Value), 10, 64)
+				case seqTag:
+					rp.seq, _ = strconv.ParseUint(string(p.Value), 10, 64)
+				}
+			}
+			if chanID == channelid && portID == portid && rp.seq == seq {
+				return rp, nil
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("no send_packet event found for [%s]chan{%s}port{%s} seq(%d)", cc.ChainId(), channelid, portid, seq)
+}
+
 func (cc *CosmosProvider) QueryLatestHeight() (int64, error) {
 	stat, err := cc.RPCClient.Status(context.Background())
 	if err != nil {
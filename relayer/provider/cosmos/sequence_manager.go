@@ -0,0 +1,77 @@
+package cosmos
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"sync"
+
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+)
+
+// errSequenceMismatch is returned when a tx is simulated with a sequence the node does not expect
+var errSequenceMismatch = errors.New("account sequence mismatch")
+
+// expectedSequence matches the sequence the chain expects in an account sequence mismatch error
+var expectedSequence = regexp.MustCompile(`account sequence mismatch, expected (\d+)`)
+
+// sequenceManager caches the account sequences of the keys a provider signs with, so a tx can be
+// signed and broadcast while the txs sent before it are still waiting in the mempool
+type sequenceManager struct {
+	mu       sync.Mutex
+	accounts map[string]*accountSequence
+}
+
+// accountSequence is the sequence that the next tx of a key is signed with. Its lock is held from
+// signing a tx until it passed CheckTx, so the txs of a key enter the mempool in sequence order.
+type accountSequence struct {
+	mu     sync.Mutex
+	next   uint64
+	synced bool
+}
+
+// account returns the sequence of the named key, creating it unsynced on first use
+func (m *sequenceManager) account(key string) *accountSequence {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.accounts == nil {
+		m.accounts = make(map[string]*accountSequence)
+	}
+	acc, ok := m.accounts[key]
+	if !ok {
+		acc = &accountSequence{}
+		m.accounts[key] = acc
+	}
+	return acc
+}
+
+// sequence returns the sequence to sign the next tx with, given the sequence of the account in the last
+// committed state. A cached sequence behind the committed one means the key was used elsewhere, in which
+// case the account is synced to the chain again.
+func (a *accountSequence) sequence(committed uint64) uint64 {
+	if !a.synced || a.next < committed {
+		a.next, a.synced = committed, true
+	}
+	return a.next
+}
+
+// accepted advances the account past a tx signed with seq that passed CheckTx
+func (a *accountSequence) accepted(seq uint64) {
+	a.next, a.synced = seq+1, true
+}
+
+// resync handles a tx rejected with an account sequence mismatch. The sequence expected by the mempool
+// is taken from the error log if it is there, otherwise the account is synced on the next tx.
+func (a *accountSequence) resync(log string) {
+	a.synced = false
+	if m := expectedSequence.FindStringSubmatch(log); m != nil {
+		if seq, err := strconv.ParseUint(m[1], 10, 64); err == nil {
+			a.next, a.synced = seq, true
+		}
+	}
+}
+
+// isSequenceMismatch returns true if a CheckTx response code is an account sequence mismatch
+func isSequenceMismatch(codespace string, code uint32) bool {
+	return codespace == sdkerrors.RootCodespace && code == sdkerrors.ErrWrongSequence.ABCICode()
+}
@@ -0,0 +1,146 @@
+package cosmos
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	"github.com/cosmos/cosmos-sdk/crypto/hd"
+	"github.com/cosmos/cosmos-sdk/crypto/keyring"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	txtypes "github.com/cosmos/cosmos-sdk/types/tx"
+	authsigning "github.com/cosmos/cosmos-sdk/x/auth/signing"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/cosmos/relayer/relayer/provider"
+	lens "github.com/strangelove-ventures/lens/client"
+	"github.com/stretchr/testify/require"
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/libs/bytes"
+	rpcclient "github.com/tendermint/tendermint/rpc/client"
+	ctypes "github.com/tendermint/tendermint/rpc/core/types"
+	tmtypes "github.com/tendermint/tendermint/types"
+)
+
+// mempoolNode is a node that never commits a block. Like a node of an sdk chain, it checks the sequence
+// of simulated and broadcast txs against its committed state with the txs of its mempool applied.
+type mempoolNode struct {
+	rpcclient.Client
+	cdc lens.Codec
+
+	mu      sync.Mutex
+	account *authtypes.BaseAccount
+	mempool int
+}
+
+func (n *mempoolNode) ABCIQueryWithOptions(_ context.Context, path string, data bytes.HexBytes, _ rpcclient.ABCIQueryOptions) (*ctypes.ResultABCIQuery, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	switch path {
+	case "/cosmos.auth.v1beta1.Query/Account":
+		acc, err := codectypes.NewAnyWithValue(n.account)
+		if err != nil {
+			return nil, err
+		}
+		bz, err := n.cdc.Marshaler.Marshal(&authtypes.QueryAccountResponse{Account: acc})
+		if err != nil {
+			return nil, err
+		}
+		return &ctypes.ResultABCIQuery{Response: abci.ResponseQuery{Value: bz, Height: 1}}, nil
+	case "/cosmos.tx.v1beta1.Service/Simulate":
+		var req txtypes.SimulateRequest
+		if err := req.Unmarshal(data); err != nil {
+			return nil, err
+		}
+		if res := n.checkSequence(req.Tx.AuthInfo.SignerInfos[0].Sequence); res.Code != 0 {
+			return &ctypes.ResultABCIQuery{Response: abci.ResponseQuery{Code: res.Code, Log: res.Log}}, nil
+		}
+		bz, err := (&txtypes.SimulateResponse{GasInfo: &sdk.GasInfo{GasUsed: 50000}}).Marshal()
+		if err != nil {
+			return nil, err
+		}
+		return &ctypes.ResultABCIQuery{Response: abci.ResponseQuery{Value: bz}}, nil
+	}
+	return nil, fmt.Errorf("unexpected query %s", path)
+}
+
+func (n *mempoolNode) BroadcastTxSync(_ context.Context, tx tmtypes.Tx) (*ctypes.ResultBroadcastTx, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	res := n.checkTx(tx)
+	if res.Code == 0 {
+		n.mempool++
+	}
+	return res, nil
+}
+
+// checkTx checks the sequence of tx against the sequence of the account after the txs of the mempool
+func (n *mempoolNode) checkTx(bz []byte) *ctypes.ResultBroadcastTx {
+	tx, err := n.cdc.TxConfig.TxDecoder()(bz)
+	if err != nil {
+		return &ctypes.ResultBroadcastTx{Code: sdkerrors.ErrTxDecode.ABCICode(), Log: err.Error()}
+	}
+	sigs, err := tx.(authsigning.SigVerifiableTx).GetSignaturesV2()
+	if err != nil {
+		return &ctypes.ResultBroadcastTx{Code: sdkerrors.ErrTxDecode.ABCICode(), Log: err.Error()}
+	}
+	return n.checkSequence(sigs[0].Sequence)
+}
+
+func (n *mempoolNode) checkSequence(seq uint64) *ctypes.ResultBroadcastTx {
+	expected := n.account.Sequence + uint64(n.mempool)
+	if seq != expected {
+		return &ctypes.ResultBroadcastTx{
+			Codespace: sdkerrors.RootCodespace,
+			Code:      sdkerrors.ErrWrongSequence.ABCICode(),
+			Log:       fmt.Sprintf("account sequence mismatch, expected %d, got %d: incorrect account sequence", expected, seq),
+		}
+	}
+	return &ctypes.ResultBroadcastTx{}
+}
+
+func TestBroadcastMessagesInFlight(t *testing.T) {
+	cdc := lens.MakeCodec(lens.ModuleBasics)
+	kr := keyring.NewInMemory()
+	info, _, err := kr.NewMnemonic("relayer", keyring.English, hd.CreateHDPath(118, 0, 0).String(), "", hd.Secp256k1)
+	require.NoError(t, err)
+
+	node := &mempoolNode{cdc: cdc, account: authtypes.NewBaseAccount(info.GetAddress(), info.GetPubKey(), 1, 3)}
+	cc := &CosmosProvider{
+		ChainClient: lens.ChainClient{
+			Config: &lens.ChainClientConfig{
+				ChainID:       "ibc-0",
+				AccountPrefix: "cosmos",
+				GasAdjustment: 1.5,
+				GasPrices:     "0.01stake",
+				SignModeStr:   "direct",
+			},
+			Keybase:   kr,
+			RPCClient: node,
+			Codec:     cdc,
+		},
+		PCfg: CosmosProviderConfig{Key: "relayer", ChainID: "ibc-0"},
+	}
+
+	addr := info.GetAddress()
+	msg := NewCosmosMessage(banktypes.NewMsgSend(addr, addr, sdk.NewCoins(sdk.NewInt64Coin("stake", 1))))
+
+	// the second tx is simulated and signed on top of the first one, still waiting in the mempool
+	for i := 0; i < 2; i++ {
+		_, err = cc.BroadcastMessages([]provider.RelayerMessage{msg}, "", 0, 1)
+		require.NoError(t, err)
+	}
+	require.Equal(t, 2, node.mempool)
+
+	// a cached sequence the node no longer expects is resynced from the failed simulation
+	node.mu.Lock()
+	node.mempool = 0
+	node.mu.Unlock()
+	_, err = cc.BroadcastMessages([]provider.RelayerMessage{msg}, "", 0, 1)
+	require.NoError(t, err)
+	require.Equal(t, 1, node.mempool)
+}
@@ -0,0 +1,443 @@
+package cosmos
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	cryptocodec "github.com/cosmos/cosmos-sdk/crypto/codec"
+	"github.com/cosmos/cosmos-sdk/crypto/hd"
+	"github.com/cosmos/cosmos-sdk/crypto/keyring"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/relayer/relayer/provider"
+)
+
+var (
+	_ provider.Signer = &HTTPSigner{}
+	_ provider.Signer = &KeyringSigner{}
+
+	// errSignerKeys is returned when the private keys of a chain with a signer are to be added or read
+	errSignerKeys = errors.New("the private keys of the chain are held by its signer")
+
+	// signerCodec encodes the public keys exchanged with a signer
+	signerCodec = func() *codec.ProtoCodec {
+		registry := codectypes.NewInterfaceRegistry()
+		cryptocodec.RegisterInterfaces(registry)
+		return codec.NewProtoCodec(registry)
+	}()
+)
+
+// SignerConfig selects the remote signer that holds the keys of a chain in place of its keyring. The
+// signer is reached over HTTP at URL and serves the requests described by NewSignerHandler. Requests
+// are authenticated with Token, sent as a bearer token, or with the client certificate in CertFile and
+// KeyFile when the signer is served over https. CAFile is the CA that the certificate of such a signer is
+// verified with, if the host does not trust it already.
+type SignerConfig struct {
+	URL      string `json:"url" yaml:"url"`
+	Token    string `json:"token,omitempty" yaml:"token,omitempty"`
+	CertFile string `json:"cert-file,omitempty" yaml:"cert-file,omitempty"`
+	KeyFile  string `json:"key-file,omitempty" yaml:"key-file,omitempty"`
+	CAFile   string `json:"ca-file,omitempty" yaml:"ca-file,omitempty"`
+}
+
+// Validate checks that the url of the signer is an http or https url, and that requests to a signer
+// off the host are authenticated and do not send their token in the clear
+func (sc SignerConfig) Validate() error {
+	u, err := url.Parse(sc.URL)
+	if err != nil {
+		return fmt.Errorf("invalid signer url %s: %w", sc.URL, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("signer url %s must be an http or https url", sc.URL)
+	}
+	if (sc.CertFile == "") != (sc.KeyFile == "") {
+		return errors.New("signer cert-file and key-file must be set together")
+	}
+	if u.Scheme != "https" && (sc.CertFile != "" || sc.CAFile != "") {
+		return fmt.Errorf("signer url %s must be an https url to use certificates", sc.URL)
+	}
+	if IsLoopback(u.Hostname()) {
+		return nil
+	}
+	if sc.Token == "" && sc.CertFile == "" {
+		return fmt.Errorf("signer %s is not on this host and needs a token or a client certificate", sc.URL)
+	}
+	if sc.Token != "" && u.Scheme != "https" {
+		return fmt.Errorf("signer url %s must be an https url to send it a token", sc.URL)
+	}
+	return nil
+}
+
+// IsLoopback returns true if host is the name or an address of the loopback interface
+func IsLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// SignerAuth is how a signer served by NewSignerHandler authenticates requests: with a bearer token,
+// with the client certificates verified by the TLS listener it is served on, or both
+type SignerAuth struct {
+	Token       string
+	ClientCerts bool
+}
+
+// Enabled returns true if requests are authenticated at all
+func (a SignerAuth) Enabled() bool {
+	return a.Token != "" || a.ClientCerts
+}
+
+// authenticate returns an error unless r carries the credentials required by a
+func (a SignerAuth) authenticate(r *http.Request) error {
+	if a.ClientCerts && (r.TLS == nil || len(r.TLS.VerifiedChains) == 0) {
+		return errors.New("client certificate required")
+	}
+	if a.Token != "" {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(token), []byte(a.Token)) != 1 {
+			return errors.New("invalid token")
+		}
+	}
+	return nil
+}
+
+// signRequest and signResponse are the body of a request to sign with a signer and of its response
+type signRequest struct {
+	Key string `json:"key"`
+	Msg []byte `json:"msg"`
+}
+
+type signResponse struct {
+	Signature []byte `json:"signature"`
+}
+
+// signerError is the body of the response to a request that a signer failed
+type signerError struct {
+	Error string `json:"error"`
+}
+
+// HTTPSigner is a remote signer reached over HTTP
+type HTTPSigner struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+// NewHTTPSigner returns a signer served by NewSignerHandler, or by any server of the same requests, as
+// configured by sc
+func NewHTTPSigner(sc SignerConfig, timeout time.Duration) (*HTTPSigner, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if sc.CertFile != "" || sc.CAFile != "" {
+		tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+		if sc.CertFile != "" {
+			cert, err := tls.LoadX509KeyPair(sc.CertFile, sc.KeyFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load signer client certificate: %w", err)
+			}
+			tlsConfig.Certificates = []tls.Certificate{cert}
+		}
+		if sc.CAFile != "" {
+			pool, err := LoadCertPool(sc.CAFile)
+			if err != nil {
+				return nil, err
+			}
+			tlsConfig.RootCAs = pool
+		}
+		transport.TLSClientConfig = tlsConfig
+	}
+	return &HTTPSigner{
+		url:    strings.TrimSuffix(sc.URL, "/"),
+		token:  sc.Token,
+		client: &http.Client{Timeout: timeout, Transport: transport},
+	}, nil
+}
+
+// LoadCertPool returns a pool of the PEM encoded certificates in file
+func LoadCertPool(file string) (*x509.CertPool, error) {
+	bz, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(bz) {
+		return nil, fmt.Errorf("no certificates found in %s", file)
+	}
+	return pool, nil
+}
+
+func (s *HTTPSigner) PubKey(key string) (cryptotypes.PubKey, error) {
+	bz, err := s.do(http.NewRequest(http.MethodGet, s.url+"/pubkey/"+url.PathEscape(key), nil))
+	if err != nil {
+		return nil, err
+	}
+	var pub cryptotypes.PubKey
+	if err = signerCodec.UnmarshalInterfaceJSON(bz, &pub); err != nil {
+		return nil, fmt.Errorf("invalid public key from signer: %w", err)
+	}
+	return pub, nil
+}
+
+func (s *HTTPSigner) Sign(key string, msg []byte) ([]byte, error) {
+	body, err := json.Marshal(signRequest{Key: key, Msg: msg})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(http.MethodPost, s.url+"/sign", bytes.NewReader(body))
+	if err == nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	bz, err := s.do(req, err)
+	if err != nil {
+		return nil, err
+	}
+	var res signResponse
+	if err = json.Unmarshal(bz, &res); err != nil {
+		return nil, fmt.Errorf("invalid signature from signer: %w", err)
+	}
+	return res.Signature, nil
+}
+
+// do sends req to the signer and returns the body of its response, or the error the signer reported
+func (s *HTTPSigner) do(req *http.Request, err error) ([]byte, error) {
+	if err != nil {
+		return nil, err
+	}
+	if s.token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+	res, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("signer unreachable: %w", err)
+	}
+	defer res.Body.Close()
+	bz, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode != http.StatusOK {
+		var serr signerError
+		if json.Unmarshal(bz, &serr) != nil || serr.Error == "" {
+			serr.Error = res.Status
+		}
+		return nil, fmt.Errorf("signer failed: %s", serr.Error)
+	}
+	return bz, nil
+}
+
+// NewSignerHandler serves the keys of signer over HTTP, for HTTPSigners to reach them:
+//
+//	GET /pubkey/{key} returns the public key of the named key as JSON
+//	POST /sign with {"key": "name", "msg": "base64"} returns {"signature": "base64"}
+//
+// Requests without the credentials required by auth are refused. A failed request is answered with
+// {"error": "reason"}.
+func NewSignerHandler(signer provider.Signer, auth SignerAuth) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/pubkey/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeSignerError(w, http.StatusMethodNotAllowed, errors.New("use GET"))
+			return
+		}
+		pub, err := signer.PubKey(strings.TrimPrefix(r.URL.Path, "/pubkey/"))
+		if err != nil {
+			writeSignerError(w, http.StatusNotFound, err)
+			return
+		}
+		bz, err := signerCodec.MarshalInterfaceJSON(pub)
+		if err != nil {
+			writeSignerError(w, http.StatusInternalServerError, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(bz)
+	})
+	mux.HandleFunc("/sign", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			writeSignerError(w, http.StatusMethodNotAllowed, errors.New("use POST"))
+			return
+		}
+		var req signRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeSignerError(w, http.StatusBadRequest, err)
+			return
+		}
+		sig, err := signer.Sign(req.Key, req.Msg)
+		if err != nil {
+			writeSignerError(w, http.StatusBadRequest, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(signResponse{Signature: sig})
+	})
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := auth.authenticate(r); err != nil {
+			writeSignerError(w, http.StatusUnauthorized, err)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+func writeSignerError(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(signerError{Error: err.Error()})
+}
+
+// KeyringSigner signs with the keys of a keyring. Served by NewSignerHandler, it stands in for a KMS
+// when testing a relayer configured with a signer.
+type KeyringSigner struct {
+	kr keyring.Keyring
+}
+
+func NewKeyringSigner(kr keyring.Keyring) *KeyringSigner {
+	return &KeyringSigner{kr: kr}
+}
+
+// LocalSigner returns a signer of the keys of the chain in the keyring of the relayer under homepath
+func (pc CosmosProviderConfig) LocalSigner(homepath string) (*KeyringSigner, error) {
+	kr, err := keyring.New(pc.ChainID, pc.KeyringBackend, path.Join(homepath, "keys", pc.ChainID), os.Stdin)
+	if err != nil {
+		return nil, err
+	}
+	return NewKeyringSigner(kr), nil
+}
+
+func (s *KeyringSigner) PubKey(key string) (cryptotypes.PubKey, error) {
+	info, err := s.kr.Key(key)
+	if err != nil {
+		return nil, err
+	}
+	return info.GetPubKey(), nil
+}
+
+func (s *KeyringSigner) Sign(key string, msg []byte) ([]byte, error) {
+	sig, _, err := s.kr.Sign(key, msg)
+	return sig, err
+}
+
+// signerKeyring is the keyring of a chain whose keys are held by a signer. It stores nothing but their
+// public keys, fetched from the signer when they are first used, and has the signer sign with them.
+// Private keys can not be added to it nor exported from it.
+type signerKeyring struct {
+	keyring.Keyring
+	signer provider.Signer
+
+	// keys are the names of the keys the chain is configured with, which are listed by List
+	keys []string
+	mu   sync.Mutex
+}
+
+func newSignerKeyring(signer provider.Signer, keys []string) *signerKeyring {
+	return &signerKeyring{Keyring: keyring.NewInMemory(), signer: signer, keys: keys}
+}
+
+func (k *signerKeyring) Key(uid string) (keyring.Info, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.key(uid)
+}
+
+func (k *signerKeyring) key(uid string) (keyring.Info, error) {
+	if info, err := k.Keyring.Key(uid); err == nil {
+		return info, nil
+	}
+	pub, err := k.signer.PubKey(uid)
+	if err != nil {
+		return nil, err
+	}
+	return k.Keyring.SavePubKey(uid, pub, hd.PubKeyType(pub.Type()))
+}
+
+// List returns the keys the chain is configured with that the signer holds
+func (k *signerKeyring) List() ([]keyring.Info, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	for _, key := range k.keys {
+		_, _ = k.key(key)
+	}
+	return k.Keyring.List()
+}
+
+func (k *signerKeyring) KeyByAddress(address sdk.Address) (keyring.Info, error) {
+	if _, err := k.List(); err != nil {
+		return nil, err
+	}
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.Keyring.KeyByAddress(address)
+}
+
+// Sign has the signer sign msg, and checks the signature against the public key of uid
+func (k *signerKeyring) Sign(uid string, msg []byte) ([]byte, cryptotypes.PubKey, error) {
+	info, err := k.Key(uid)
+	if err != nil {
+		return nil, nil, err
+	}
+	sig, err := k.signer.Sign(uid, msg)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !info.GetPubKey().VerifySignature(msg, sig) {
+		return nil, nil, fmt.Errorf("signer returned an invalid signature for key %s", uid)
+	}
+	return sig, info.GetPubKey(), nil
+}
+
+func (k *signerKeyring) SignByAddress(address sdk.Address, msg []byte) ([]byte, cryptotypes.PubKey, error) {
+	info, err := k.KeyByAddress(address)
+	if err != nil {
+		return nil, nil, err
+	}
+	return k.Sign(info.GetName(), msg)
+}
+
+func (k *signerKeyring) NewMnemonic(string, keyring.Language, string, string, keyring.SignatureAlgo) (keyring.Info, string, error) {
+	return nil, "", errSignerKeys
+}
+
+func (k *signerKeyring) NewAccount(string, string, string, string, keyring.SignatureAlgo) (keyring.Info, error) {
+	return nil, errSignerKeys
+}
+
+func (k *signerKeyring) SaveLedgerKey(string, keyring.SignatureAlgo, string, uint32, uint32, uint32) (keyring.Info, error) {
+	return nil, errSignerKeys
+}
+
+func (k *signerKeyring) ImportPrivKey(string, string, string) error {
+	return errSignerKeys
+}
+
+func (k *signerKeyring) ExportPrivKeyArmor(string, string) (string, error) {
+	return "", errSignerKeys
+}
+
+func (k *signerKeyring) ExportPrivKeyArmorByAddress(sdk.Address, string) (string, error) {
+	return "", errSignerKeys
+}
+
+func (k *signerKeyring) Delete(string) error {
+	return errSignerKeys
+}
+
+func (k *signerKeyring) DeleteByAddress(sdk.Address) error {
+	return errSignerKeys
+}
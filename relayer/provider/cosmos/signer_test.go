@@ -0,0 +1,123 @@
+package cosmos
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/client/tx"
+	"github.com/cosmos/cosmos-sdk/crypto/hd"
+	"github.com/cosmos/cosmos-sdk/crypto/keyring"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/tx/signing"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	lens "github.com/strangelove-ventures/lens/client"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestSigner serves a keyring holding the key "relayer" and returns the keyring of a chain that signs
+// through it, along with the key held by the signer
+func newTestSigner(t *testing.T) (*signerKeyring, keyring.Info) {
+	t.Helper()
+	kr := keyring.NewInMemory()
+	info, _, err := kr.NewMnemonic("relayer", keyring.English, hd.CreateHDPath(118, 0, 0).String(), "", hd.Secp256k1)
+	require.NoError(t, err)
+
+	srv := httptest.NewServer(NewSignerHandler(NewKeyringSigner(kr), SignerAuth{Token: "secret"}))
+	t.Cleanup(srv.Close)
+	signer, err := NewHTTPSigner(SignerConfig{URL: srv.URL, Token: "secret"}, time.Second)
+	require.NoError(t, err)
+
+	// requests without the token are refused
+	anon, err := NewHTTPSigner(SignerConfig{URL: srv.URL}, time.Second)
+	require.NoError(t, err)
+	_, err = anon.PubKey("relayer")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid token")
+	_, err = anon.Sign("relayer", []byte("relay"))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid token")
+
+	return newSignerKeyring(signer, []string{"relayer", "missing"}), info
+}
+
+func TestSignerConfigValidate(t *testing.T) {
+	for _, tc := range []struct {
+		sc    SignerConfig
+		valid bool
+	}{
+		{SignerConfig{URL: "http://localhost:7000"}, true},
+		{SignerConfig{URL: "http://127.0.0.1:7000"}, true},
+		{SignerConfig{URL: "http://signer:7000"}, false},
+		{SignerConfig{URL: "http://signer:7000", Token: "secret"}, false},
+		{SignerConfig{URL: "https://signer:7000", Token: "secret"}, true},
+		{SignerConfig{URL: "https://signer:7000", CertFile: "relayer.crt", KeyFile: "relayer.key"}, true},
+		{SignerConfig{URL: "https://signer:7000", CertFile: "relayer.crt"}, false},
+		{SignerConfig{URL: "grpc://signer:7000", Token: "secret"}, false},
+	} {
+		if tc.valid {
+			require.NoError(t, tc.sc.Validate(), tc.sc.URL)
+		} else {
+			require.Error(t, tc.sc.Validate(), tc.sc.URL)
+		}
+	}
+}
+
+func TestSignerKeyring(t *testing.T) {
+	kr, held := newTestSigner(t)
+
+	info, err := kr.Key("relayer")
+	require.NoError(t, err)
+	require.Equal(t, held.GetAddress(), info.GetAddress())
+	require.Equal(t, keyring.TypeOffline, info.GetType())
+
+	_, err = kr.Key("missing")
+	require.Error(t, err)
+	infos, err := kr.List()
+	require.NoError(t, err)
+	require.Len(t, infos, 1)
+
+	msg := []byte("relay")
+	sig, pub, err := kr.SignByAddress(held.GetAddress(), msg)
+	require.NoError(t, err)
+	require.True(t, pub.VerifySignature(msg, sig))
+
+	// the private keys never leave the signer
+	_, _, err = kr.NewMnemonic("other", keyring.English, hd.CreateHDPath(118, 0, 0).String(), "", hd.Secp256k1)
+	require.ErrorIs(t, err, errSignerKeys)
+	_, err = kr.ExportPrivKeyArmor("relayer", "")
+	require.ErrorIs(t, err, errSignerKeys)
+	_, err = kr.SaveLedgerKey("ledger", hd.Secp256k1, "cosmos", 118, 0, 0)
+	require.ErrorIs(t, err, errSignerKeys)
+}
+
+func TestAddLedgerKeyNeedsAminoJSON(t *testing.T) {
+	cc := &CosmosProvider{PCfg: CosmosProviderConfig{ChainID: "ibc-0", AccountPrefix: "cosmos", SignModeStr: "direct"}}
+	cc.Keybase = keyring.NewInMemory()
+	_, err := cc.AddLedgerKey("ledger", 118, 0, 0)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), signModeAminoJSON)
+	require.False(t, cc.KeyExists("ledger"))
+}
+
+func TestSignTxWithSigner(t *testing.T) {
+	kr, held := newTestSigner(t)
+	cdc := lens.MakeCodec(lens.ModuleBasics)
+
+	txf := tx.Factory{}.
+		WithChainID("ibc-0").
+		WithTxConfig(cdc.TxConfig).
+		WithKeybase(kr).
+		WithSignMode(signing.SignMode_SIGN_MODE_DIRECT).
+		WithAccountNumber(1).
+		WithGas(100000)
+	addr := held.GetAddress()
+	txb, err := tx.BuildUnsignedTx(txf, banktypes.NewMsgSend(addr, addr, sdk.NewCoins(sdk.NewInt64Coin("stake", 1))))
+	require.NoError(t, err)
+	require.NoError(t, tx.Sign(txf, "relayer", txb, true))
+
+	sigs, err := txb.GetTx().GetSignaturesV2()
+	require.NoError(t, err)
+	require.Len(t, sigs, 1)
+	require.True(t, held.GetPubKey().Equals(sigs[0].PubKey))
+}
@@ -0,0 +1,422 @@
+// Package mock implements provider.ChainProvider on top of an in-memory chain, so the handshakes and
+// the relaying of the relayer can run in plain go tests, without docker or a network.
+//
+// A Chain executes the IBC client, connection, channel and packet msgs the relayer sends it and keeps
+// the state after every block. Queries follow the semantics of a cosmos chain: a query at height h
+// returns the state after block h-1 along with a proof for height h, and the app hash of the header at
+// height h commits to that state. Headers, app hashes and proofs only depend on the chain id and the
+// height, and a proof is checked against the root of the consensus state the receiving client holds
+// at the proof height, so msgs built from the wrong heights or the wrong counterparty state fail just
+// like they would on a real chain.
+package mock
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	transfertypes "github.com/cosmos/ibc-go/v2/modules/apps/transfer/types"
+	clienttypes "github.com/cosmos/ibc-go/v2/modules/core/02-client/types"
+	conntypes "github.com/cosmos/ibc-go/v2/modules/core/03-connection/types"
+	chantypes "github.com/cosmos/ibc-go/v2/modules/core/04-channel/types"
+	tmclient "github.com/cosmos/ibc-go/v2/modules/light-clients/07-tendermint/types"
+	"github.com/cosmos/relayer/relayer/provider"
+	"github.com/gogo/protobuf/proto"
+	abci "github.com/tendermint/tendermint/abci/types"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+)
+
+const (
+	// DefaultBlockTime is the time between two blocks of a new chain
+	DefaultBlockTime = time.Second
+
+	// DefaultUnbondingPeriod is the unbonding period of a new chain
+	DefaultUnbondingPeriod = 21 * 24 * time.Hour
+
+	// gasPerMsg is the gas every msg of a tx is charged
+	gasPerMsg = 100000
+)
+
+// Chain is a simulated chain. Every tx is executed in a block of its own and blocks without txs are
+// committed by NextBlock, or every BlockTime by Run. The time of the header at height h is
+// genesis + h*BlockTime.
+type Chain struct {
+	ChainID         string
+	BlockTime       time.Duration
+	UnbondingPeriod time.Duration
+
+	mu      sync.RWMutex
+	genesis time.Time
+	// states holds the state after every block, indexed by height
+	states []*state
+	txs    map[string]*tx
+}
+
+// tx is a tx executed by the chain
+type tx struct {
+	hash   string
+	height int64
+	code   uint32
+	log    string
+	gas    int64
+	events []abci.Event
+}
+
+// NewChain returns a chain with the given id whose first block is committed at genesis
+func NewChain(chainID string, genesis time.Time) *Chain {
+	return &Chain{
+		ChainID:         chainID,
+		BlockTime:       DefaultBlockTime,
+		UnbondingPeriod: DefaultUnbondingPeriod,
+		genesis:         genesis,
+		states:          []*state{newState(), newState()},
+		txs:             make(map[string]*tx),
+	}
+}
+
+// Run commits a block every BlockTime until the returned func is called
+func (c *Chain) Run() func() {
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(c.BlockTime)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				c.NextBlock()
+			}
+		}
+	}()
+	return func() { close(done) }
+}
+
+// NextBlock commits a block without txs and returns its height
+func (c *Chain) NextBlock() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.states = append(c.states, c.latest())
+	return c.height()
+}
+
+// Height returns the height of the latest block
+func (c *Chain) Height() int64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.height()
+}
+
+// SetBalance sets the balance of an address, it takes effect in a block of its own
+func (c *Chain) SetBalance(addr string, coins sdk.Coins) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	st := c.latest().clone()
+	st.balances[addr] = coins
+	c.states = append(c.states, st)
+}
+
+// Balance returns the balance of an address in the latest state
+func (c *Chain) Balance(addr string) sdk.Coins {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.latest().balances[addr]
+}
+
+// revision returns the revision number of the chain, parsed from its id
+func (c *Chain) revision() uint64 {
+	return clienttypes.ParseChainID(c.ChainID)
+}
+
+func (c *Chain) height() int64 {
+	return int64(len(c.states) - 1)
+}
+
+func (c *Chain) latest() *state {
+	return c.states[len(c.states)-1]
+}
+
+// blockTime returns the time of the block at height h
+func (c *Chain) blockTime(h int64) time.Time {
+	return c.genesis.Add(time.Duration(h) * c.BlockTime).UTC()
+}
+
+// appHash returns the app hash of the header at height h
+func (c *Chain) appHash(h int64) []byte {
+	hash := sha256.Sum256([]byte(fmt.Sprintf("%s/%d", c.ChainID, h)))
+	return hash[:]
+}
+
+// header returns the header at height h. The validator set is left empty: clients of a mock chain
+// trust any header with the right chain id and a trusted height they hold a consensus state for.
+func (c *Chain) header(h int64) (*tmclient.Header, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if h < 1 || h > c.height() {
+		return nil, fmt.Errorf("no header at height %d, latest height is %d", h, c.height())
+	}
+	return c.buildHeader(h), nil
+}
+
+func (c *Chain) buildHeader(h int64) *tmclient.Header {
+	return &tmclient.Header{
+		SignedHeader: &tmproto.SignedHeader{
+			Header: &tmproto.Header{
+				ChainID: c.ChainID,
+				Height:  h,
+				Time:    c.blockTime(h),
+				AppHash: c.appHash(h),
+			},
+			Commit: &tmproto.Commit{Height: h},
+		},
+	}
+}
+
+// stateAt returns the state the header at height h commits to, which is the state after block h-1,
+// and the height proofs of it are for. A height of 0 queries the latest state.
+func (c *Chain) stateAt(h int64) (*state, clienttypes.Height, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	switch {
+	case h == 0:
+		return c.latest(), clienttypes.NewHeight(c.revision(), uint64(c.height()+1)), nil
+	case h < 0 || h > c.height():
+		return nil, clienttypes.Height{}, fmt.Errorf("cannot query height %d, latest height is %d", h, c.height())
+	}
+	return c.states[h-1], clienttypes.NewHeight(c.revision(), uint64(h)), nil
+}
+
+// latestState returns the state after the latest block
+func (c *Chain) latestState() *state {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.latest()
+}
+
+// proof returns the proof that key holds value in the state committed to by the header at height.
+// A nil value proves that key is absent.
+func (c *Chain) proof(height clienttypes.Height, key, value []byte) []byte {
+	return commitmentProof(c.appHash(int64(height.RevisionHeight)), key, value)
+}
+
+// commitmentProof binds a key and its value to the app hash of a header
+func commitmentProof(root, key, value []byte) []byte {
+	h := sha256.New()
+	h.Write(root)
+	h.Write(key)
+	h.Write(value)
+	return h.Sum(nil)
+}
+
+// deliverTx executes msgs in a new block. A tx with a failing msg is still committed but leaves the
+// state unchanged, and reports the error in its log.
+func (c *Chain) deliverTx(msgs []sdk.Msg) (*tx, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	h := sha256.New()
+	fmt.Fprintf(h, "%s/%d", c.ChainID, c.height()+1)
+	for _, msg := range msgs {
+		bz, err := proto.Marshal(msg)
+		if err != nil {
+			return nil, err
+		}
+		h.Write(bz)
+	}
+
+	res := &tx{
+		hash:   fmt.Sprintf("%X", h.Sum(nil)),
+		height: c.height() + 1,
+		gas:    int64(gasPerMsg * len(msgs)),
+	}
+	st, events, err := c.execute(msgs)
+	if err != nil {
+		res.code, res.log = 1, err.Error()
+		st = c.latest()
+	}
+	res.events = events
+
+	c.states = append(c.states, st)
+	c.txs[res.hash] = res
+	return res, nil
+}
+
+// simulateTx executes msgs like deliverTx without committing them, and returns the gas they use
+func (c *Chain) simulateTx(msgs []sdk.Msg) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, _, err := c.execute(msgs); err != nil {
+		return 0, err
+	}
+	return int64(gasPerMsg * len(msgs)), nil
+}
+
+// execute runs msgs on a copy of the latest state as the next block, and returns the resulting state along
+// with the events emitted
+func (c *Chain) execute(msgs []sdk.Msg) (*state, []abci.Event, error) {
+	st := c.latest().clone()
+	ctx := &blockContext{chain: c, height: c.height() + 1}
+	ctx.time = c.blockTime(ctx.height)
+	for i, msg := range msgs {
+		if err := ctx.deliverMsg(st, msg); err != nil {
+			return nil, nil, fmt.Errorf("failed to execute message; message index: %d: %s", i, err)
+		}
+	}
+	return st, ctx.events, nil
+}
+
+// tx returns the tx with the given hash
+func (c *Chain) tx(hash string) (*tx, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	res, ok := c.txs[hash]
+	return res, ok
+}
+
+// allTxs returns every tx executed by the chain, oldest first
+func (c *Chain) allTxs() []*tx {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	txs := make([]*tx, 0, len(c.txs))
+	for _, res := range c.txs {
+		txs = append(txs, res)
+	}
+	sort.Slice(txs, func(i, j int) bool {
+		return txs[i].height < txs[j].height
+	})
+	return txs
+}
+
+// response converts a tx to the response the relayer expects, its events keyed by type.attribute
+func (t *tx) response() *provider.RelayerTxResponse {
+	events := make(map[string]string)
+	for _, ev := range t.events {
+		for _, attr := range ev.Attributes {
+			events[ev.Type+"."+string(attr.Key)] = string(attr.Value)
+		}
+	}
+	return &provider.RelayerTxResponse{
+		Height:  t.height,
+		TxHash:  t.hash,
+		Code:    t.code,
+		Data:    t.log,
+		GasUsed: t.gas,
+		Fee:     sdk.NewCoins(),
+		Events:  events,
+	}
+}
+
+// portChannel identifies a channel end
+type portChannel struct {
+	port, channel string
+}
+
+// packetID identifies a packet by the channel end it was sent or received on
+type packetID struct {
+	portChannel
+	seq uint64
+}
+
+// client is a light client of a counterparty chain
+type client struct {
+	clientState *tmclient.ClientState
+	consensus   map[clienttypes.Height]*tmclient.ConsensusState
+}
+
+// state is the state of a chain after a block. Msg handlers work on a clone of the latest state and
+// replace the values of its maps instead of changing them, so the states of earlier blocks are kept.
+type state struct {
+	clients     map[string]*client
+	connections map[string]conntypes.ConnectionEnd
+	channels    map[portChannel]chantypes.Channel
+	nextSeqSend map[portChannel]uint64
+	nextSeqRecv map[portChannel]uint64
+	commitments map[packetID][]byte
+	receipts    map[packetID]bool
+	// acks holds the acknowledgements written for received packets, the chain commits to their hash
+	acks map[packetID][]byte
+	// sent holds every packet sent by the chain
+	sent     map[packetID]chantypes.Packet
+	balances map[string]sdk.Coins
+	traces   map[string]transfertypes.DenomTrace
+
+	clientSeq, connectionSeq, channelSeq uint64
+}
+
+func newState() *state {
+	return &state{
+		clients:     make(map[string]*client),
+		connections: make(map[string]conntypes.ConnectionEnd),
+		channels:    make(map[portChannel]chantypes.Channel),
+		nextSeqSend: make(map[portChannel]uint64),
+		nextSeqRecv: make(map[portChannel]uint64),
+		commitments: make(map[packetID][]byte),
+		receipts:    make(map[packetID]bool),
+		acks:        make(map[packetID][]byte),
+		sent:        make(map[packetID]chantypes.Packet),
+		balances:    make(map[string]sdk.Coins),
+		traces:      make(map[string]transfertypes.DenomTrace),
+	}
+}
+
+func (st *state) clone() *state {
+	out := newState()
+	for k, v := range st.clients {
+		out.clients[k] = v
+	}
+	for k, v := range st.connections {
+		out.connections[k] = v
+	}
+	for k, v := range st.channels {
+		out.channels[k] = v
+	}
+	for k, v := range st.nextSeqSend {
+		out.nextSeqSend[k] = v
+	}
+	for k, v := range st.nextSeqRecv {
+		out.nextSeqRecv[k] = v
+	}
+	for k, v := range st.commitments {
+		out.commitments[k] = v
+	}
+	for k, v := range st.receipts {
+		out.receipts[k] = v
+	}
+	for k, v := range st.acks {
+		out.acks[k] = v
+	}
+	for k, v := range st.sent {
+		out.sent[k] = v
+	}
+	for k, v := range st.balances {
+		out.balances[k] = v
+	}
+	for k, v := range st.traces {
+		out.traces[k] = v
+	}
+	out.clientSeq, out.connectionSeq, out.channelSeq = st.clientSeq, st.connectionSeq, st.channelSeq
+	return out
+}
+
+// mustMarshal returns the bytes a chain stores for a value
+func mustMarshal(msg proto.Message) []byte {
+	bz, err := proto.Marshal(msg)
+	if err != nil {
+		panic(err)
+	}
+	return bz
+}
+
+// keyName returns a printable form of a store key for errors
+func keyName(key []byte) string {
+	for _, b := range key {
+		if b < 0x20 || b > 0x7e {
+			return hex.EncodeToString(key)
+		}
+	}
+	return string(key)
+}
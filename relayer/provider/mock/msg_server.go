@@ -0,0 +1,850 @@
+package mock
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/authz"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/cosmos/cosmos-sdk/x/feegrant"
+	transfertypes "github.com/cosmos/ibc-go/v2/modules/apps/transfer/types"
+	clienttypes "github.com/cosmos/ibc-go/v2/modules/core/02-client/types"
+	conntypes "github.com/cosmos/ibc-go/v2/modules/core/03-connection/types"
+	chantypes "github.com/cosmos/ibc-go/v2/modules/core/04-channel/types"
+	commitmenttypes "github.com/cosmos/ibc-go/v2/modules/core/23-commitment/types"
+	host "github.com/cosmos/ibc-go/v2/modules/core/24-host"
+	ibcexported "github.com/cosmos/ibc-go/v2/modules/core/exported"
+	tmclient "github.com/cosmos/ibc-go/v2/modules/light-clients/07-tendermint/types"
+	abci "github.com/tendermint/tendermint/abci/types"
+)
+
+// blockContext is the block a tx is executed in
+type blockContext struct {
+	chain  *Chain
+	height int64
+	time   time.Time
+	events []abci.Event
+}
+
+// selfHeight returns the height of the block as an IBC height
+func (ctx *blockContext) selfHeight() clienttypes.Height {
+	return clienttypes.NewHeight(ctx.chain.revision(), uint64(ctx.height))
+}
+
+// emit records an event with the given attributes, given as key value pairs
+func (ctx *blockContext) emit(eventType string, attrs ...string) {
+	ev := abci.Event{Type: eventType}
+	for i := 0; i+1 < len(attrs); i += 2 {
+		ev.Attributes = append(ev.Attributes, abci.EventAttribute{Key: []byte(attrs[i]), Value: []byte(attrs[i+1])})
+	}
+	ctx.events = append(ctx.events, ev)
+}
+
+// deliverMsg executes a msg on st
+func (ctx *blockContext) deliverMsg(st *state, msg sdk.Msg) error {
+	switch m := msg.(type) {
+	case *clienttypes.MsgCreateClient:
+		return ctx.createClient(st, m)
+	case *clienttypes.MsgUpdateClient:
+		return ctx.updateClient(st, m)
+	case *clienttypes.MsgSubmitMisbehaviour:
+		return ctx.submitMisbehaviour(st, m)
+	case *conntypes.MsgConnectionOpenInit:
+		return ctx.connectionOpenInit(st, m)
+	case *conntypes.MsgConnectionOpenTry:
+		return ctx.connectionOpenTry(st, m)
+	case *conntypes.MsgConnectionOpenAck:
+		return ctx.connectionOpenAck(st, m)
+	case *conntypes.MsgConnectionOpenConfirm:
+		return ctx.connectionOpenConfirm(st, m)
+	case *chantypes.MsgChannelOpenInit:
+		return ctx.channelOpenInit(st, m)
+	case *chantypes.MsgChannelOpenTry:
+		return ctx.channelOpenTry(st, m)
+	case *chantypes.MsgChannelOpenAck:
+		return ctx.channelOpenAck(st, m)
+	case *chantypes.MsgChannelOpenConfirm:
+		return ctx.channelOpenConfirm(st, m)
+	case *chantypes.MsgChannelCloseInit:
+		return ctx.channelCloseInit(st, m)
+	case *chantypes.MsgChannelCloseConfirm:
+		return ctx.channelCloseConfirm(st, m)
+	case *transfertypes.MsgTransfer:
+		return ctx.transfer(st, m)
+	case *chantypes.MsgRecvPacket:
+		return ctx.recvPacket(st, m)
+	case *chantypes.MsgAcknowledgement:
+		return ctx.acknowledgePacket(st, m)
+	case *chantypes.MsgTimeout:
+		return ctx.timeoutPacket(st, m)
+	case *banktypes.MsgSend:
+		return st.send(m.FromAddress, m.ToAddress, m.Amount)
+	case *authz.MsgExec:
+		msgs, err := m.GetMessages()
+		if err != nil {
+			return err
+		}
+		for _, msg := range msgs {
+			if err = ctx.deliverMsg(st, msg); err != nil {
+				return err
+			}
+		}
+		return nil
+	case *authz.MsgGrant, *feegrant.MsgGrantAllowance, *feegrant.MsgRevokeAllowance:
+		// fees are not charged, so grants have no effect
+		return nil
+	default:
+		return fmt.Errorf("unsupported msg type %s", sdk.MsgTypeURL(msg))
+	}
+}
+
+// verify checks that proof proves value under key in the state of the counterparty tracked by the
+// client with the given id, at height. A nil value proves that key is absent.
+func (st *state) verify(clientID string, height clienttypes.Height, proof, key, value []byte) error {
+	cl, ok := st.clients[clientID]
+	switch {
+	case !ok:
+		return fmt.Errorf("client %s not found", clientID)
+	case !cl.clientState.FrozenHeight.IsZero():
+		return fmt.Errorf("client %s is frozen", clientID)
+	}
+	cs, ok := cl.consensus[height]
+	if !ok {
+		return fmt.Errorf("client %s has no consensus state at proof height %s", clientID, height)
+	}
+	if !bytes.Equal(proof, commitmentProof(cs.Root.GetHash(), key, value)) {
+		return fmt.Errorf("invalid proof for %s at height %s", keyName(key), height)
+	}
+	return nil
+}
+
+func (ctx *blockContext) createClient(st *state, m *clienttypes.MsgCreateClient) error {
+	cs, err := clienttypes.UnpackClientState(m.ClientState)
+	if err != nil {
+		return err
+	}
+	consState, err := clienttypes.UnpackConsensusState(m.ConsensusState)
+	if err != nil {
+		return err
+	}
+	clientState, ok := cs.(*tmclient.ClientState)
+	if !ok {
+		return fmt.Errorf("unsupported client state %T", cs)
+	}
+	consensusState, ok := consState.(*tmclient.ConsensusState)
+	if !ok {
+		return fmt.Errorf("unsupported consensus state %T", consState)
+	}
+
+	clientID := clienttypes.FormatClientIdentifier(ibcexported.Tendermint, st.clientSeq)
+	st.clientSeq++
+	st.clients[clientID] = &client{
+		clientState: clientState,
+		consensus:   map[clienttypes.Height]*tmclient.ConsensusState{clientState.LatestHeight: consensusState},
+	}
+	ctx.emit(clienttypes.EventTypeCreateClient,
+		clienttypes.AttributeKeyClientID, clientID,
+		clienttypes.AttributeKeyClientType, ibcexported.Tendermint,
+		clienttypes.AttributeKeyConsensusHeight, clientState.LatestHeight.String())
+	return nil
+}
+
+func (ctx *blockContext) updateClient(st *state, m *clienttypes.MsgUpdateClient) error {
+	h, err := clienttypes.UnpackHeader(m.Header)
+	if err != nil {
+		return err
+	}
+	header, ok := h.(*tmclient.Header)
+	if !ok || header.Header == nil {
+		return fmt.Errorf("unsupported header %T", h)
+	}
+
+	cl, ok := st.clients[m.ClientId]
+	switch {
+	case !ok:
+		return fmt.Errorf("client %s not found", m.ClientId)
+	case !cl.clientState.FrozenHeight.IsZero():
+		return fmt.Errorf("client %s is frozen", m.ClientId)
+	case header.Header.ChainID != cl.clientState.ChainId:
+		return fmt.Errorf("header of chain %s cannot update client %s of chain %s",
+			header.Header.ChainID, m.ClientId, cl.clientState.ChainId)
+	}
+
+	height := header.GetHeight().(clienttypes.Height)
+	consensusState := header.ConsensusState()
+	if existing, ok := cl.consensus[height]; ok && bytes.Equal(existing.Root.GetHash(), consensusState.Root.GetHash()) {
+		// the client already holds this header
+		return nil
+	}
+	trusted, ok := cl.consensus[header.TrustedHeight]
+	switch {
+	case !ok:
+		return fmt.Errorf("client %s has no consensus state at trusted height %s", m.ClientId, header.TrustedHeight)
+	case !height.GT(header.TrustedHeight):
+		return fmt.Errorf("header height %s must be greater than trusted height %s", height, header.TrustedHeight)
+	case cl.clientState.IsExpired(trusted.Timestamp, ctx.time):
+		return fmt.Errorf("client %s is expired", m.ClientId)
+	}
+
+	updated := &client{
+		clientState: cl.clientState,
+		consensus:   make(map[clienttypes.Height]*tmclient.ConsensusState, len(cl.consensus)+1),
+	}
+	for k, v := range cl.consensus {
+		updated.consensus[k] = v
+	}
+	if _, ok := cl.consensus[height]; ok {
+		// a second header for the same height is misbehaviour
+		frozen := *cl.clientState
+		frozen.FrozenHeight = tmclient.FrozenHeight
+		updated.clientState = &frozen
+	} else {
+		updated.consensus[height] = consensusState
+		if height.GT(cl.clientState.LatestHeight) {
+			cs := *cl.clientState
+			cs.LatestHeight = height
+			updated.clientState = &cs
+		}
+	}
+	st.clients[m.ClientId] = updated
+
+	ctx.emit(clienttypes.EventTypeUpdateClient,
+		clienttypes.AttributeKeyClientID, m.ClientId,
+		clienttypes.AttributeKeyClientType, ibcexported.Tendermint,
+		clienttypes.AttributeKeyConsensusHeight, height.String())
+	return nil
+}
+
+func (ctx *blockContext) submitMisbehaviour(st *state, m *clienttypes.MsgSubmitMisbehaviour) error {
+	cl, ok := st.clients[m.ClientId]
+	if !ok {
+		return fmt.Errorf("client %s not found", m.ClientId)
+	}
+	frozen := *cl.clientState
+	frozen.FrozenHeight = tmclient.FrozenHeight
+	st.clients[m.ClientId] = &client{clientState: &frozen, consensus: cl.consensus}
+	ctx.emit(clienttypes.EventTypeSubmitMisbehaviour, clienttypes.AttributeKeyClientID, m.ClientId)
+	return nil
+}
+
+// ourPrefix returns the commitment prefix of the chain, the one the relayer records in the
+// counterparty of a connection end
+func ourPrefix() commitmenttypes.MerklePrefix {
+	return commitmenttypes.NewMerklePrefix([]byte(host.StoreKey))
+}
+
+// verifySelfClient checks the client state a counterparty holds of this chain and the consensus state
+// it stores for it, as proven in a connection handshake
+func (ctx *blockContext) verifySelfClient(st *state, conn conntypes.ConnectionEnd, clientState ibcexported.ClientState, proofHeight, consensusHeight clienttypes.Height, proofClient, proofConsensus []byte) error {
+	cs, ok := clientState.(*tmclient.ClientState)
+	switch {
+	case !ok:
+		return fmt.Errorf("unsupported client state %T", clientState)
+	case cs.ChainId != ctx.chain.ChainID:
+		return fmt.Errorf("counterparty client tracks chain %s instead of %s", cs.ChainId, ctx.chain.ChainID)
+	case !cs.FrozenHeight.IsZero():
+		return fmt.Errorf("counterparty client is frozen")
+	case !consensusHeight.LT(ctx.selfHeight()):
+		return fmt.Errorf("consensus height %s is not lower than the current height %s", consensusHeight, ctx.selfHeight())
+	}
+
+	key := host.FullClientStateKey(conn.Counterparty.ClientId)
+	if err := st.verify(conn.ClientId, proofHeight, proofClient, key, mustMarshal(cs)); err != nil {
+		return err
+	}
+
+	self := ctx.chain.buildHeader(int64(consensusHeight.RevisionHeight)).ConsensusState()
+	key = host.FullConsensusStateKey(conn.Counterparty.ClientId, consensusHeight)
+	return st.verify(conn.ClientId, proofHeight, proofConsensus, key, mustMarshal(self))
+}
+
+func (ctx *blockContext) connectionOpenInit(st *state, m *conntypes.MsgConnectionOpenInit) error {
+	if _, ok := st.clients[m.ClientId]; !ok {
+		return fmt.Errorf("client %s not found", m.ClientId)
+	}
+	versions := conntypes.GetCompatibleVersions()
+	if m.Version != nil {
+		versions = []ibcexported.Version{m.Version}
+	}
+
+	connectionID := conntypes.FormatConnectionIdentifier(st.connectionSeq)
+	st.connectionSeq++
+	st.connections[connectionID] = conntypes.ConnectionEnd{
+		ClientId:     m.ClientId,
+		Versions:     conntypes.ExportedVersionsToProto(versions),
+		State:        conntypes.INIT,
+		Counterparty: m.Counterparty,
+		DelayPeriod:  m.DelayPeriod,
+	}
+	ctx.emit(conntypes.EventTypeConnectionOpenInit,
+		conntypes.AttributeKeyConnectionID, connectionID,
+		conntypes.AttributeKeyClientID, m.ClientId,
+		conntypes.AttributeKeyCounterpartyClientID, m.Counterparty.ClientId)
+	return nil
+}
+
+func (ctx *blockContext) connectionOpenTry(st *state, m *conntypes.MsgConnectionOpenTry) error {
+	if _, ok := st.clients[m.ClientId]; !ok {
+		return fmt.Errorf("client %s not found", m.ClientId)
+	}
+	clientState, err := clienttypes.UnpackClientState(m.ClientState)
+	if err != nil {
+		return err
+	}
+	version, err := conntypes.PickVersion(conntypes.GetCompatibleVersions(), conntypes.ProtoVersionsToExported(m.CounterpartyVersions))
+	if err != nil {
+		return err
+	}
+
+	conn := conntypes.ConnectionEnd{
+		ClientId:     m.ClientId,
+		Versions:     conntypes.ExportedVersionsToProto([]ibcexported.Version{version}),
+		State:        conntypes.TRYOPEN,
+		Counterparty: m.Counterparty,
+		DelayPeriod:  m.DelayPeriod,
+	}
+	expected := conntypes.ConnectionEnd{
+		ClientId:     m.Counterparty.ClientId,
+		Versions:     m.CounterpartyVersions,
+		State:        conntypes.INIT,
+		Counterparty: conntypes.NewCounterparty(m.ClientId, "", ourPrefix()),
+		DelayPeriod:  m.DelayPeriod,
+	}
+	key := host.ConnectionKey(m.Counterparty.ConnectionId)
+	if err = st.verify(m.ClientId, m.ProofHeight, m.ProofInit, key, mustMarshal(&expected)); err != nil {
+		return err
+	}
+	if err = ctx.verifySelfClient(st, conn, clientState, m.ProofHeight, m.ConsensusHeight, m.ProofClient, m.ProofConsensus); err != nil {
+		return err
+	}
+
+	connectionID := m.PreviousConnectionId
+	if connectionID != "" {
+		previous, ok := st.connections[connectionID]
+		if !ok || previous.State != conntypes.INIT {
+			return fmt.Errorf("previous connection %s is not in state INIT", connectionID)
+		}
+	} else {
+		connectionID = conntypes.FormatConnectionIdentifier(st.connectionSeq)
+		st.connectionSeq++
+	}
+	st.connections[connectionID] = conn
+	ctx.emit(conntypes.EventTypeConnectionOpenTry,
+		conntypes.AttributeKeyConnectionID, connectionID,
+		conntypes.AttributeKeyClientID, m.ClientId,
+		conntypes.AttributeKeyCounterpartyClientID, m.Counterparty.ClientId,
+		conntypes.AttributeKeyCounterpartyConnectionID, m.Counterparty.ConnectionId)
+	return nil
+}
+
+func (ctx *blockContext) connectionOpenAck(st *state, m *conntypes.MsgConnectionOpenAck) error {
+	conn, ok := st.connections[m.ConnectionId]
+	switch {
+	case !ok:
+		return fmt.Errorf("connection %s not found", m.ConnectionId)
+	case conn.State != conntypes.INIT && conn.State != conntypes.TRYOPEN:
+		return fmt.Errorf("connection %s is in state %s, expected INIT or TRYOPEN", m.ConnectionId, conn.State)
+	case m.Version == nil || !conntypes.IsSupportedVersion(m.Version):
+		return fmt.Errorf("version %s is not supported", m.Version)
+	}
+	clientState, err := clienttypes.UnpackClientState(m.ClientState)
+	if err != nil {
+		return err
+	}
+
+	expected := conntypes.ConnectionEnd{
+		ClientId:     conn.Counterparty.ClientId,
+		Versions:     []*conntypes.Version{m.Version},
+		State:        conntypes.TRYOPEN,
+		Counterparty: conntypes.NewCounterparty(conn.ClientId, m.ConnectionId, ourPrefix()),
+		DelayPeriod:  conn.DelayPeriod,
+	}
+	key := host.ConnectionKey(m.CounterpartyConnectionId)
+	if err = st.verify(conn.ClientId, m.ProofHeight, m.ProofTry, key, mustMarshal(&expected)); err != nil {
+		return err
+	}
+	if err = ctx.verifySelfClient(st, conn, clientState, m.ProofHeight, m.ConsensusHeight, m.ProofClient, m.ProofConsensus); err != nil {
+		return err
+	}
+
+	conn.State = conntypes.OPEN
+	conn.Versions = []*conntypes.Version{m.Version}
+	conn.Counterparty.ConnectionId = m.CounterpartyConnectionId
+	st.connections[m.ConnectionId] = conn
+	ctx.emit(conntypes.EventTypeConnectionOpenAck,
+		conntypes.AttributeKeyConnectionID, m.ConnectionId,
+		conntypes.AttributeKeyCounterpartyConnectionID, m.CounterpartyConnectionId)
+	return nil
+}
+
+func (ctx *blockContext) connectionOpenConfirm(st *state, m *conntypes.MsgConnectionOpenConfirm) error {
+	conn, ok := st.connections[m.ConnectionId]
+	switch {
+	case !ok:
+		return fmt.Errorf("connection %s not found", m.ConnectionId)
+	case conn.State != conntypes.TRYOPEN:
+		return fmt.Errorf("connection %s is in state %s, expected TRYOPEN", m.ConnectionId, conn.State)
+	}
+
+	expected := conntypes.ConnectionEnd{
+		ClientId:     conn.Counterparty.ClientId,
+		Versions:     conn.Versions,
+		State:        conntypes.OPEN,
+		Counterparty: conntypes.NewCounterparty(conn.ClientId, m.ConnectionId, ourPrefix()),
+		DelayPeriod:  conn.DelayPeriod,
+	}
+	key := host.ConnectionKey(conn.Counterparty.ConnectionId)
+	if err := st.verify(conn.ClientId, m.ProofHeight, m.ProofAck, key, mustMarshal(&expected)); err != nil {
+		return err
+	}
+
+	conn.State = conntypes.OPEN
+	st.connections[m.ConnectionId] = conn
+	ctx.emit(conntypes.EventTypeConnectionOpenConfirm, conntypes.AttributeKeyConnectionID, m.ConnectionId)
+	return nil
+}
+
+// channelConnection returns the open connection a channel is built on
+func (st *state) channelConnection(hops []string) (conntypes.ConnectionEnd, error) {
+	if len(hops) != 1 {
+		return conntypes.ConnectionEnd{}, fmt.Errorf("channels must have exactly one connection hop, got %d", len(hops))
+	}
+	conn, ok := st.connections[hops[0]]
+	if !ok {
+		return conntypes.ConnectionEnd{}, fmt.Errorf("connection %s not found", hops[0])
+	}
+	return conn, nil
+}
+
+// openConnection returns the connection of a channel and fails unless it is open
+func (st *state) openConnection(hops []string) (conntypes.ConnectionEnd, error) {
+	conn, err := st.channelConnection(hops)
+	if err != nil {
+		return conn, err
+	}
+	if conn.State != conntypes.OPEN {
+		return conn, fmt.Errorf("connection %s is in state %s, expected OPEN", hops[0], conn.State)
+	}
+	return conn, nil
+}
+
+func (ctx *blockContext) channelOpenInit(st *state, m *chantypes.MsgChannelOpenInit) error {
+	if _, err := st.channelConnection(m.Channel.ConnectionHops); err != nil {
+		return err
+	}
+
+	channelID := chantypes.FormatChannelIdentifier(st.channelSeq)
+	st.channelSeq++
+	pc := portChannel{m.PortId, channelID}
+	st.channels[pc] = m.Channel
+	st.nextSeqSend[pc], st.nextSeqRecv[pc] = 1, 1
+	ctx.emit(chantypes.EventTypeChannelOpenInit,
+		chantypes.AttributeKeyPortID, m.PortId,
+		chantypes.AttributeKeyChannelID, channelID,
+		chantypes.AttributeCounterpartyPortID, m.Channel.Counterparty.PortId,
+		chantypes.AttributeKeyConnectionID, m.Channel.ConnectionHops[0])
+	return nil
+}
+
+func (ctx *blockContext) channelOpenTry(st *state, m *chantypes.MsgChannelOpenTry) error {
+	conn, err := st.openConnection(m.Channel.ConnectionHops)
+	if err != nil {
+		return err
+	}
+
+	expected := chantypes.NewChannel(chantypes.INIT, m.Channel.Ordering, chantypes.NewCounterparty(m.PortId, ""),
+		[]string{conn.Counterparty.ConnectionId}, m.CounterpartyVersion)
+	key := host.ChannelKey(m.Channel.Counterparty.PortId, m.Channel.Counterparty.ChannelId)
+	if err = st.verify(conn.ClientId, m.ProofHeight, m.ProofInit, key, mustMarshal(&expected)); err != nil {
+		return err
+	}
+
+	channelID := m.PreviousChannelId
+	if channelID != "" {
+		previous, ok := st.channels[portChannel{m.PortId, channelID}]
+		if !ok || previous.State != chantypes.INIT {
+			return fmt.Errorf("previous channel %s is not in state INIT", channelID)
+		}
+	} else {
+		channelID = chantypes.FormatChannelIdentifier(st.channelSeq)
+		st.channelSeq++
+	}
+	pc := portChannel{m.PortId, channelID}
+	st.channels[pc] = m.Channel
+	st.nextSeqSend[pc], st.nextSeqRecv[pc] = 1, 1
+	ctx.emit(chantypes.EventTypeChannelOpenTry,
+		chantypes.AttributeKeyPortID, m.PortId,
+		chantypes.AttributeKeyChannelID, channelID,
+		chantypes.AttributeCounterpartyPortID, m.Channel.Counterparty.PortId,
+		chantypes.AttributeCounterpartyChannelID, m.Channel.Counterparty.ChannelId,
+		chantypes.AttributeKeyConnectionID, m.Channel.ConnectionHops[0])
+	return nil
+}
+
+func (ctx *blockContext) channelOpenAck(st *state, m *chantypes.MsgChannelOpenAck) error {
+	pc := portChannel{m.PortId, m.ChannelId}
+	channel, ok := st.channels[pc]
+	switch {
+	case !ok:
+		return fmt.Errorf("channel %s/%s not found", m.PortId, m.ChannelId)
+	case channel.State != chantypes.INIT && channel.State != chantypes.TRYOPEN:
+		return fmt.Errorf("channel %s is in state %s, expected INIT or TRYOPEN", m.ChannelId, channel.State)
+	}
+	conn, err := st.openConnection(channel.ConnectionHops)
+	if err != nil {
+		return err
+	}
+
+	expected := chantypes.NewChannel(chantypes.TRYOPEN, channel.Ordering, chantypes.NewCounterparty(m.PortId, m.ChannelId),
+		[]string{conn.Counterparty.ConnectionId}, m.CounterpartyVersion)
+	key := host.ChannelKey(channel.Counterparty.PortId, m.CounterpartyChannelId)
+	if err = st.verify(conn.ClientId, m.ProofHeight, m.ProofTry, key, mustMarshal(&expected)); err != nil {
+		return err
+	}
+
+	channel.State = chantypes.OPEN
+	channel.Version = m.CounterpartyVersion
+	channel.Counterparty.ChannelId = m.CounterpartyChannelId
+	st.channels[pc] = channel
+	ctx.emit(chantypes.EventTypeChannelOpenAck,
+		chantypes.AttributeKeyPortID, m.PortId,
+		chantypes.AttributeKeyChannelID, m.ChannelId,
+		chantypes.AttributeCounterpartyChannelID, m.CounterpartyChannelId)
+	return nil
+}
+
+func (ctx *blockContext) channelOpenConfirm(st *state, m *chantypes.MsgChannelOpenConfirm) error {
+	pc := portChannel{m.PortId, m.ChannelId}
+	channel, ok := st.channels[pc]
+	switch {
+	case !ok:
+		return fmt.Errorf("channel %s/%s not found", m.PortId, m.ChannelId)
+	case channel.State != chantypes.TRYOPEN:
+		return fmt.Errorf("channel %s is in state %s, expected TRYOPEN", m.ChannelId, channel.State)
+	}
+	conn, err := st.openConnection(channel.ConnectionHops)
+	if err != nil {
+		return err
+	}
+
+	expected := chantypes.NewChannel(chantypes.OPEN, channel.Ordering, chantypes.NewCounterparty(m.PortId, m.ChannelId),
+		[]string{conn.Counterparty.ConnectionId}, channel.Version)
+	key := host.ChannelKey(channel.Counterparty.PortId, channel.Counterparty.ChannelId)
+	if err = st.verify(conn.ClientId, m.ProofHeight, m.ProofAck, key, mustMarshal(&expected)); err != nil {
+		return err
+	}
+
+	channel.State = chantypes.OPEN
+	st.channels[pc] = channel
+	ctx.emit(chantypes.EventTypeChannelOpenConfirm,
+		chantypes.AttributeKeyPortID, m.PortId,
+		chantypes.AttributeKeyChannelID, m.ChannelId)
+	return nil
+}
+
+func (ctx *blockContext) channelCloseInit(st *state, m *chantypes.MsgChannelCloseInit) error {
+	pc := portChannel{m.PortId, m.ChannelId}
+	channel, ok := st.channels[pc]
+	switch {
+	case !ok:
+		return fmt.Errorf("channel %s/%s not found", m.PortId, m.ChannelId)
+	case channel.State == chantypes.CLOSED:
+		return fmt.Errorf("channel %s is already closed", m.ChannelId)
+	}
+	if _, err := st.openConnection(channel.ConnectionHops); err != nil {
+		return err
+	}
+
+	channel.State = chantypes.CLOSED
+	st.channels[pc] = channel
+	ctx.emit(chantypes.EventTypeChannelCloseInit,
+		chantypes.AttributeKeyPortID, m.PortId,
+		chantypes.AttributeKeyChannelID, m.ChannelId)
+	return nil
+}
+
+func (ctx *blockContext) channelCloseConfirm(st *state, m *chantypes.MsgChannelCloseConfirm) error {
+	pc := portChannel{m.PortId, m.ChannelId}
+	channel, ok := st.channels[pc]
+	switch {
+	case !ok:
+		return fmt.Errorf("channel %s/%s not found", m.PortId, m.ChannelId)
+	case channel.State == chantypes.CLOSED:
+		return fmt.Errorf("channel %s is already closed", m.ChannelId)
+	}
+	conn, err := st.openConnection(channel.ConnectionHops)
+	if err != nil {
+		return err
+	}
+
+	expected := chantypes.NewChannel(chantypes.CLOSED, channel.Ordering, chantypes.NewCounterparty(m.PortId, m.ChannelId),
+		[]string{conn.Counterparty.ConnectionId}, channel.Version)
+	key := host.ChannelKey(channel.Counterparty.PortId, channel.Counterparty.ChannelId)
+	if err = st.verify(conn.ClientId, m.ProofHeight, m.ProofInit, key, mustMarshal(&expected)); err != nil {
+		return err
+	}
+
+	channel.State = chantypes.CLOSED
+	st.channels[pc] = channel
+	ctx.emit(chantypes.EventTypeChannelCloseConfirm,
+		chantypes.AttributeKeyPortID, m.PortId,
+		chantypes.AttributeKeyChannelID, m.ChannelId)
+	return nil
+}
+
+// send moves coins between two accounts
+func (st *state) send(from, to string, amount sdk.Coins) error {
+	if err := st.debit(from, amount); err != nil {
+		return err
+	}
+	st.credit(to, amount)
+	return nil
+}
+
+func (st *state) debit(addr string, amount sdk.Coins) error {
+	balance, negative := st.balances[addr].SafeSub(amount)
+	if negative {
+		return fmt.Errorf("insufficient funds: %s has %s, needs %s", addr, st.balances[addr], amount)
+	}
+	st.balances[addr] = balance
+	return nil
+}
+
+func (st *state) credit(addr string, amount sdk.Coins) {
+	st.balances[addr] = st.balances[addr].Add(amount...)
+}
+
+// localDenom returns the denom on this chain of the denom of a transfer packet
+func (st *state) localDenom(denom string) string {
+	return transfertypes.ParseDenomTrace(denom).IBCDenom()
+}
+
+// transfer sends an ics20 packet and escrows the tokens of the sender. Vouchers are not burned but
+// escrowed as well, since the balances of a mock chain only need to add up from the point of view of
+// the accounts.
+func (ctx *blockContext) transfer(st *state, m *transfertypes.MsgTransfer) error {
+	pc := portChannel{m.SourcePort, m.SourceChannel}
+	channel, ok := st.channels[pc]
+	switch {
+	case !ok:
+		return fmt.Errorf("channel %s/%s not found", m.SourcePort, m.SourceChannel)
+	case channel.State != chantypes.OPEN:
+		return fmt.Errorf("channel %s is in state %s, expected OPEN", m.SourceChannel, channel.State)
+	}
+	if err := st.debit(m.Sender, sdk.NewCoins(m.Token)); err != nil {
+		return err
+	}
+
+	denom := m.Token.Denom
+	if strings.HasPrefix(denom, "ibc/") {
+		trace, ok := st.traces[strings.TrimPrefix(denom, "ibc/")]
+		if !ok {
+			return fmt.Errorf("denomination trace of %s not found", denom)
+		}
+		denom = trace.GetFullDenomPath()
+	}
+	data := transfertypes.NewFungibleTokenPacketData(denom, m.Token.Amount.String(), m.Sender, m.Receiver)
+
+	seq := st.nextSeqSend[pc]
+	st.nextSeqSend[pc] = seq + 1
+	packet := chantypes.NewPacket(data.GetBytes(), seq, m.SourcePort, m.SourceChannel,
+		channel.Counterparty.PortId, channel.Counterparty.ChannelId, m.TimeoutHeight, m.TimeoutTimestamp)
+	id := packetID{pc, seq}
+	st.commitments[id] = chantypes.CommitPacket(nil, packet)
+	st.sent[id] = packet
+
+	ctx.emit(chantypes.EventTypeSendPacket,
+		chantypes.AttributeKeyData, string(packet.Data),
+		chantypes.AttributeKeyTimeoutHeight, packet.TimeoutHeight.String(),
+		chantypes.AttributeKeyTimeoutTimestamp, strconv.FormatUint(packet.TimeoutTimestamp, 10),
+		chantypes.AttributeKeySequence, strconv.FormatUint(seq, 10),
+		chantypes.AttributeKeySrcPort, packet.SourcePort,
+		chantypes.AttributeKeySrcChannel, packet.SourceChannel,
+		chantypes.AttributeKeyDstPort, packet.DestinationPort,
+		chantypes.AttributeKeyDstChannel, packet.DestinationChannel,
+		chantypes.AttributeKeyChannelOrdering, channel.Ordering.String())
+	return nil
+}
+
+// packetChannel returns the open channel a packet is sent over, along with its connection
+func (st *state) packetChannel(port, channelID string) (chantypes.Channel, conntypes.ConnectionEnd, error) {
+	channel, ok := st.channels[portChannel{port, channelID}]
+	switch {
+	case !ok:
+		return channel, conntypes.ConnectionEnd{}, fmt.Errorf("channel %s/%s not found", port, channelID)
+	case channel.State != chantypes.OPEN:
+		return channel, conntypes.ConnectionEnd{}, fmt.Errorf("channel %s is in state %s, expected OPEN", channelID, channel.State)
+	}
+	conn, err := st.openConnection(channel.ConnectionHops)
+	return channel, conn, err
+}
+
+// recvPacket receives a packet and writes its acknowledgement. Receipts are written on ordered channels
+// too, so timeouts are proven the same way on both orderings.
+func (ctx *blockContext) recvPacket(st *state, m *chantypes.MsgRecvPacket) error {
+	packet := m.Packet
+	channel, conn, err := st.packetChannel(packet.DestinationPort, packet.DestinationChannel)
+	switch {
+	case err != nil:
+		return err
+	case packet.SourcePort != channel.Counterparty.PortId || packet.SourceChannel != channel.Counterparty.ChannelId:
+		return fmt.Errorf("packet was sent from %s/%s, not the counterparty of channel %s",
+			packet.SourcePort, packet.SourceChannel, packet.DestinationChannel)
+	case !packet.TimeoutHeight.IsZero() && ctx.selfHeight().GTE(packet.TimeoutHeight):
+		return fmt.Errorf("packet timed out at height %s", packet.TimeoutHeight)
+	case packet.TimeoutTimestamp != 0 && uint64(ctx.time.UnixNano()) >= packet.TimeoutTimestamp:
+		return fmt.Errorf("packet timed out at timestamp %d", packet.TimeoutTimestamp)
+	}
+
+	key := host.PacketCommitmentKey(packet.SourcePort, packet.SourceChannel, packet.Sequence)
+	if err = st.verify(conn.ClientId, m.ProofHeight, m.ProofCommitment, key, chantypes.CommitPacket(nil, packet)); err != nil {
+		return err
+	}
+
+	pc := portChannel{packet.DestinationPort, packet.DestinationChannel}
+	id := packetID{pc, packet.Sequence}
+	if st.receipts[id] {
+		return fmt.Errorf("packet seq(%d) was already received", packet.Sequence)
+	}
+	if channel.Ordering == chantypes.ORDERED {
+		if packet.Sequence != st.nextSeqRecv[pc] {
+			return fmt.Errorf("packet seq(%d) received out of order, expected seq(%d)", packet.Sequence, st.nextSeqRecv[pc])
+		}
+		st.nextSeqRecv[pc] = packet.Sequence + 1
+	}
+	st.receipts[id] = true
+
+	ack := ctx.onRecvPacket(st, packet)
+	st.acks[id] = ack.Acknowledgement()
+
+	ctx.emit(chantypes.EventTypeRecvPacket,
+		chantypes.AttributeKeySequence, strconv.FormatUint(packet.Sequence, 10),
+		chantypes.AttributeKeyDstPort, packet.DestinationPort,
+		chantypes.AttributeKeyDstChannel, packet.DestinationChannel)
+	ctx.emit(chantypes.EventTypeWriteAck,
+		chantypes.AttributeKeyData, string(packet.Data),
+		chantypes.AttributeKeyAck, string(ack.Acknowledgement()),
+		chantypes.AttributeKeyTimeoutHeight, packet.TimeoutHeight.String(),
+		chantypes.AttributeKeyTimeoutTimestamp, strconv.FormatUint(packet.TimeoutTimestamp, 10),
+		chantypes.AttributeKeySequence, strconv.FormatUint(packet.Sequence, 10),
+		chantypes.AttributeKeySrcPort, packet.SourcePort,
+		chantypes.AttributeKeySrcChannel, packet.SourceChannel,
+		chantypes.AttributeKeyDstPort, packet.DestinationPort,
+		chantypes.AttributeKeyDstChannel, packet.DestinationChannel)
+	return nil
+}
+
+// onRecvPacket credits the receiver of an ics20 packet with vouchers, or with the escrowed tokens
+// when they return to the chain they came from
+func (ctx *blockContext) onRecvPacket(st *state, packet chantypes.Packet) chantypes.Acknowledgement {
+	var data transfertypes.FungibleTokenPacketData
+	if err := transfertypes.ModuleCdc.UnmarshalJSON(packet.Data, &data); err != nil {
+		return chantypes.NewErrorAcknowledgement("cannot unmarshal ICS-20 transfer packet data")
+	}
+	amount, ok := sdk.NewIntFromString(data.Amount)
+	if !ok {
+		return chantypes.NewErrorAcknowledgement(fmt.Sprintf("invalid amount %s", data.Amount))
+	}
+
+	var denom string
+	if transfertypes.ReceiverChainIsSource(packet.SourcePort, packet.SourceChannel, data.Denom) {
+		denom = st.localDenom(strings.TrimPrefix(data.Denom, transfertypes.GetDenomPrefix(packet.SourcePort, packet.SourceChannel)))
+	} else {
+		trace := transfertypes.ParseDenomTrace(transfertypes.GetPrefixedDenom(packet.DestinationPort, packet.DestinationChannel, data.Denom))
+		st.traces[trace.Hash().String()] = trace
+		denom = trace.IBCDenom()
+	}
+	st.credit(data.Receiver, sdk.NewCoins(sdk.NewCoin(denom, amount)))
+	return chantypes.NewResultAcknowledgement([]byte{byte(1)})
+}
+
+// refund returns the tokens of an ics20 packet to its sender
+func (st *state) refund(packet chantypes.Packet) {
+	var data transfertypes.FungibleTokenPacketData
+	if err := transfertypes.ModuleCdc.UnmarshalJSON(packet.Data, &data); err != nil {
+		return
+	}
+	if amount, ok := sdk.NewIntFromString(data.Amount); ok {
+		st.credit(data.Sender, sdk.NewCoins(sdk.NewCoin(st.localDenom(data.Denom), amount)))
+	}
+}
+
+// sentPacket checks that a packet sent by the chain is still waiting for its acknowledgement or timeout
+func (st *state) sentPacket(packet chantypes.Packet) (chantypes.Channel, conntypes.ConnectionEnd, error) {
+	channel, conn, err := st.packetChannel(packet.SourcePort, packet.SourceChannel)
+	if err != nil {
+		return channel, conn, err
+	}
+	commitment, ok := st.commitments[packetID{portChannel{packet.SourcePort, packet.SourceChannel}, packet.Sequence}]
+	switch {
+	case !ok:
+		return channel, conn, fmt.Errorf("packet seq(%d) has no commitment, it was acknowledged or timed out", packet.Sequence)
+	case !bytes.Equal(commitment, chantypes.CommitPacket(nil, packet)):
+		return channel, conn, fmt.Errorf("packet seq(%d) does not match its commitment", packet.Sequence)
+	}
+	return channel, conn, nil
+}
+
+func (ctx *blockContext) acknowledgePacket(st *state, m *chantypes.MsgAcknowledgement) error {
+	packet := m.Packet
+	_, conn, err := st.sentPacket(packet)
+	if err != nil {
+		return err
+	}
+	key := host.PacketAcknowledgementKey(packet.DestinationPort, packet.DestinationChannel, packet.Sequence)
+	if err = st.verify(conn.ClientId, m.ProofHeight, m.ProofAcked, key, chantypes.CommitAcknowledgement(m.Acknowledgement)); err != nil {
+		return err
+	}
+
+	delete(st.commitments, packetID{portChannel{packet.SourcePort, packet.SourceChannel}, packet.Sequence})
+	var ack chantypes.Acknowledgement
+	if err = transfertypes.ModuleCdc.UnmarshalJSON(m.Acknowledgement, &ack); err != nil || !ack.Success() {
+		st.refund(packet)
+	}
+
+	ctx.emit(chantypes.EventTypeAcknowledgePacket,
+		chantypes.AttributeKeySequence, strconv.FormatUint(packet.Sequence, 10),
+		chantypes.AttributeKeySrcPort, packet.SourcePort,
+		chantypes.AttributeKeySrcChannel, packet.SourceChannel)
+	return nil
+}
+
+func (ctx *blockContext) timeoutPacket(st *state, m *chantypes.MsgTimeout) error {
+	packet := m.Packet
+	channel, conn, err := st.sentPacket(packet)
+	if err != nil {
+		return err
+	}
+
+	cl := st.clients[conn.ClientId]
+	cs, ok := cl.consensus[m.ProofHeight]
+	switch {
+	case !ok:
+		return fmt.Errorf("client %s has no consensus state at proof height %s", conn.ClientId, m.ProofHeight)
+	case (packet.TimeoutHeight.IsZero() || m.ProofHeight.LT(packet.TimeoutHeight)) &&
+		(packet.TimeoutTimestamp == 0 || uint64(cs.Timestamp.UnixNano()) < packet.TimeoutTimestamp):
+		return fmt.Errorf("packet seq(%d) has not timed out at height %s", packet.Sequence, m.ProofHeight)
+	}
+	key := host.PacketReceiptKey(packet.DestinationPort, packet.DestinationChannel, packet.Sequence)
+	if err = st.verify(conn.ClientId, m.ProofHeight, m.ProofUnreceived, key, nil); err != nil {
+		return err
+	}
+
+	pc := portChannel{packet.SourcePort, packet.SourceChannel}
+	delete(st.commitments, packetID{pc, packet.Sequence})
+	if channel.Ordering == chantypes.ORDERED {
+		channel.State = chantypes.CLOSED
+		st.channels[pc] = channel
+	}
+	st.refund(packet)
+
+	ctx.emit(chantypes.EventTypeTimeoutPacket,
+		chantypes.AttributeKeySequence, strconv.FormatUint(packet.Sequence, 10),
+		chantypes.AttributeKeySrcPort, packet.SourcePort,
+		chantypes.AttributeKeySrcChannel, packet.SourceChannel)
+	return nil
+}
@@ -0,0 +1,137 @@
+package mock
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/authz"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/cosmos/cosmos-sdk/x/feegrant"
+	transfertypes "github.com/cosmos/ibc-go/v2/modules/apps/transfer/types"
+	ibctypes "github.com/cosmos/ibc-go/v2/modules/core/types"
+	tmclient "github.com/cosmos/ibc-go/v2/modules/light-clients/07-tendermint/types"
+	"github.com/cosmos/relayer/relayer/provider"
+)
+
+// registry decodes the msgs of the txs broadcast with BroadcastTx
+var registry = func() codectypes.InterfaceRegistry {
+	r := codectypes.NewInterfaceRegistry()
+	ibctypes.RegisterInterfaces(r)
+	tmclient.RegisterInterfaces(r)
+	transfertypes.RegisterInterfaces(r)
+	banktypes.RegisterInterfaces(r)
+	authz.RegisterInterfaces(r)
+	feegrant.RegisterInterfaces(r)
+	return r
+}()
+
+// offlineTx is the encoding of the txs generated for a mock chain. Its signature is nothing more than a
+// hash of the tx and of the account it is signed for.
+type offlineTx struct {
+	Msgs      []offlineMsg `json:"msgs"`
+	Gas       uint64       `json:"gas"`
+	Signature []byte       `json:"signature,omitempty"`
+}
+
+type offlineMsg struct {
+	TypeURL string `json:"type-url"`
+	Value   []byte `json:"value"`
+}
+
+// GenerateTx encodes msgs in an unsigned tx for the key of the provider
+func (mp *MockProvider) GenerateTx(msgs []provider.RelayerMessage) (*provider.OfflineTx, error) {
+	signer, err := mp.Address()
+	if err != nil {
+		return nil, err
+	}
+	gas, _, err := mp.SimulateMessages(msgs, "")
+	if err != nil {
+		return nil, err
+	}
+
+	tx := offlineTx{Msgs: make([]offlineMsg, 0, len(msgs)), Gas: gas}
+	for _, msg := range msgs {
+		bz, err := msg.MsgBytes()
+		if err != nil {
+			return nil, err
+		}
+		tx.Msgs = append(tx.Msgs, offlineMsg{TypeURL: msg.Type(), Value: bz})
+	}
+	bz, err := json.Marshal(tx)
+	if err != nil {
+		return nil, err
+	}
+	return &provider.OfflineTx{ChainID: mp.chain.ChainID, Signer: signer, Tx: bz}, nil
+}
+
+// SignTx signs a generated tx with the key of the provider, which must be the key of its signer
+func (mp *MockProvider) SignTx(otx *provider.OfflineTx) error {
+	if otx.ChainID != mp.chain.ChainID {
+		return fmt.Errorf("transaction is for chain %s, not %s", otx.ChainID, mp.chain.ChainID)
+	}
+	addr, err := mp.Address()
+	if err != nil {
+		return err
+	}
+	if addr != otx.Signer {
+		return fmt.Errorf("transaction must be signed by %s, but key %s has address %s", otx.Signer, mp.PCfg.Key, addr)
+	}
+
+	var tx offlineTx
+	if err = json.Unmarshal(otx.Tx, &tx); err != nil {
+		return err
+	}
+	if tx.Signature, err = signature(otx, tx); err != nil {
+		return err
+	}
+	otx.Tx, err = json.Marshal(tx)
+	return err
+}
+
+// BroadcastTx executes the msgs of a tx signed by SignTx in a new block of the chain
+func (mp *MockProvider) BroadcastTx(otx *provider.OfflineTx) (*provider.RelayerTxResponse, bool, error) {
+	if otx.ChainID != mp.chain.ChainID {
+		return nil, false, fmt.Errorf("transaction is for chain %s, not %s", otx.ChainID, mp.chain.ChainID)
+	}
+
+	var tx offlineTx
+	if err := json.Unmarshal(otx.Tx, &tx); err != nil {
+		return nil, false, err
+	}
+	if len(tx.Signature) == 0 {
+		return nil, false, fmt.Errorf("transaction for %s is not signed", otx.Signer)
+	}
+	if sig, err := signature(otx, tx); err != nil || !bytes.Equal(sig, tx.Signature) {
+		return nil, false, fmt.Errorf("transaction for %s has an invalid signature", otx.Signer)
+	}
+
+	txMsgs := make([]sdk.Msg, 0, len(tx.Msgs))
+	for _, msg := range tx.Msgs {
+		var sdkMsg sdk.Msg
+		if err := registry.UnpackAny(&codectypes.Any{TypeUrl: msg.TypeURL, Value: msg.Value}, &sdkMsg); err != nil {
+			return nil, false, err
+		}
+		txMsgs = append(txMsgs, sdkMsg)
+	}
+
+	res, err := mp.chain.deliverTx(txMsgs)
+	if err != nil {
+		return nil, false, err
+	}
+	return mp.WaitForTx(res.hash, defaultTxCommitTimeout)
+}
+
+// signature returns the signature of tx by the signer of otx
+func signature(otx *provider.OfflineTx, tx offlineTx) ([]byte, error) {
+	tx.Signature = nil
+	bz, err := json.Marshal(tx)
+	if err != nil {
+		return nil, err
+	}
+	hash := sha256.Sum256([]byte(fmt.Sprintf("%s/%s/%d/%d/%s", otx.ChainID, otx.Signer, otx.AccountNumber, otx.Sequence, bz)))
+	return hash[:], nil
+}
@@ -0,0 +1,969 @@
+package mock
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/bech32"
+	"github.com/cosmos/cosmos-sdk/x/authz"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/cosmos/cosmos-sdk/x/feegrant"
+	transfertypes "github.com/cosmos/ibc-go/v2/modules/apps/transfer/types"
+	clienttypes "github.com/cosmos/ibc-go/v2/modules/core/02-client/types"
+	conntypes "github.com/cosmos/ibc-go/v2/modules/core/03-connection/types"
+	chantypes "github.com/cosmos/ibc-go/v2/modules/core/04-channel/types"
+	commitmenttypes "github.com/cosmos/ibc-go/v2/modules/core/23-commitment/types"
+	ibcexported "github.com/cosmos/ibc-go/v2/modules/core/exported"
+	tmclient "github.com/cosmos/ibc-go/v2/modules/light-clients/07-tendermint/types"
+	"github.com/cosmos/relayer/relayer/provider"
+	"github.com/gogo/protobuf/proto"
+	"github.com/tendermint/tendermint/libs/log"
+	ctypes "github.com/tendermint/tendermint/rpc/core/types"
+)
+
+var (
+	_ provider.ChainProvider  = &MockProvider{}
+	_ provider.ProviderConfig = MockProviderConfig{}
+
+	// ErrNotSupported is returned for the features a mock chain does not simulate
+	ErrNotSupported = errors.New("not supported by the mock provider")
+)
+
+// defaultTxCommitTimeout is how long SendMessages waits for a tx, a mock chain commits them right away
+const defaultTxCommitTimeout = time.Second
+
+// MockProviderConfig is the configuration of a mock chain
+type MockProviderConfig struct {
+	Key           string `json:"key" yaml:"key"`
+	ChainID       string `json:"chain-id" yaml:"chain-id"`
+	AccountPrefix string `json:"account-prefix" yaml:"account-prefix"`
+	Timeout       string `json:"timeout" yaml:"timeout"`
+}
+
+func (pc MockProviderConfig) Validate() error {
+	if pc.ChainID == "" {
+		return errors.New("chain-id is required")
+	}
+	if _, err := time.ParseDuration(pc.Timeout); err != nil {
+		return err
+	}
+	return nil
+}
+
+// NewProvider validates the MockProviderConfig and returns a provider of a new chain started now.
+// The chain does not commit empty blocks until Chain().Run is called.
+func (pc MockProviderConfig) NewProvider(homepath string, debug bool) (provider.ChainProvider, error) {
+	if err := pc.Validate(); err != nil {
+		return nil, err
+	}
+	p := NewProvider(NewChain(pc.ChainID, time.Now()), pc.Key)
+	p.PCfg = pc
+	return p, nil
+}
+
+// MockMessage wraps an sdk.Msg, so mock chains can be driven by the relayer code written for cosmos chains
+type MockMessage struct {
+	Msg sdk.Msg
+}
+
+func NewMockMessage(msg sdk.Msg) provider.RelayerMessage {
+	return MockMessage{Msg: msg}
+}
+
+func (mm MockMessage) Type() string {
+	return sdk.MsgTypeURL(mm.Msg)
+}
+
+func (mm MockMessage) MsgBytes() ([]byte, error) {
+	return proto.Marshal(mm.Msg)
+}
+
+// MockProvider is a provider.ChainProvider of a mock Chain. Several providers, each with their own keys,
+// can share a chain. Txs are not signed and pay no fees, the first msg of a tx is simply executed on
+// behalf of the key of the provider.
+type MockProvider struct {
+	PCfg MockProviderConfig
+
+	chain *Chain
+
+	mu           sync.RWMutex
+	keys         map[string]string
+	keyPool      []string
+	keyIndex     int
+	feeGranter   string
+	authzGranter string
+	logger       log.Logger
+}
+
+// NewProvider returns a provider of chain that signs with the named key, which is created with AddKey
+// when it does not exist yet
+func NewProvider(chain *Chain, key string) *MockProvider {
+	p := &MockProvider{
+		PCfg: MockProviderConfig{
+			Key:           key,
+			ChainID:       chain.ChainID,
+			AccountPrefix: sdk.Bech32MainPrefix,
+			Timeout:       "10s",
+		},
+		chain:  chain,
+		keys:   make(map[string]string),
+		logger: log.NewNopLogger(),
+	}
+	if key != "" {
+		_, _ = p.AddKey(key)
+	}
+	return p
+}
+
+// Chain returns the chain of the provider
+func (mp *MockProvider) Chain() *Chain {
+	return mp.chain
+}
+
+func (mp *MockProvider) Init() error {
+	return nil
+}
+
+func (mp *MockProvider) ProviderConfig() provider.ProviderConfig {
+	return mp.PCfg
+}
+
+func (mp *MockProvider) ChainId() string {
+	return mp.chain.ChainID
+}
+
+func (mp *MockProvider) Type() string {
+	return "mock"
+}
+
+func (mp *MockProvider) Key() string {
+	return mp.PCfg.Key
+}
+
+func (mp *MockProvider) Timeout() string {
+	return mp.PCfg.Timeout
+}
+
+// Address returns the address of the key of the provider
+func (mp *MockProvider) Address() (string, error) {
+	return mp.ShowAddress(mp.PCfg.Key)
+}
+
+// TrustingPeriod returns 85% of the unbonding period of the chain, as the cosmos provider does
+func (mp *MockProvider) TrustingPeriod() (time.Duration, error) {
+	integer, _ := math.Modf(mp.chain.UnbondingPeriod.Hours() * 0.85)
+	return time.Duration(integer) * time.Hour, nil
+}
+
+// address derives the address of a key from its mnemonic
+func (mp *MockProvider) address(mnemonic string) (string, error) {
+	hash := sha256.Sum256([]byte(mnemonic))
+	return bech32.ConvertAndEncode(mp.PCfg.AccountPrefix, hash[:20])
+}
+
+func (mp *MockProvider) CreateKeystore(path string) error {
+	return nil
+}
+
+func (mp *MockProvider) KeystoreCreated(path string) bool {
+	return true
+}
+
+// AddKey adds a key whose mnemonic is derived from the chain id and its name, so the same key has the
+// same address in every provider of a chain
+func (mp *MockProvider) AddKey(name string) (*provider.KeyOutput, error) {
+	hash := sha256.Sum256([]byte(mp.chain.ChainID + "/" + name))
+	mnemonic := hex.EncodeToString(hash[:])
+	addr, err := mp.RestoreKey(name, mnemonic)
+	if err != nil {
+		return nil, err
+	}
+	return &provider.KeyOutput{Mnemonic: mnemonic, Address: addr}, nil
+}
+
+// AddLedgerKey fails, a mock chain has no Ledger device to hold keys
+func (mp *MockProvider) AddLedgerKey(name string, coinType, account, index uint32) (string, error) {
+	return "", errors.New("ledger keys are not supported by the mock provider")
+}
+
+func (mp *MockProvider) RestoreKey(name, mnemonic string) (string, error) {
+	addr, err := mp.address(mnemonic)
+	if err != nil {
+		return "", err
+	}
+	mp.mu.Lock()
+	defer mp.mu.Unlock()
+	if _, ok := mp.keys[name]; ok {
+		return "", fmt.Errorf("key with name %s already exists", name)
+	}
+	mp.keys[name] = addr
+	return addr, nil
+}
+
+func (mp *MockProvider) ShowAddress(name string) (string, error) {
+	mp.mu.RLock()
+	defer mp.mu.RUnlock()
+	addr, ok := mp.keys[name]
+	if !ok {
+		return "", fmt.Errorf("key %s not found", name)
+	}
+	return addr, nil
+}
+
+func (mp *MockProvider) ListAddresses() (map[string]string, error) {
+	mp.mu.RLock()
+	defer mp.mu.RUnlock()
+	out := make(map[string]string, len(mp.keys))
+	for name, addr := range mp.keys {
+		out[name] = addr
+	}
+	return out, nil
+}
+
+func (mp *MockProvider) DeleteKey(name string) error {
+	mp.mu.Lock()
+	defer mp.mu.Unlock()
+	if _, ok := mp.keys[name]; !ok {
+		return fmt.Errorf("key %s not found", name)
+	}
+	delete(mp.keys, name)
+	return nil
+}
+
+func (mp *MockProvider) KeyExists(name string) bool {
+	mp.mu.RLock()
+	defer mp.mu.RUnlock()
+	_, ok := mp.keys[name]
+	return ok
+}
+
+func (mp *MockProvider) ExportPrivKeyArmor(keyName string) (string, error) {
+	return "", ErrNotSupported
+}
+
+func (mp *MockProvider) KeyPool() []string {
+	mp.mu.RLock()
+	defer mp.mu.RUnlock()
+	return mp.keyPool
+}
+
+func (mp *MockProvider) SetKeyPool(keys []string) {
+	mp.mu.Lock()
+	defer mp.mu.Unlock()
+	mp.keyPool = keys
+}
+
+// NextKey takes the keys of the pool in turn, or returns the key of the provider without a pool
+func (mp *MockProvider) NextKey() string {
+	mp.mu.Lock()
+	defer mp.mu.Unlock()
+	if len(mp.keyPool) == 0 {
+		return mp.PCfg.Key
+	}
+	key := mp.keyPool[mp.keyIndex%len(mp.keyPool)]
+	mp.keyIndex++
+	return key
+}
+
+// SetFeeGranter records the fee granter, fees are not charged by a mock chain
+func (mp *MockProvider) SetFeeGranter(granter string) {
+	mp.mu.Lock()
+	defer mp.mu.Unlock()
+	mp.feeGranter = granter
+}
+
+// FeeGranter returns the recorded fee granter
+func (mp *MockProvider) FeeGranter() string {
+	mp.mu.Lock()
+	defer mp.mu.Unlock()
+	return mp.feeGranter
+}
+
+// SetAuthzGranter records the authz granter, msgs are executed on behalf of their signer regardless
+func (mp *MockProvider) SetAuthzGranter(granter string) {
+	mp.mu.Lock()
+	defer mp.mu.Unlock()
+	mp.authzGranter = granter
+}
+
+func (mp *MockProvider) SetLogger(logger log.Logger) {
+	mp.logger = logger
+}
+
+// SetLightCacheSize is a no-op, headers of a mock chain are built on demand
+func (mp *MockProvider) SetLightCacheSize(size int) {}
+
+func (mp *MockProvider) CreateClient(clientState ibcexported.ClientState, dstHeader ibcexported.Header) (provider.RelayerMessage, error) {
+	tmHeader, ok := dstHeader.(*tmclient.Header)
+	if !ok {
+		return nil, fmt.Errorf("got data of type %T but wanted tmclient.Header", dstHeader)
+	}
+	acc, err := mp.Address()
+	if err != nil {
+		return nil, err
+	}
+	anyClientState, err := clienttypes.PackClientState(clientState)
+	if err != nil {
+		return nil, err
+	}
+	anyConsensusState, err := clienttypes.PackConsensusState(tmHeader.ConsensusState())
+	if err != nil {
+		return nil, err
+	}
+	return NewMockMessage(&clienttypes.MsgCreateClient{
+		ClientState:    anyClientState,
+		ConsensusState: anyConsensusState,
+		Signer:         acc,
+	}), nil
+}
+
+func (mp *MockProvider) SubmitMisbehavior(clientId string, misbehaviour ibcexported.Misbehaviour) (provider.RelayerMessage, error) {
+	acc, err := mp.Address()
+	if err != nil {
+		return nil, err
+	}
+	msg, err := clienttypes.NewMsgSubmitMisbehaviour(clientId, misbehaviour, acc)
+	if err != nil {
+		return nil, err
+	}
+	return NewMockMessage(msg), nil
+}
+
+func (mp *MockProvider) UpdateClient(srcClientId string, dstHeader ibcexported.Header) (provider.RelayerMessage, error) {
+	acc, err := mp.Address()
+	if err != nil {
+		return nil, err
+	}
+	anyHeader, err := clienttypes.PackHeader(dstHeader)
+	if err != nil {
+		return nil, err
+	}
+	return NewMockMessage(&clienttypes.MsgUpdateClient{
+		ClientId: srcClientId,
+		Header:   anyHeader,
+		Signer:   acc,
+	}), nil
+}
+
+// withUpdate prepends a client update to msg, as the handshake steps of the cosmos provider do
+func (mp *MockProvider) withUpdate(srcClientId string, dstHeader ibcexported.Header, msg sdk.Msg) ([]provider.RelayerMessage, error) {
+	updateMsg, err := mp.UpdateClient(srcClientId, dstHeader)
+	if err != nil {
+		return nil, err
+	}
+	return []provider.RelayerMessage{updateMsg, NewMockMessage(msg)}, nil
+}
+
+func (mp *MockProvider) ConnectionOpenInit(srcClientId, dstClientId string, dstHeader ibcexported.Header) ([]provider.RelayerMessage, error) {
+	acc, err := mp.Address()
+	if err != nil {
+		return nil, err
+	}
+	return mp.withUpdate(srcClientId, dstHeader, &conntypes.MsgConnectionOpenInit{
+		ClientId:     srcClientId,
+		Counterparty: conntypes.NewCounterparty(dstClientId, "", ourPrefix()),
+		Signer:       acc,
+	})
+}
+
+func (mp *MockProvider) ConnectionOpenTry(dstQueryProvider provider.QueryProvider, dstHeader ibcexported.Header, srcClientId, dstClientId, srcConnId, dstConnId string) ([]provider.RelayerMessage, error) {
+	acc, err := mp.Address()
+	if err != nil {
+		return nil, err
+	}
+	cph, err := dstQueryProvider.QueryLatestHeight()
+	if err != nil {
+		return nil, err
+	}
+	clientState, clientStateProof, consensusStateProof, connStateProof, proofHeight, err := dstQueryProvider.GenerateConnHandshakeProof(cph, dstClientId, dstConnId)
+	if err != nil {
+		return nil, err
+	}
+	csAny, err := clienttypes.PackClientState(clientState)
+	if err != nil {
+		return nil, err
+	}
+	return mp.withUpdate(srcClientId, dstHeader, &conntypes.MsgConnectionOpenTry{
+		ClientId:             srcClientId,
+		PreviousConnectionId: srcConnId,
+		ClientState:          csAny,
+		Counterparty:         conntypes.NewCounterparty(dstClientId, dstConnId, ourPrefix()),
+		CounterpartyVersions: conntypes.ExportedVersionsToProto(conntypes.GetCompatibleVersions()),
+		ProofHeight:          proofHeight.(clienttypes.Height),
+		ProofInit:            connStateProof,
+		ProofClient:          clientStateProof,
+		ProofConsensus:       consensusStateProof,
+		ConsensusHeight:      clientState.GetLatestHeight().(clienttypes.Height),
+		Signer:               acc,
+	})
+}
+
+func (mp *MockProvider) ConnectionOpenAck(dstQueryProvider provider.QueryProvider, dstHeader ibcexported.Header, srcClientId, srcConnId, dstClientId, dstConnId string) ([]provider.RelayerMessage, error) {
+	acc, err := mp.Address()
+	if err != nil {
+		return nil, err
+	}
+	cph, err := dstQueryProvider.QueryLatestHeight()
+	if err != nil {
+		return nil, err
+	}
+	clientState, clientStateProof, consensusStateProof, connStateProof, proofHeight, err := dstQueryProvider.GenerateConnHandshakeProof(cph, dstClientId, dstConnId)
+	if err != nil {
+		return nil, err
+	}
+	csAny, err := clienttypes.PackClientState(clientState)
+	if err != nil {
+		return nil, err
+	}
+	return mp.withUpdate(srcClientId, dstHeader, &conntypes.MsgConnectionOpenAck{
+		ConnectionId:             srcConnId,
+		CounterpartyConnectionId: dstConnId,
+		Version:                  conntypes.DefaultIBCVersion,
+		ClientState:              csAny,
+		ProofHeight:              proofHeight.(clienttypes.Height),
+		ProofTry:                 connStateProof,
+		ProofClient:              clientStateProof,
+		ProofConsensus:           consensusStateProof,
+		ConsensusHeight:          clientState.GetLatestHeight().(clienttypes.Height),
+		Signer:                   acc,
+	})
+}
+
+func (mp *MockProvider) ConnectionOpenConfirm(dstQueryProvider provider.QueryProvider, dstHeader ibcexported.Header, dstConnId, srcClientId, srcConnId string) ([]provider.RelayerMessage, error) {
+	acc, err := mp.Address()
+	if err != nil {
+		return nil, err
+	}
+	cph, err := dstQueryProvider.QueryLatestHeight()
+	if err != nil {
+		return nil, err
+	}
+	counterpartyConnState, err := dstQueryProvider.QueryConnection(cph, dstConnId)
+	if err != nil {
+		return nil, err
+	}
+	return mp.withUpdate(srcClientId, dstHeader, &conntypes.MsgConnectionOpenConfirm{
+		ConnectionId: srcConnId,
+		ProofAck:     counterpartyConnState.Proof,
+		ProofHeight:  counterpartyConnState.ProofHeight,
+		Signer:       acc,
+	})
+}
+
+func (mp *MockProvider) ChannelOpenInit(srcClientId, srcConnId, srcPortId, srcVersion, dstPortId string, order chantypes.Order, dstHeader ibcexported.Header) ([]provider.RelayerMessage, error) {
+	acc, err := mp.Address()
+	if err != nil {
+		return nil, err
+	}
+	return mp.withUpdate(srcClientId, dstHeader, &chantypes.MsgChannelOpenInit{
+		PortId:  srcPortId,
+		Channel: chantypes.NewChannel(chantypes.INIT, order, chantypes.NewCounterparty(dstPortId, ""), []string{srcConnId}, srcVersion),
+		Signer:  acc,
+	})
+}
+
+func (mp *MockProvider) ChannelOpenTry(dstQueryProvider provider.QueryProvider, dstHeader ibcexported.Header, srcPortId, dstPortId, srcChanId, dstChanId, srcVersion, srcConnectionId, srcClientId string) ([]provider.RelayerMessage, error) {
+	acc, err := mp.Address()
+	if err != nil {
+		return nil, err
+	}
+	cph, err := dstQueryProvider.QueryLatestHeight()
+	if err != nil {
+		return nil, err
+	}
+	counterpartyChannelRes, err := dstQueryProvider.QueryChannel(cph, dstChanId, dstPortId)
+	if err != nil {
+		return nil, err
+	}
+	return mp.withUpdate(srcClientId, dstHeader, &chantypes.MsgChannelOpenTry{
+		PortId:              srcPortId,
+		PreviousChannelId:   srcChanId,
+		Channel:             chantypes.NewChannel(chantypes.TRYOPEN, counterpartyChannelRes.Channel.Ordering, chantypes.NewCounterparty(dstPortId, dstChanId), []string{srcConnectionId}, srcVersion),
+		CounterpartyVersion: counterpartyChannelRes.Channel.Version,
+		ProofInit:           counterpartyChannelRes.Proof,
+		ProofHeight:         counterpartyChannelRes.ProofHeight,
+		Signer:              acc,
+	})
+}
+
+func (mp *MockProvider) ChannelOpenAck(dstQueryProvider provider.QueryProvider, dstHeader ibcexported.Header, srcClientId, srcPortId, srcChanId, dstChanId, dstPortId string) ([]provider.RelayerMessage, error) {
+	acc, err := mp.Address()
+	if err != nil {
+		return nil, err
+	}
+	cph, err := dstQueryProvider.QueryLatestHeight()
+	if err != nil {
+		return nil, err
+	}
+	counterpartyChannelRes, err := dstQueryProvider.QueryChannel(cph, dstChanId, dstPortId)
+	if err != nil {
+		return nil, err
+	}
+	return mp.withUpdate(srcClientId, dstHeader, &chantypes.MsgChannelOpenAck{
+		PortId:                srcPortId,
+		ChannelId:             srcChanId,
+		CounterpartyChannelId: dstChanId,
+		CounterpartyVersion:   counterpartyChannelRes.Channel.Version,
+		ProofTry:              counterpartyChannelRes.Proof,
+		ProofHeight:           counterpartyChannelRes.ProofHeight,
+		Signer:                acc,
+	})
+}
+
+func (mp *MockProvider) ChannelOpenConfirm(dstQueryProvider provider.QueryProvider, dstHeader ibcexported.Header, srcClientId, srcPortId, srcChanId, dstPortId, dstChanId string) ([]provider.RelayerMessage, error) {
+	acc, err := mp.Address()
+	if err != nil {
+		return nil, err
+	}
+	cph, err := dstQueryProvider.QueryLatestHeight()
+	if err != nil {
+		return nil, err
+	}
+	counterpartyChanState, err := dstQueryProvider.QueryChannel(cph, dstChanId, dstPortId)
+	if err != nil {
+		return nil, err
+	}
+	return mp.withUpdate(srcClientId, dstHeader, &chantypes.MsgChannelOpenConfirm{
+		PortId:      srcPortId,
+		ChannelId:   srcChanId,
+		ProofAck:    counterpartyChanState.Proof,
+		ProofHeight: counterpartyChanState.ProofHeight,
+		Signer:      acc,
+	})
+}
+
+func (mp *MockProvider) ChannelCloseInit(srcPortId, srcChanId string) (provider.RelayerMessage, error) {
+	acc, err := mp.Address()
+	if err != nil {
+		return nil, err
+	}
+	return NewMockMessage(&chantypes.MsgChannelCloseInit{
+		PortId:    srcPortId,
+		ChannelId: srcChanId,
+		Signer:    acc,
+	}), nil
+}
+
+func (mp *MockProvider) ChannelCloseConfirm(dstQueryProvider provider.QueryProvider, dsth int64, dstChanId, dstPortId, srcPortId, srcChanId string) (provider.RelayerMessage, error) {
+	acc, err := mp.Address()
+	if err != nil {
+		return nil, err
+	}
+	dstChanResp, err := dstQueryProvider.QueryChannel(dsth, dstChanId, dstPortId)
+	if err != nil {
+		return nil, err
+	}
+	return NewMockMessage(&chantypes.MsgChannelCloseConfirm{
+		PortId:      srcPortId,
+		ChannelId:   srcChanId,
+		ProofInit:   dstChanResp.Proof,
+		ProofHeight: dstChanResp.ProofHeight,
+		Signer:      acc,
+	}), nil
+}
+
+// GetIBCUpdateHeader returns the header of the chain at srch, trusting the latest height of the client
+// of the chain on dst
+func (mp *MockProvider) GetIBCUpdateHeader(srch int64, dst provider.ChainProvider, dstClientId string) (ibcexported.Header, error) {
+	h, err := mp.chain.header(srch)
+	if err != nil {
+		return nil, err
+	}
+	cs, err := dst.QueryClientState(0, dstClientId)
+	if err != nil {
+		return nil, err
+	}
+	h.TrustedHeight = cs.GetLatestHeight().(clienttypes.Height)
+	return h, nil
+}
+
+func (mp *MockProvider) GetLightSignedHeaderAtHeight(h int64) (ibcexported.Header, error) {
+	if h == 0 {
+		return nil, errors.New("height cannot be 0")
+	}
+	return mp.chain.header(h)
+}
+
+func (mp *MockProvider) MsgRelayAcknowledgement(dst provider.ChainProvider, dstChanId, dstPortId, srcChanId, srcPortId string, dsth int64, packet provider.RelayPacket) (provider.RelayerMessage, error) {
+	ack, ok := packet.(*relayPacket)
+	if !ok || ack.ack == nil {
+		return nil, fmt.Errorf("got data of type %T but wanted an acknowledgement", packet)
+	}
+	acc, err := mp.Address()
+	if err != nil {
+		return nil, err
+	}
+	ackRes, err := dst.QueryPacketAcknowledgement(dsth, dstChanId, dstPortId, packet.Seq())
+	if err != nil {
+		return nil, err
+	}
+	return NewMockMessage(&chantypes.MsgAcknowledgement{
+		Packet:          ack.packet(srcPortId, srcChanId, dstPortId, dstChanId),
+		Acknowledgement: ack.ack,
+		ProofAcked:      ackRes.Proof,
+		ProofHeight:     ackRes.ProofHeight,
+		Signer:          acc,
+	}), nil
+}
+
+func (mp *MockProvider) MsgTransfer(amount sdk.Coin, dstChainId, dstAddr, srcPortId, srcChanId string, timeoutHeight, timeoutTimestamp uint64) (provider.RelayerMessage, error) {
+	acc, err := mp.Address()
+	if err != nil {
+		return nil, err
+	}
+	msg := &transfertypes.MsgTransfer{
+		SourcePort:       srcPortId,
+		SourceChannel:    srcChanId,
+		Token:            amount,
+		Sender:           acc,
+		Receiver:         dstAddr,
+		TimeoutTimestamp: timeoutTimestamp,
+	}
+	if timeoutHeight > 0 {
+		msg.TimeoutHeight = clienttypes.NewHeight(clienttypes.ParseChainID(dstChainId), timeoutHeight)
+	}
+	return NewMockMessage(msg), nil
+}
+
+func (mp *MockProvider) MsgSend(dstAddr string, amount sdk.Coins) (provider.RelayerMessage, error) {
+	acc, err := mp.Address()
+	if err != nil {
+		return nil, err
+	}
+	return NewMockMessage(&banktypes.MsgSend{FromAddress: acc, ToAddress: dstAddr, Amount: amount}), nil
+}
+
+func (mp *MockProvider) MsgGrantAllowance(grantee string, spendLimit sdk.Coins, expiration *time.Time) (provider.RelayerMessage, error) {
+	granter, err := mp.Address()
+	if err != nil {
+		return nil, err
+	}
+	allowance, err := codectypes.NewAnyWithValue(&feegrant.BasicAllowance{SpendLimit: spendLimit, Expiration: expiration})
+	if err != nil {
+		return nil, err
+	}
+	return NewMockMessage(&feegrant.MsgGrantAllowance{Granter: granter, Grantee: grantee, Allowance: allowance}), nil
+}
+
+func (mp *MockProvider) MsgRevokeAllowance(grantee string) (provider.RelayerMessage, error) {
+	granter, err := mp.Address()
+	if err != nil {
+		return nil, err
+	}
+	return NewMockMessage(&feegrant.MsgRevokeAllowance{Granter: granter, Grantee: grantee}), nil
+}
+
+func (mp *MockProvider) MsgGrantRelayAuthorizations(grantee string, expiration time.Time) ([]provider.RelayerMessage, error) {
+	granter, err := mp.Address()
+	if err != nil {
+		return nil, err
+	}
+	var msgs []provider.RelayerMessage
+	for _, msg := range []sdk.Msg{
+		&clienttypes.MsgUpdateClient{},
+		&chantypes.MsgRecvPacket{},
+		&chantypes.MsgAcknowledgement{},
+		&chantypes.MsgTimeout{},
+	} {
+		authorization, err := codectypes.NewAnyWithValue(authz.NewGenericAuthorization(sdk.MsgTypeURL(msg)))
+		if err != nil {
+			return nil, err
+		}
+		msgs = append(msgs, NewMockMessage(&authz.MsgGrant{
+			Granter: granter,
+			Grantee: grantee,
+			Grant:   authz.Grant{Authorization: authorization, Expiration: expiration},
+		}))
+	}
+	return msgs, nil
+}
+
+func (mp *MockProvider) MsgRelayTimeout(dst provider.ChainProvider, dsth int64, packet provider.RelayPacket, dstChanId, dstPortId, srcChanId, srcPortId string) (provider.RelayerMessage, error) {
+	acc, err := mp.Address()
+	if err != nil {
+		return nil, err
+	}
+	recvRes, err := dst.QueryPacketReceipt(dsth, dstChanId, dstPortId, packet.Seq())
+	if err != nil {
+		return nil, err
+	}
+	return NewMockMessage(&chantypes.MsgTimeout{
+		Packet:           packetOf(packet, srcPortId, srcChanId, dstPortId, dstChanId),
+		ProofUnreceived:  recvRes.Proof,
+		ProofHeight:      recvRes.ProofHeight,
+		NextSequenceRecv: packet.Seq(),
+		Signer:           acc,
+	}), nil
+}
+
+func (mp *MockProvider) MsgRelayRecvPacket(dst provider.ChainProvider, dsth int64, packet provider.RelayPacket, dstChanId, dstPortId, srcChanId, srcPortId string) (provider.RelayerMessage, error) {
+	acc, err := mp.Address()
+	if err != nil {
+		return nil, err
+	}
+	comRes, err := dst.QueryPacketCommitment(dsth, dstChanId, dstPortId, packet.Seq())
+	if err != nil {
+		return nil, err
+	}
+	return NewMockMessage(&chantypes.MsgRecvPacket{
+		Packet:          packetOf(packet, dstPortId, dstChanId, srcPortId, srcChanId),
+		ProofCommitment: comRes.Proof,
+		ProofHeight:     comRes.ProofHeight,
+		Signer:          acc,
+	}), nil
+}
+
+func (mp *MockProvider) MsgUpgradeClient(srcClientId string, consRes *clienttypes.QueryConsensusStateResponse, clientRes *clienttypes.QueryClientStateResponse) (provider.RelayerMessage, error) {
+	return nil, fmt.Errorf("client upgrades are %w", ErrNotSupported)
+}
+
+// RelayPacketFromSequence returns the msg relaying the packet sent by src with the given sequence: a recv
+// on dst, or a timeout on src when the header of dst at dsth is past the timeout of the packet
+func (mp *MockProvider) RelayPacketFromSequence(src, dst provider.ChainProvider, srch, dsth, seq uint64, dstChanId, dstPortId, srcChanId, srcPortId, srcClientId string) (provider.RelayerMessage, provider.RelayerMessage, error) {
+	pkt, err := src.QuerySendPacket(srcChanId, srcPortId, seq)
+	if err != nil {
+		return nil, nil, err
+	}
+	header, err := dst.GetIBCUpdateHeader(int64(dsth), src, srcClientId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	timeout := pkt.Timeout()
+	switch {
+	case !timeout.IsZero() && header.GetHeight().GTE(timeout),
+		pkt.TimeoutStamp() != 0 && uint64(header.(*tmclient.Header).GetTime().UnixNano()) >= pkt.TimeoutStamp():
+		msg, err := src.MsgRelayTimeout(dst, int64(dsth), pkt, dstChanId, dstPortId, srcChanId, srcPortId)
+		return nil, msg, err
+	default:
+		msg, err := dst.MsgRelayRecvPacket(src, int64(srch), pkt, srcChanId, srcPortId, dstChanId, dstPortId)
+		return msg, nil, err
+	}
+}
+
+// AcknowledgementFromSequence returns the msg relaying the acknowledgement dst wrote for the packet
+// with the given sequence, dst must be a mock provider as well
+func (mp *MockProvider) AcknowledgementFromSequence(dst provider.ChainProvider, dsth, seq uint64, dstChanId, dstPortId, srcChanId, srcPortId string) (provider.RelayerMessage, error) {
+	counterparty, ok := dst.(*MockProvider)
+	if !ok {
+		return nil, fmt.Errorf("got provider of type %T but wanted *MockProvider", dst)
+	}
+	id := packetID{portChannel{dstPortId, dstChanId}, seq}
+	for _, res := range counterparty.chain.allTxs() {
+		for _, ev := range res.events {
+			if ev.Type != chantypes.EventTypeWriteAck || !hasAttributes(ev.Attributes,
+				chantypes.AttributeKeyDstPort, id.port,
+				chantypes.AttributeKeyDstChannel, id.channel,
+				chantypes.AttributeKeySequence, fmt.Sprint(seq)) {
+				continue
+			}
+			rp, err := relayPacketFromEvent(ev.Attributes)
+			if err != nil {
+				return nil, err
+			}
+			return mp.MsgRelayAcknowledgement(dst, dstChanId, dstPortId, srcChanId, srcPortId, int64(dsth), rp)
+		}
+	}
+	return nil, fmt.Errorf("no acknowledgement written for [%s]chan{%s}port{%s} seq(%d)", dst.ChainId(), dstChanId, dstPortId, seq)
+}
+
+func (mp *MockProvider) SubscribeEvents(ctx context.Context, query string) (<-chan ctypes.ResultEvent, error) {
+	return nil, fmt.Errorf("event subscriptions are %w", ErrNotSupported)
+}
+
+func (mp *MockProvider) SendMessage(msg provider.RelayerMessage) (*provider.RelayerTxResponse, bool, error) {
+	return mp.SendMessages([]provider.RelayerMessage{msg})
+}
+
+// SendMessages executes msgs in a new block of the chain. As on a cosmos chain, a tx with a failing msg is
+// committed but returns an error.
+func (mp *MockProvider) SendMessages(msgs []provider.RelayerMessage) (*provider.RelayerTxResponse, bool, error) {
+	txHash, err := mp.BroadcastMessages(msgs, mp.NextKey(), 0, 1)
+	if err != nil {
+		return nil, false, err
+	}
+	return mp.WaitForTx(txHash, defaultTxCommitTimeout)
+}
+
+// BroadcastMessages executes msgs in a new block of the chain and returns the hash of the tx, the key
+// and gas are not used as txs of a mock chain are not signed and pay no fees
+func (mp *MockProvider) BroadcastMessages(msgs []provider.RelayerMessage, key string, gas uint64, feeBump float64) (string, error) {
+	txMsgs, err := sdkMsgs(msgs)
+	if err != nil {
+		return "", err
+	}
+	res, err := mp.chain.deliverTx(txMsgs)
+	if err != nil {
+		return "", err
+	}
+	return res.hash, nil
+}
+
+// SimulateMessages executes msgs on top of the latest block without committing them. Txs of a mock chain
+// pay no fees.
+func (mp *MockProvider) SimulateMessages(msgs []provider.RelayerMessage, key string) (uint64, sdk.Coins, error) {
+	txMsgs, err := sdkMsgs(msgs)
+	if err != nil {
+		return 0, nil, err
+	}
+	gas, err := mp.chain.simulateTx(txMsgs)
+	if err != nil {
+		return 0, nil, err
+	}
+	return uint64(gas), sdk.NewCoins(), nil
+}
+
+// sdkMsgs returns the sdk msgs of the MockMessages of a tx
+func sdkMsgs(msgs []provider.RelayerMessage) ([]sdk.Msg, error) {
+	if len(msgs) == 0 {
+		return nil, errors.New("cannot send a tx without msgs")
+	}
+	out := make([]sdk.Msg, 0, len(msgs))
+	for _, msg := range msgs {
+		mm, ok := msg.(MockMessage)
+		if !ok {
+			return nil, fmt.Errorf("got msg of type %T but wanted MockMessage", msg)
+		}
+		out = append(out, mm.Msg)
+	}
+	return out, nil
+}
+
+// WaitForTx returns the result of a tx, txs are committed as soon as they are broadcast
+func (mp *MockProvider) WaitForTx(txHash string, timeout time.Duration) (*provider.RelayerTxResponse, bool, error) {
+	res, ok := mp.chain.tx(txHash)
+	if !ok {
+		return nil, false, fmt.Errorf("%w: %s not committed after %s", provider.ErrTxNotCommitted, txHash, timeout)
+	}
+	rlyRes := res.response()
+	if rlyRes.Code != 0 {
+		return rlyRes, false, fmt.Errorf("transaction failed with code: %d", rlyRes.Code)
+	}
+	return rlyRes, true, nil
+}
+
+// TxInMempool always returns false, a mock chain has no mempool
+func (mp *MockProvider) TxInMempool(txHash string) (bool, error) {
+	return false, nil
+}
+
+// WaitForNBlocks blocks until n blocks were committed after the latest one
+func (mp *MockProvider) WaitForNBlocks(n int64) error {
+	initial := mp.chain.Height()
+	for mp.chain.Height() <= initial+n {
+		time.Sleep(10 * time.Millisecond)
+	}
+	return nil
+}
+
+// AutoUpdateClient updates the client of dst on the chain when its latest consensus state expires within
+// thresholdTime and returns the trusting period of the client, or the time left before the update is due
+func (mp *MockProvider) AutoUpdateClient(dst provider.ChainProvider, thresholdTime time.Duration, srcClientId, dstClientId string) (time.Duration, error) {
+	cl, ok := mp.chain.latestState().clients[srcClientId]
+	if !ok {
+		return 0, fmt.Errorf("client %s not found", srcClientId)
+	}
+	if cl.clientState.TrustingPeriod <= thresholdTime {
+		return 0, fmt.Errorf("client (%s) trusting period time is less than or equal to threshold time", srcClientId)
+	}
+
+	now := mp.chain.blockTime(mp.chain.Height())
+	consensusState := cl.consensus[cl.clientState.LatestHeight]
+	timeToExpiry := consensusState.Timestamp.Add(cl.clientState.TrustingPeriod).Sub(now)
+	switch {
+	case timeToExpiry > thresholdTime:
+		return timeToExpiry, nil
+	case cl.clientState.IsExpired(consensusState.Timestamp, now):
+		return 0, fmt.Errorf("client (%s) is already expired on chain: %s", srcClientId, mp.chain.ChainID)
+	}
+
+	dsth, err := dst.QueryLatestHeight()
+	if err != nil {
+		return 0, err
+	}
+	header, err := dst.GetIBCUpdateHeader(dsth, mp, srcClientId)
+	if err != nil {
+		return 0, err
+	}
+	updateMsg, err := mp.UpdateClient(srcClientId, header)
+	if err != nil {
+		return 0, err
+	}
+	if res, success, err := mp.SendMessage(updateMsg); !success {
+		if err == nil {
+			err = fmt.Errorf("tx failed: %s", res.Data)
+		}
+		return 0, err
+	}
+	return cl.clientState.TrustingPeriod, nil
+}
+
+// FindMatchingClient returns a client of the chain that tracks counterparty with the same parameters as
+// clientState and whose latest consensus state matches the header of counterparty at its height
+func (mp *MockProvider) FindMatchingClient(counterparty provider.ChainProvider, clientState ibcexported.ClientState) (string, bool) {
+	cs, ok := clientState.(*tmclient.ClientState)
+	if !ok {
+		return "", false
+	}
+	st := mp.chain.latestState()
+	ids := make([]string, 0, len(st.clients))
+	for id := range st.clients {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	now := mp.chain.blockTime(mp.chain.Height())
+	for _, id := range ids {
+		existing := *st.clients[id].clientState
+		candidate := *cs
+		existing.LatestHeight, candidate.LatestHeight = clienttypes.ZeroHeight(), clienttypes.ZeroHeight()
+		if !proto.Equal(&existing, &candidate) {
+			continue
+		}
+
+		latest := st.clients[id].clientState.LatestHeight
+		consensusState := st.clients[id].consensus[latest]
+		if st.clients[id].clientState.IsExpired(consensusState.Timestamp, now) {
+			continue
+		}
+		header, err := counterparty.GetLightSignedHeaderAtHeight(int64(latest.RevisionHeight))
+		if err != nil {
+			continue
+		}
+		tmHeader, ok := header.(*tmclient.Header)
+		if ok && proto.Equal(consensusState, tmHeader.ConsensusState()) {
+			return id, true
+		}
+	}
+	return "", false
+}
+
+// NewClientState returns the client state the cosmos provider creates for the counterparty of a header
+func (mp *MockProvider) NewClientState(dstUpdateHeader ibcexported.Header, dstTrustingPeriod, dstUbdPeriod time.Duration, allowUpdateAfterExpiry, allowUpdateAfterMisbehaviour bool) (ibcexported.ClientState, error) {
+	dstTmHeader, ok := dstUpdateHeader.(*tmclient.Header)
+	if !ok {
+		return nil, fmt.Errorf("got data of type %T but wanted tmclient.Header", dstUpdateHeader)
+	}
+	return &tmclient.ClientState{
+		ChainId:                      dstTmHeader.GetHeader().GetChainID(),
+		TrustLevel:                   tmclient.DefaultTrustLevel,
+		TrustingPeriod:               dstTrustingPeriod,
+		UnbondingPeriod:              dstUbdPeriod,
+		MaxClockDrift:                time.Minute * 10,
+		FrozenHeight:                 clienttypes.ZeroHeight(),
+		LatestHeight:                 dstUpdateHeader.GetHeight().(clienttypes.Height),
+		ProofSpecs:                   commitmenttypes.GetSDKSpecs(),
+		UpgradePath:                  []string{"upgrade", "upgradedIBCState"},
+		AllowUpdateAfterExpiry:       allowUpdateAfterExpiry,
+		AllowUpdateAfterMisbehaviour: allowUpdateAfterMisbehaviour,
+	}, nil
+}
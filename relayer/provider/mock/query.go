@@ -0,0 +1,553 @@
+package mock
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	transfertypes "github.com/cosmos/ibc-go/v2/modules/apps/transfer/types"
+	clienttypes "github.com/cosmos/ibc-go/v2/modules/core/02-client/types"
+	conntypes "github.com/cosmos/ibc-go/v2/modules/core/03-connection/types"
+	chantypes "github.com/cosmos/ibc-go/v2/modules/core/04-channel/types"
+	commitmenttypes "github.com/cosmos/ibc-go/v2/modules/core/23-commitment/types"
+	host "github.com/cosmos/ibc-go/v2/modules/core/24-host"
+	ibcexported "github.com/cosmos/ibc-go/v2/modules/core/exported"
+	tmclient "github.com/cosmos/ibc-go/v2/modules/light-clients/07-tendermint/types"
+	"github.com/cosmos/relayer/relayer/provider"
+	abci "github.com/tendermint/tendermint/abci/types"
+	ctypes "github.com/tendermint/tendermint/rpc/core/types"
+)
+
+// QueryTx returns the tx with the given hash
+func (mp *MockProvider) QueryTx(hashHex string) (*ctypes.ResultTx, error) {
+	res, ok := mp.chain.tx(strings.ToUpper(hashHex))
+	if !ok {
+		return nil, fmt.Errorf("tx (%s) not found", hashHex)
+	}
+	return resultTx(res)
+}
+
+// QueryTxs returns the txs with events matching every given type.attribute='value' query
+func (mp *MockProvider) QueryTxs(page, limit int, events []string) ([]*ctypes.ResultTx, error) {
+	if page <= 0 {
+		return nil, fmt.Errorf("page must greater than 0")
+	}
+	if limit <= 0 {
+		return nil, fmt.Errorf("limit must greater than 0")
+	}
+
+	type condition struct{ eventType, key, value string }
+	conditions := make([]condition, 0, len(events))
+	for _, ev := range events {
+		kv := strings.SplitN(ev, "=", 2)
+		typeKey := strings.SplitN(strings.TrimSpace(kv[0]), ".", 2)
+		if len(kv) != 2 || len(typeKey) != 2 {
+			return nil, fmt.Errorf("invalid event query %s, expected type.attribute='value'", ev)
+		}
+		conditions = append(conditions, condition{typeKey[0], typeKey[1], strings.Trim(strings.TrimSpace(kv[1]), "'")})
+	}
+
+	var txs []*ctypes.ResultTx
+	for _, res := range mp.chain.allTxs() {
+		matches := true
+		for _, cond := range conditions {
+			found := false
+			for _, ev := range res.events {
+				if ev.Type == cond.eventType && hasAttributes(ev.Attributes, cond.key, cond.value) {
+					found = true
+					break
+				}
+			}
+			matches = matches && found
+		}
+		if !matches {
+			continue
+		}
+		rt, err := resultTx(res)
+		if err != nil {
+			return nil, err
+		}
+		txs = append(txs, rt)
+	}
+
+	start := (page - 1) * limit
+	if start >= len(txs) {
+		return nil, nil
+	}
+	end := start + limit
+	if end > len(txs) {
+		end = len(txs)
+	}
+	return txs[start:end], nil
+}
+
+// resultTx converts a tx to the result of a tx query
+func resultTx(res *tx) (*ctypes.ResultTx, error) {
+	hash, err := hex.DecodeString(res.hash)
+	if err != nil {
+		return nil, err
+	}
+	return &ctypes.ResultTx{
+		Hash:   hash,
+		Height: res.height,
+		TxResult: abci.ResponseDeliverTx{
+			Code:    res.code,
+			Log:     res.log,
+			GasUsed: res.gas,
+			Events:  res.events,
+		},
+	}, nil
+}
+
+func (mp *MockProvider) QueryLatestHeight() (int64, error) {
+	return mp.chain.Height(), nil
+}
+
+func (mp *MockProvider) QueryHeaderAtHeight(height int64) (ibcexported.Header, error) {
+	if height <= 0 {
+		return nil, fmt.Errorf("must pass in valid height, %d not valid", height)
+	}
+	return mp.chain.header(height)
+}
+
+func (mp *MockProvider) QueryBalance(keyName string) (sdk.Coins, error) {
+	addr, err := mp.ShowAddress(keyName)
+	if err != nil {
+		return nil, err
+	}
+	return mp.QueryBalanceWithAddress(addr)
+}
+
+func (mp *MockProvider) QueryBalanceWithAddress(addr string) (sdk.Coins, error) {
+	return mp.chain.Balance(addr), nil
+}
+
+func (mp *MockProvider) QueryUnbondingPeriod() (time.Duration, error) {
+	return mp.chain.UnbondingPeriod, nil
+}
+
+// queryProof returns the value of a key in the state committed to by the header at height, along
+// with its proof and proof height
+func (mp *MockProvider) queryProof(height int64, key []byte, value func(st *state) []byte) ([]byte, []byte, clienttypes.Height, error) {
+	st, proofHeight, err := mp.chain.stateAt(height)
+	if err != nil {
+		return nil, nil, clienttypes.Height{}, err
+	}
+	v := value(st)
+	return v, mp.chain.proof(proofHeight, key, v), proofHeight, nil
+}
+
+func (mp *MockProvider) QueryClientStateResponse(height int64, srcClientId string) (*clienttypes.QueryClientStateResponse, error) {
+	var cs tmclient.ClientState
+	value, proof, proofHeight, err := mp.queryProof(height, host.FullClientStateKey(srcClientId), func(st *state) []byte {
+		cl, ok := st.clients[srcClientId]
+		if !ok {
+			return nil
+		}
+		cs = *cl.clientState
+		return mustMarshal(&cs)
+	})
+	switch {
+	case err != nil:
+		return nil, err
+	case value == nil:
+		return nil, sdkerrors.Wrap(clienttypes.ErrClientNotFound, srcClientId)
+	}
+	anyClientState, err := clienttypes.PackClientState(&cs)
+	if err != nil {
+		return nil, err
+	}
+	return &clienttypes.QueryClientStateResponse{ClientState: anyClientState, Proof: proof, ProofHeight: proofHeight}, nil
+}
+
+func (mp *MockProvider) QueryClientState(height int64, clientid string) (ibcexported.ClientState, error) {
+	res, err := mp.QueryClientStateResponse(height, clientid)
+	if err != nil {
+		return nil, err
+	}
+	return clienttypes.UnpackClientState(res.ClientState)
+}
+
+func (mp *MockProvider) QueryClientConsensusState(chainHeight int64, clientid string, clientHeight ibcexported.Height) (*clienttypes.QueryConsensusStateResponse, error) {
+	var cs tmclient.ConsensusState
+	height := clienttypes.NewHeight(clientHeight.GetRevisionNumber(), clientHeight.GetRevisionHeight())
+	value, proof, proofHeight, err := mp.queryProof(chainHeight, host.FullConsensusStateKey(clientid, height), func(st *state) []byte {
+		cl, ok := st.clients[clientid]
+		if !ok || cl.consensus[height] == nil {
+			return nil
+		}
+		cs = *cl.consensus[height]
+		return mustMarshal(&cs)
+	})
+	switch {
+	case err != nil:
+		return nil, err
+	case value == nil:
+		return nil, sdkerrors.Wrap(clienttypes.ErrConsensusStateNotFound, clientid)
+	}
+	anyConsensusState, err := clienttypes.PackConsensusState(&cs)
+	if err != nil {
+		return nil, err
+	}
+	return &clienttypes.QueryConsensusStateResponse{ConsensusState: anyConsensusState, Proof: proof, ProofHeight: proofHeight}, nil
+}
+
+func (mp *MockProvider) QueryUpgradedClient(height int64) (*clienttypes.QueryClientStateResponse, error) {
+	return nil, fmt.Errorf("client upgrades are %w", ErrNotSupported)
+}
+
+func (mp *MockProvider) QueryUpgradedConsState(height int64) (*clienttypes.QueryConsensusStateResponse, error) {
+	return nil, fmt.Errorf("client upgrades are %w", ErrNotSupported)
+}
+
+// QueryConsensusState returns the consensus state a client of the chain holds for the header at
+// height, the latest one when height is 0
+func (mp *MockProvider) QueryConsensusState(height int64) (ibcexported.ConsensusState, int64, error) {
+	if height == 0 {
+		height = mp.chain.Height()
+	}
+	h, err := mp.chain.header(height)
+	if err != nil {
+		return &tmclient.ConsensusState{}, 0, err
+	}
+	return h.ConsensusState(), height, nil
+}
+
+func (mp *MockProvider) QueryClients() (clienttypes.IdentifiedClientStates, error) {
+	st := mp.chain.latestState()
+	clients := make(clienttypes.IdentifiedClientStates, 0, len(st.clients))
+	for id, cl := range st.clients {
+		cs := *cl.clientState
+		clients = append(clients, clienttypes.NewIdentifiedClientState(id, &cs))
+	}
+	return clients.Sort(), nil
+}
+
+func (mp *MockProvider) QueryConnection(height int64, connectionid string) (*conntypes.QueryConnectionResponse, error) {
+	var conn conntypes.ConnectionEnd
+	value, proof, proofHeight, err := mp.queryProof(height, host.ConnectionKey(connectionid), func(st *state) []byte {
+		var ok bool
+		if conn, ok = st.connections[connectionid]; !ok {
+			return nil
+		}
+		return mustMarshal(&conn)
+	})
+	switch {
+	case err != nil:
+		return nil, err
+	case value == nil:
+		// the cosmos provider returns an uninitialized connection for missing connections
+		return &conntypes.QueryConnectionResponse{
+			Connection: &conntypes.ConnectionEnd{
+				ClientId: "client",
+				Versions: []*conntypes.Version{},
+				State:    conntypes.UNINITIALIZED,
+				Counterparty: conntypes.Counterparty{
+					ClientId:     "client",
+					ConnectionId: "connection",
+					Prefix:       commitmenttypes.MerklePrefix{KeyPrefix: []byte{}},
+				},
+			},
+			Proof:       []byte{},
+			ProofHeight: clienttypes.ZeroHeight(),
+		}, nil
+	}
+	return &conntypes.QueryConnectionResponse{Connection: &conn, Proof: proof, ProofHeight: proofHeight}, nil
+}
+
+func (mp *MockProvider) QueryConnections() ([]*conntypes.IdentifiedConnection, error) {
+	st := mp.chain.latestState()
+	conns := make([]*conntypes.IdentifiedConnection, 0, len(st.connections))
+	for id, conn := range st.connections {
+		identified := conntypes.NewIdentifiedConnection(id, conn)
+		conns = append(conns, &identified)
+	}
+	sort.Slice(conns, func(i, j int) bool {
+		return conns[i].Id < conns[j].Id
+	})
+	return conns, nil
+}
+
+func (mp *MockProvider) QueryConnectionsUsingClient(height int64, clientid string) (*conntypes.QueryConnectionsResponse, error) {
+	conns, err := mp.QueryConnections()
+	if err != nil {
+		return nil, err
+	}
+	res := &conntypes.QueryConnectionsResponse{Height: clienttypes.NewHeight(mp.chain.revision(), uint64(mp.chain.Height()))}
+	for _, conn := range conns {
+		if conn.ClientId == clientid {
+			res.Connections = append(res.Connections, conn)
+		}
+	}
+	return res, nil
+}
+
+// GenerateConnHandshakeProof generates all the proofs needed to prove the existence of the
+// connection state on this chain, like the cosmos provider does
+func (mp *MockProvider) GenerateConnHandshakeProof(height int64, clientId, connId string) (ibcexported.ClientState, []byte, []byte, []byte, ibcexported.Height, error) {
+	clientStateRes, err := mp.QueryClientStateResponse(height, clientId)
+	if err != nil {
+		return nil, nil, nil, nil, clienttypes.Height{}, err
+	}
+	clientState, err := clienttypes.UnpackClientState(clientStateRes.ClientState)
+	if err != nil {
+		return nil, nil, nil, nil, clienttypes.Height{}, err
+	}
+	consensusStateRes, err := mp.QueryClientConsensusState(height, clientId, clientState.GetLatestHeight())
+	if err != nil {
+		return nil, nil, nil, nil, clienttypes.Height{}, err
+	}
+	connectionStateRes, err := mp.QueryConnection(height, connId)
+	if err != nil {
+		return nil, nil, nil, nil, clienttypes.Height{}, err
+	}
+	return clientState, clientStateRes.Proof, consensusStateRes.Proof, connectionStateRes.Proof, connectionStateRes.ProofHeight, nil
+}
+
+func (mp *MockProvider) QueryChannel(height int64, channelid, portid string) (*chantypes.QueryChannelResponse, error) {
+	var channel chantypes.Channel
+	value, proof, proofHeight, err := mp.queryProof(height, host.ChannelKey(portid, channelid), func(st *state) []byte {
+		var ok bool
+		if channel, ok = st.channels[portChannel{portid, channelid}]; !ok {
+			return nil
+		}
+		return mustMarshal(&channel)
+	})
+	switch {
+	case err != nil:
+		return nil, err
+	case value == nil:
+		// the cosmos provider returns an uninitialized channel for missing channels
+		return &chantypes.QueryChannelResponse{
+			Channel: &chantypes.Channel{
+				State:          chantypes.UNINITIALIZED,
+				Ordering:       chantypes.UNORDERED,
+				Counterparty:   chantypes.NewCounterparty("port", "channel"),
+				ConnectionHops: []string{},
+				Version:        "version",
+			},
+			Proof:       []byte{},
+			ProofHeight: clienttypes.ZeroHeight(),
+		}, nil
+	}
+	return &chantypes.QueryChannelResponse{Channel: &channel, Proof: proof, ProofHeight: proofHeight}, nil
+}
+
+func (mp *MockProvider) QueryChannelClient(height int64, channelid, portid string) (*clienttypes.IdentifiedClientState, error) {
+	st := mp.chain.latestState()
+	channel, ok := st.channels[portChannel{portid, channelid}]
+	if !ok {
+		return nil, sdkerrors.Wrapf(chantypes.ErrChannelNotFound, "port-id: %s, channel-id: %s", portid, channelid)
+	}
+	conn, err := st.channelConnection(channel.ConnectionHops)
+	if err != nil {
+		return nil, err
+	}
+	cl, ok := st.clients[conn.ClientId]
+	if !ok {
+		return nil, sdkerrors.Wrap(clienttypes.ErrClientNotFound, conn.ClientId)
+	}
+	cs := *cl.clientState
+	identified := clienttypes.NewIdentifiedClientState(conn.ClientId, &cs)
+	return &identified, nil
+}
+
+// channels returns the channels of the latest state matching keep, ordered by channel id
+func (mp *MockProvider) channels(keep func(chantypes.Channel) bool) []*chantypes.IdentifiedChannel {
+	st := mp.chain.latestState()
+	var channels []*chantypes.IdentifiedChannel
+	for pc, channel := range st.channels {
+		if keep(channel) {
+			identified := chantypes.NewIdentifiedChannel(pc.port, pc.channel, channel)
+			channels = append(channels, &identified)
+		}
+	}
+	sort.Slice(channels, func(i, j int) bool {
+		if channels[i].ChannelId != channels[j].ChannelId {
+			return channels[i].ChannelId < channels[j].ChannelId
+		}
+		return channels[i].PortId < channels[j].PortId
+	})
+	return channels
+}
+
+func (mp *MockProvider) QueryConnectionChannels(height int64, connectionid string) ([]*chantypes.IdentifiedChannel, error) {
+	return mp.channels(func(channel chantypes.Channel) bool {
+		return len(channel.ConnectionHops) > 0 && channel.ConnectionHops[0] == connectionid
+	}), nil
+}
+
+func (mp *MockProvider) QueryChannels() ([]*chantypes.IdentifiedChannel, error) {
+	return mp.channels(func(chantypes.Channel) bool { return true }), nil
+}
+
+// packetStates returns the values of a packet map of the latest state on a channel, ordered by sequence
+func (mp *MockProvider) packetStates(channelid, portid string, values func(st *state) map[packetID][]byte) []*chantypes.PacketState {
+	pc := portChannel{portid, channelid}
+	states := []*chantypes.PacketState{}
+	for id, data := range values(mp.chain.latestState()) {
+		if id.portChannel == pc {
+			ps := chantypes.NewPacketState(portid, channelid, id.seq, data)
+			states = append(states, &ps)
+		}
+	}
+	sort.Slice(states, func(i, j int) bool {
+		return states[i].Sequence < states[j].Sequence
+	})
+	return states
+}
+
+func (mp *MockProvider) QueryPacketCommitments(height uint64, channelid, portid string) (*chantypes.QueryPacketCommitmentsResponse, error) {
+	return &chantypes.QueryPacketCommitmentsResponse{
+		Commitments: mp.packetStates(channelid, portid, func(st *state) map[packetID][]byte { return st.commitments }),
+		Height:      clienttypes.NewHeight(mp.chain.revision(), uint64(mp.chain.Height())),
+	}, nil
+}
+
+// QueryPacketAcknowledgements returns the acknowledgement commitments written on a channel
+func (mp *MockProvider) QueryPacketAcknowledgements(height uint64, channelid, portid string) ([]*chantypes.PacketState, error) {
+	return mp.packetStates(channelid, portid, func(st *state) map[packetID][]byte {
+		acks := make(map[packetID][]byte, len(st.acks))
+		for id, ack := range st.acks {
+			acks[id] = chantypes.CommitAcknowledgement(ack)
+		}
+		return acks
+	}), nil
+}
+
+// QueryUnreceivedPackets returns the sequences the chain has no receipt for
+func (mp *MockProvider) QueryUnreceivedPackets(height uint64, channelid, portid string, seqs []uint64) ([]uint64, error) {
+	st := mp.chain.latestState()
+	unreceived := []uint64{}
+	for _, seq := range seqs {
+		if !st.receipts[packetID{portChannel{portid, channelid}, seq}] {
+			unreceived = append(unreceived, seq)
+		}
+	}
+	return unreceived, nil
+}
+
+// QueryUnreceivedAcknowledgements returns the sequences of the packets sent by the chain that are
+// still waiting for their acknowledgement
+func (mp *MockProvider) QueryUnreceivedAcknowledgements(height uint64, channelid, portid string, seqs []uint64) ([]uint64, error) {
+	st := mp.chain.latestState()
+	unreceived := []uint64{}
+	for _, seq := range seqs {
+		if _, ok := st.commitments[packetID{portChannel{portid, channelid}, seq}]; ok {
+			unreceived = append(unreceived, seq)
+		}
+	}
+	return unreceived, nil
+}
+
+func (mp *MockProvider) QueryNextSeqRecv(height int64, channelid, portid string) (*chantypes.QueryNextSequenceReceiveResponse, error) {
+	value, proof, proofHeight, err := mp.queryProof(height, host.NextSequenceRecvKey(portid, channelid), func(st *state) []byte {
+		seq, ok := st.nextSeqRecv[portChannel{portid, channelid}]
+		if !ok {
+			return nil
+		}
+		return sdk.Uint64ToBigEndian(seq)
+	})
+	switch {
+	case err != nil:
+		return nil, err
+	case value == nil:
+		return nil, sdkerrors.Wrapf(chantypes.ErrChannelNotFound, "portID (%s), channelID (%s)", portid, channelid)
+	}
+	return &chantypes.QueryNextSequenceReceiveResponse{
+		NextSequenceReceive: binary.BigEndian.Uint64(value),
+		Proof:               proof,
+		ProofHeight:         proofHeight,
+	}, nil
+}
+
+func (mp *MockProvider) QueryPacketCommitment(height int64, channelid, portid string, seq uint64) (*chantypes.QueryPacketCommitmentResponse, error) {
+	value, proof, proofHeight, err := mp.queryProof(height, host.PacketCommitmentKey(portid, channelid, seq), func(st *state) []byte {
+		return st.commitments[packetID{portChannel{portid, channelid}, seq}]
+	})
+	switch {
+	case err != nil:
+		return nil, err
+	case value == nil:
+		return nil, sdkerrors.Wrapf(chantypes.ErrPacketCommitmentNotFound, "portID (%s), channelID (%s), sequence (%d)", portid, channelid, seq)
+	}
+	return &chantypes.QueryPacketCommitmentResponse{Commitment: value, Proof: proof, ProofHeight: proofHeight}, nil
+}
+
+// QueryPacketAcknowledgement returns the commitment of the acknowledgement of a packet, as stored by a
+// cosmos chain
+func (mp *MockProvider) QueryPacketAcknowledgement(height int64, channelid, portid string, seq uint64) (*chantypes.QueryPacketAcknowledgementResponse, error) {
+	value, proof, proofHeight, err := mp.queryProof(height, host.PacketAcknowledgementKey(portid, channelid, seq), func(st *state) []byte {
+		ack, ok := st.acks[packetID{portChannel{portid, channelid}, seq}]
+		if !ok {
+			return nil
+		}
+		return chantypes.CommitAcknowledgement(ack)
+	})
+	switch {
+	case err != nil:
+		return nil, err
+	case value == nil:
+		return nil, sdkerrors.Wrapf(chantypes.ErrInvalidAcknowledgement, "portID (%s), channelID (%s), sequence (%d)", portid, channelid, seq)
+	}
+	return &chantypes.QueryPacketAcknowledgementResponse{Acknowledgement: value, Proof: proof, ProofHeight: proofHeight}, nil
+}
+
+// QueryPacketReceipt returns whether a packet was received, with a proof of the receipt or of its absence
+func (mp *MockProvider) QueryPacketReceipt(height int64, channelid, portid string, seq uint64) (*chantypes.QueryPacketReceiptResponse, error) {
+	value, proof, proofHeight, err := mp.queryProof(height, host.PacketReceiptKey(portid, channelid, seq), func(st *state) []byte {
+		if !st.receipts[packetID{portChannel{portid, channelid}, seq}] {
+			return nil
+		}
+		return []byte{byte(1)}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &chantypes.QueryPacketReceiptResponse{Received: value != nil, Proof: proof, ProofHeight: proofHeight}, nil
+}
+
+// QuerySendPacket returns the packet sent on a channel with the given sequence
+func (mp *MockProvider) QuerySendPacket(channelid, portid string, seq uint64) (provider.RelayPacket, error) {
+	packet, ok := mp.chain.latestState().sent[packetID{portChannel{portid, channelid}, seq}]
+	if !ok {
+		return nil, fmt.Errorf("no send_packet event found for [%s]chan{%s}port{%s} seq(%d)", mp.ChainId(), channelid, portid, seq)
+	}
+	return &relayPacket{
+		packetData:   packet.Data,
+		seq:          packet.Sequence,
+		timeout:      packet.TimeoutHeight,
+		timeoutStamp: packet.TimeoutTimestamp,
+	}, nil
+}
+
+// QueryDenomTrace returns the denomination trace of an ibc denom, given by its hash
+func (mp *MockProvider) QueryDenomTrace(denom string) (*transfertypes.DenomTrace, error) {
+	trace, ok := mp.chain.latestState().traces[strings.TrimPrefix(denom, "ibc/")]
+	if !ok {
+		return nil, sdkerrors.Wrap(transfertypes.ErrTraceNotFound, denom)
+	}
+	return &trace, nil
+}
+
+func (mp *MockProvider) QueryDenomTraces(offset, limit uint64, height int64) ([]transfertypes.DenomTrace, error) {
+	st := mp.chain.latestState()
+	traces := make(transfertypes.Traces, 0, len(st.traces))
+	for _, trace := range st.traces {
+		traces = append(traces, trace)
+	}
+	traces = traces.Sort()
+	if offset >= uint64(len(traces)) {
+		return []transfertypes.DenomTrace{}, nil
+	}
+	end := uint64(len(traces))
+	if limit != 0 && offset+limit < end {
+		end = offset + limit
+	}
+	return traces[offset:end], nil
+}
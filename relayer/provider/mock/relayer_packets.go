@@ -0,0 +1,147 @@
+package mock
+
+import (
+	"fmt"
+	"strconv"
+
+	clienttypes "github.com/cosmos/ibc-go/v2/modules/core/02-client/types"
+	chantypes "github.com/cosmos/ibc-go/v2/modules/core/04-channel/types"
+	"github.com/cosmos/relayer/relayer/provider"
+	abci "github.com/tendermint/tendermint/abci/types"
+)
+
+var _ provider.RelayPacket = &relayPacket{}
+
+// relayPacket is a packet found in the send_packet or write_acknowledgement event of a tx, along with
+// its acknowledgement in the latter case
+type relayPacket struct {
+	packetData   []byte
+	ack          []byte
+	seq          uint64
+	timeout      clienttypes.Height
+	timeoutStamp uint64
+
+	// proof holds the commitment, receipt or acknowledgement proof fetched by FetchCommitResponse
+	proof       []byte
+	proofHeight clienttypes.Height
+}
+
+func (rp *relayPacket) Data() []byte {
+	return rp.packetData
+}
+
+func (rp *relayPacket) Seq() uint64 {
+	return rp.seq
+}
+
+func (rp *relayPacket) Timeout() clienttypes.Height {
+	return rp.timeout
+}
+
+func (rp *relayPacket) TimeoutStamp() uint64 {
+	return rp.timeoutStamp
+}
+
+// packet returns the packet sent from the src port and channel to the dst ones
+func (rp *relayPacket) packet(srcPortId, srcChanId, dstPortId, dstChanId string) chantypes.Packet {
+	return packetOf(rp, srcPortId, srcChanId, dstPortId, dstChanId)
+}
+
+// FetchCommitResponse fetches the proof of the packet commitment on dst, or of its acknowledgement
+func (rp *relayPacket) FetchCommitResponse(dst provider.ChainProvider, queryHeight uint64, dstChanId, dstPortId string) error {
+	if rp.ack != nil {
+		res, err := dst.QueryPacketAcknowledgement(int64(queryHeight)-1, dstChanId, dstPortId, rp.seq)
+		if err != nil {
+			return err
+		}
+		rp.proof, rp.proofHeight = res.Proof, res.ProofHeight
+		return nil
+	}
+	res, err := dst.QueryPacketCommitment(int64(queryHeight)-1, dstChanId, dstPortId, rp.seq)
+	if err != nil {
+		return err
+	}
+	rp.proof, rp.proofHeight = res.Proof, res.ProofHeight
+	return nil
+}
+
+// Msg returns the recv msg of the packet, or its acknowledgement msg, from the proof fetched by
+// FetchCommitResponse
+func (rp *relayPacket) Msg(src provider.ChainProvider, srcPortId, srcChanId, dstPortId, dstChanId string) (provider.RelayerMessage, error) {
+	if rp.proof == nil {
+		return nil, fmt.Errorf("packet [%s]seq{%d} has no associated proofs", src.ChainId(), rp.seq)
+	}
+	addr, err := src.Address()
+	if err != nil {
+		return nil, err
+	}
+	if rp.ack != nil {
+		return NewMockMessage(&chantypes.MsgAcknowledgement{
+			Packet:          rp.packet(srcPortId, srcChanId, dstPortId, dstChanId),
+			Acknowledgement: rp.ack,
+			ProofAcked:      rp.proof,
+			ProofHeight:     rp.proofHeight,
+			Signer:          addr,
+		}), nil
+	}
+	return NewMockMessage(&chantypes.MsgRecvPacket{
+		Packet:          rp.packet(dstPortId, dstChanId, srcPortId, srcChanId),
+		ProofCommitment: rp.proof,
+		ProofHeight:     rp.proofHeight,
+		Signer:          addr,
+	}), nil
+}
+
+// packetOf returns the packet a RelayPacket was sent as
+func packetOf(rp provider.RelayPacket, srcPortId, srcChanId, dstPortId, dstChanId string) chantypes.Packet {
+	return chantypes.NewPacket(rp.Data(), rp.Seq(), srcPortId, srcChanId, dstPortId, dstChanId, rp.Timeout(), rp.TimeoutStamp())
+}
+
+// relayPacketFromEvent reads a packet from the attributes of a send_packet or write_acknowledgement event
+func relayPacketFromEvent(attrs []abci.EventAttribute) (*relayPacket, error) {
+	rp := &relayPacket{}
+	for _, attr := range attrs {
+		switch string(attr.Key) {
+		case chantypes.AttributeKeyData:
+			rp.packetData = attr.Value
+		case chantypes.AttributeKeyAck:
+			rp.ack = attr.Value
+		case chantypes.AttributeKeySequence:
+			seq, err := strconv.ParseUint(string(attr.Value), 10, 64)
+			if err != nil {
+				return nil, err
+			}
+			rp.seq = seq
+		case chantypes.AttributeKeyTimeoutHeight:
+			timeout, err := clienttypes.ParseHeight(string(attr.Value))
+			if err != nil {
+				return nil, err
+			}
+			rp.timeout = timeout
+		case chantypes.AttributeKeyTimeoutTimestamp:
+			timeoutStamp, err := strconv.ParseUint(string(attr.Value), 10, 64)
+			if err != nil {
+				return nil, err
+			}
+			rp.timeoutStamp = timeoutStamp
+		}
+	}
+	return rp, nil
+}
+
+// hasAttributes reports whether attrs holds every given key value pair
+func hasAttributes(attrs []abci.EventAttribute, kvs ...string) bool {
+	for i := 0; i+1 < len(kvs); i += 2 {
+		found := false
+		for _, attr := range attrs {
+			if string(attr.Key) == kvs[i] && string(attr.Value) == kvs[i+1] {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
@@ -1,17 +1,28 @@
 package provider
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"time"
 
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	transfertypes "github.com/cosmos/ibc-go/v2/modules/apps/transfer/types"
 	clienttypes "github.com/cosmos/ibc-go/v2/modules/core/02-client/types"
 	conntypes "github.com/cosmos/ibc-go/v2/modules/core/03-connection/types"
 	chantypes "github.com/cosmos/ibc-go/v2/modules/core/04-channel/types"
 	ibcexported "github.com/cosmos/ibc-go/v2/modules/core/exported"
+	"github.com/tendermint/tendermint/libs/log"
 	ctypes "github.com/tendermint/tendermint/rpc/core/types"
 )
 
+// ErrTxNotCommitted is returned when a broadcast transaction is not found in a block before a timeout
+var ErrTxNotCommitted = errors.New("transaction not committed")
+
+// ErrDryRun is returned in place of the result of a transaction that was only simulated for a dry run
+var ErrDryRun = errors.New("dry run, transaction not broadcast")
+
 type ProviderConfig interface {
 	NewProvider(homepath string, debug bool) (ChainProvider, error)
 	Validate() error
@@ -23,11 +34,32 @@ type RelayerMessage interface {
 }
 
 type RelayerTxResponse struct {
-	Height int64
-	TxHash string
-	Code   uint32
-	Data   string
-	Events map[string]string
+	Height  int64
+	TxHash  string
+	Code    uint32
+	Data    string
+	GasUsed int64
+	Fee     sdk.Coins
+	Events  map[string]string
+}
+
+// OfflineTx is a transaction generated for signing out of band. Tx is the transaction encoded as JSON,
+// unsigned until SignTx signs it with the key of Signer, whose account number and sequence the signature
+// commits to.
+type OfflineTx struct {
+	ChainID       string          `json:"chain-id"`
+	Signer        string          `json:"signer"`
+	AccountNumber uint64          `json:"account-number"`
+	Sequence      uint64          `json:"sequence"`
+	Tx            json.RawMessage `json:"tx"`
+}
+
+// Signer holds the private keys of a chain out of the relayer, e.g. in a KMS, and signs on its behalf
+type Signer interface {
+	// PubKey returns the public key of the named key
+	PubKey(key string) (cryptotypes.PubKey, error)
+	// Sign signs msg with the named key
+	Sign(key string, msg []byte) ([]byte, error)
 }
 
 type KeyProvider interface {
@@ -35,11 +67,15 @@ type KeyProvider interface {
 	KeystoreCreated(path string) bool
 	AddKey(name string) (output *KeyOutput, err error)
 	RestoreKey(name, mnemonic string) (address string, err error)
+	AddLedgerKey(name string, coinType, account, index uint32) (address string, err error)
 	ShowAddress(name string) (address string, err error)
 	ListAddresses() (map[string]string, error)
 	DeleteKey(name string) error
 	KeyExists(name string) bool
 	ExportPrivKeyArmor(keyName string) (armor string, err error)
+	KeyPool() []string
+	SetKeyPool(keys []string)
+	NextKey() string
 }
 
 type ChainProvider interface {
@@ -48,7 +84,7 @@ type ChainProvider interface {
 
 	Init() error
 	CreateClient(clientState ibcexported.ClientState, dstHeader ibcexported.Header) (RelayerMessage, error)
-	SubmitMisbehavior( /*TODO TBD*/ ) (RelayerMessage, error)
+	SubmitMisbehavior(clientId string, misbehaviour ibcexported.Misbehaviour) (RelayerMessage, error)
 	UpdateClient(srcClientId string, dstHeader ibcexported.Header) (RelayerMessage, error)
 	ConnectionOpenInit(srcClientId, dstClientId string, dstHeader ibcexported.Header) ([]RelayerMessage, error)
 	ConnectionOpenTry(dstQueryProvider QueryProvider, dstHeader ibcexported.Header, srcClientId, dstClientId, srcConnId, dstConnId string) ([]RelayerMessage, error)
@@ -62,6 +98,10 @@ type ChainProvider interface {
 	ChannelCloseConfirm(dstQueryProvider QueryProvider, dsth int64, dstChanId, dstPortId, srcPortId, srcChanId string) (RelayerMessage, error)
 
 	MsgRelayAcknowledgement(dst ChainProvider, dstChanId, dstPortId, srcChanId, srcPortId string, dsth int64, packet RelayPacket) (RelayerMessage, error)
+	MsgSend(dstAddr string, amount sdk.Coins) (RelayerMessage, error)
+	MsgGrantAllowance(grantee string, spendLimit sdk.Coins, expiration *time.Time) (RelayerMessage, error)
+	MsgRevokeAllowance(grantee string) (RelayerMessage, error)
+	MsgGrantRelayAuthorizations(grantee string, expiration time.Time) ([]RelayerMessage, error)
 	MsgTransfer(amount sdk.Coin, dstChainId, dstAddr, srcPortId, srcChanId string, timeoutHeight, timeoutTimestamp uint64) (RelayerMessage, error)
 	MsgRelayTimeout(dst ChainProvider, dsth int64, packet RelayPacket, dstChanId, dstPortId, srcChanId, srcPortId string) (RelayerMessage, error)
 	MsgRelayRecvPacket(dst ChainProvider, dsth int64, packet RelayPacket, dstChanId, dstPortId, srcChanId, srcPortId string) (RelayerMessage, error)
@@ -71,10 +111,24 @@ type ChainProvider interface {
 
 	SendMessage(msg RelayerMessage) (*RelayerTxResponse, bool, error)
 	SendMessages(msgs []RelayerMessage) (*RelayerTxResponse, bool, error)
+	SimulateMessages(msgs []RelayerMessage, key string) (gas uint64, fee sdk.Coins, err error)
+	GenerateTx(msgs []RelayerMessage) (*OfflineTx, error)
+	SignTx(tx *OfflineTx) error
+	BroadcastTx(tx *OfflineTx) (*RelayerTxResponse, bool, error)
+	BroadcastMessages(msgs []RelayerMessage, key string, gas uint64, feeBump float64) (txHash string, err error)
+	WaitForTx(txHash string, timeout time.Duration) (*RelayerTxResponse, bool, error)
+	TxInMempool(txHash string) (bool, error)
 
 	GetLightSignedHeaderAtHeight(h int64) (ibcexported.Header, error)
+	SetLightCacheSize(size int)
+	SetLogger(logger log.Logger)
+	SetFeeGranter(granter string)
+	FeeGranter() string
+	SetAuthzGranter(granter string)
 	GetIBCUpdateHeader(srch int64, dst ChainProvider, dstClientId string) (ibcexported.Header, error)
 
+	SubscribeEvents(ctx context.Context, query string) (<-chan ctypes.ResultEvent, error)
+
 	ChainId() string
 	Type() string
 	ProviderConfig() ProviderConfig
@@ -133,6 +187,7 @@ type QueryProvider interface {
 	QueryPacketCommitment(height int64, channelid, portid string, seq uint64) (comRes *chantypes.QueryPacketCommitmentResponse, err error)
 	QueryPacketAcknowledgement(height int64, channelid, portid string, seq uint64) (ackRes *chantypes.QueryPacketAcknowledgementResponse, err error)
 	QueryPacketReceipt(height int64, channelid, portid string, seq uint64) (recRes *chantypes.QueryPacketReceiptResponse, err error)
+	QuerySendPacket(channelid, portid string, seq uint64) (RelayPacket, error)
 
 	// ics 20 - transfer
 	QueryDenomTrace(denom string) (*transfertypes.DenomTrace, error)
@@ -26,7 +26,11 @@ func QueryLatestHeights(src, dst *Chain) (srch, dsth int64, err error) {
 		dsth, err = dst.ChainProvider.QueryLatestHeight()
 		return err
 	})
-	err = eg.Wait()
+	if err = eg.Wait(); err != nil {
+		return
+	}
+	Metrics.LatestHeight.WithLabelValues(src.ChainID()).Set(float64(srch))
+	Metrics.LatestHeight.WithLabelValues(dst.ChainID()).Set(float64(dsth))
 	return
 }
 
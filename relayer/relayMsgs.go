@@ -4,7 +4,10 @@ import (
 	"fmt"
 	"strings"
 
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	clienttypes "github.com/cosmos/ibc-go/v2/modules/core/02-client/types"
 	"github.com/cosmos/relayer/relayer/provider"
+	"golang.org/x/sync/errgroup"
 )
 
 // DeliverMsgsAction is struct
@@ -29,6 +32,9 @@ type RelayMsgs struct {
 
 	Last      bool `json:"last"`
 	Succeeded bool `json:"success"`
+
+	// Results holds the outcome of every tx sent by the last call to Send
+	Results []*TxResult `json:"-"`
 }
 
 // NewRelayMsgs returns an initialized version of relay messages
@@ -117,56 +123,119 @@ func (r *RelayMsgs) SendWithController(src, dst *Chain, useController bool) {
 		}
 	}
 
-	//nolint:prealloc // can not be pre allocated
+	r.Results = nil
+	path := pathLabel(src, dst)
+
+	// the batches for src and dst are sent at the same time, while the batches for one chain are
+	// broadcast in order since the later ones rely on the client update at the start of the first
 	var (
-		msgLen, txSize uint64
-		msgs           []provider.RelayerMessage
+		eg                     = new(errgroup.Group)
+		srcResults, dstResults []*TxResult
 	)
-
+	eg.Go(func() error {
+		srcResults = r.sendBatches(path, src, r.Src)
+		return nil
+	})
+	eg.Go(func() error {
+		dstResults = r.sendBatches(path, dst, r.Dst)
+		return nil
+	})
+	_ = eg.Wait()
+
+	r.Results = append(srcResults, dstResults...)
 	r.Succeeded = true
+	for _, res := range r.Results {
+		r.Succeeded = r.Succeeded && res.Outcome == TxCommitted
+	}
+}
 
-	// submit batches of relay transactions
-	for _, msg := range r.Src {
-		if msg != nil {
-			bz, err := msg.MsgBytes()
-			if err != nil {
-				panic(err)
-			}
+// simulated returns true if the last call to Send only simulated its txs, because the chains are in
+// dry-run mode
+func (r *RelayMsgs) simulated() bool {
+	for _, res := range r.Results {
+		if res.Outcome == TxSimulated {
+			return true
+		}
+	}
+	return false
+}
 
-			msgLen++
-			txSize += uint64(len(bz))
+// sendBatches splits msgs into batches that fit the tx limits and sends them to c. A first batch that
+// carries a client update is followed until committed before the later batches are signed, since their
+// simulation runs on committed state and needs the consensus state their proofs are verified against.
+// The later batches are then broadcast one after another without waiting for a tx to be committed before
+// broadcasting the next, and followed once every batch is broadcast.
+//
+// In dry-run mode the batches are simulated together as one tx instead, since a later batch simulated on
+// its own would fail without the client update of the first committed.
+func (r *RelayMsgs) sendBatches(path string, c *Chain, msgs []provider.RelayerMessage) []*TxResult {
+	batches := r.batches(msgs)
+	if len(batches) > 1 && c.simulating() {
+		c.Log(fmt.Sprintf("- [%s] dry run: simulating the %d batches of msgs as one tx, the later batches "+
+			"rely on the client update of the first", c.ChainID(), len(batches)))
+		var all []provider.RelayerMessage
+		for _, batch := range batches {
+			all = append(all, batch...)
+		}
+		batches = [][]provider.RelayerMessage{all}
+	}
 
-			if r.IsMaxTx(msgLen, txSize) {
-				// Submit the transactions to src chain and update its status
-				res, success, err := src.ChainProvider.SendMessages(msgs)
-				if err != nil {
-					src.LogFailedTx(res, err, msgs)
-				}
-				r.Succeeded = r.Succeeded && success
+	if len(batches) == 0 {
+		return nil
+	}
 
-				// clear the current batch and reset variables
-				msgLen, txSize = 1, uint64(len(bz))
-				msgs = []provider.RelayerMessage{}
-			}
-			msgs = append(msgs, msg)
+	// the batches of a round share a key, so the mempool keeps them behind the client update
+	var (
+		key     = c.ChainProvider.NextKey()
+		results = make([]*TxResult, 0, len(batches))
+		update  *TxResult
+	)
+	for i, batch := range batches {
+		var res *TxResult
+		switch {
+		// the batches after one that is over the fee budget are held back too
+		case i > 0 && results[i-1].Outcome == TxOverBudget:
+			res = &TxResult{ChainID: c.ChainID(), Msgs: batch, Outcome: TxOverBudget}
+		case update != nil && update.Outcome != TxCommitted && update.Outcome != TxSimulated:
+			res = &TxResult{ChainID: c.ChainID(), Msgs: batch, Outcome: TxHeldBack}
+		default:
+			res = broadcastTx(path, c, key, batch)
+		}
+		if i == 0 && len(batches) > 1 && hasClientUpdate(batch) {
+			res.follow(path, c)
+			update = res
 		}
+		results = append(results, res)
 	}
-
-	// submit leftover msgs
-	if len(msgs) > 0 {
-		res, success, err := src.ChainProvider.SendMessages(msgs)
-		if err != nil {
-			src.LogFailedTx(res, err, msgs)
+	for _, res := range results {
+		if res != update {
+			res.follow(path, c)
 		}
+		recordTxResult(path, c, res)
+	}
+	return results
+}
 
-		r.Succeeded = success
+// hasClientUpdate returns true if one of msgs updates a client
+func hasClientUpdate(msgs []provider.RelayerMessage) bool {
+	for _, msg := range msgs {
+		if msg.Type() == sdk.MsgTypeURL(&clienttypes.MsgUpdateClient{}) {
+			return true
+		}
 	}
+	return false
+}
 
-	// reset variables
-	msgLen, txSize = 0, 0
-	msgs = []provider.RelayerMessage{}
+// batches splits msgs into batches that fit the tx limits
+func (r *RelayMsgs) batches(msgs []provider.RelayerMessage) [][]provider.RelayerMessage {
+	//nolint:prealloc // can not be pre allocated
+	var (
+		msgLen, txSize uint64
+		batch          []provider.RelayerMessage
+		batches        [][]provider.RelayerMessage
+	)
 
-	for _, msg := range r.Dst {
+	for _, msg := range msgs {
 		if msg != nil {
 			bz, err := msg.MsgBytes()
 			if err != nil {
@@ -177,31 +246,32 @@ func (r *RelayMsgs) SendWithController(src, dst *Chain, useController bool) {
 			txSize += uint64(len(bz))
 
 			if r.IsMaxTx(msgLen, txSize) {
-				// Submit the transaction to dst chain and update its status
-				res, success, err := dst.ChainProvider.SendMessages(msgs)
-				if err != nil {
-					dst.LogFailedTx(res, err, msgs)
-				}
-
-				r.Succeeded = r.Succeeded && success
+				batches = append(batches, batch)
 
 				// clear the current batch and reset variables
 				msgLen, txSize = 1, uint64(len(bz))
-				msgs = []provider.RelayerMessage{}
+				batch = []provider.RelayerMessage{}
 			}
-			msgs = append(msgs, msg)
+			batch = append(batch, msg)
 		}
 	}
 
-	// submit leftover msgs
-	if len(msgs) > 0 {
-		res, success, err := dst.ChainProvider.SendMessages(msgs)
-		if err != nil {
-			dst.LogFailedTx(res, err, msgs)
-		}
+	// leftover msgs
+	if len(batch) > 0 {
+		batches = append(batches, batch)
+	}
+	return batches
+}
 
-		r.Succeeded = success
+// recordTxResult records the outcome of a batch of msgs sent to c in the path's metrics and the
+// notifier's run of failures
+func recordTxResult(path string, c *Chain, res *TxResult) {
+	if res.Outcome == TxSimulated || res.Outcome == TxOverBudget || res.Outcome == TxHeldBack {
+		return
 	}
+	Metrics.recordTx(path, c, res.Response, res.Outcome)
+	fees.record(path, c, res.Response)
+	notifier.recordTxOutcome(path, c, res.Outcome != TxFailed && res.Outcome != TxDropped)
 }
 
 func getMsgTypes(msgs []provider.RelayerMessage) string {
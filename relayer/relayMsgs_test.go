@@ -0,0 +1,112 @@
+package relayer
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/relayer/relayer/provider"
+	"github.com/stretchr/testify/require"
+)
+
+// callsProvider records the txs broadcast to and waited for on the chain of the provider it wraps
+type callsProvider struct {
+	provider.ChainProvider
+
+	mu    sync.Mutex
+	calls []string
+	keys  []string
+}
+
+func (p *callsProvider) BroadcastMessages(msgs []provider.RelayerMessage, key string, gas uint64, feeBump float64) (string, error) {
+	p.record("broadcast")
+	p.mu.Lock()
+	p.keys = append(p.keys, key)
+	p.mu.Unlock()
+	return p.ChainProvider.BroadcastMessages(msgs, key, gas, feeBump)
+}
+
+func (p *callsProvider) WaitForTx(txHash string, timeout time.Duration) (*provider.RelayerTxResponse, bool, error) {
+	p.record("wait")
+	return p.ChainProvider.WaitForTx(txHash, timeout)
+}
+
+func (p *callsProvider) record(call string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.calls = append(p.calls, call)
+}
+
+func TestSendBatchesBroadcastsBeforeWaiting(t *testing.T) {
+	src, dst := newMockLink(t)
+	calls := &callsProvider{ChainProvider: src.ChainProvider}
+	src.ChainProvider = calls
+
+	dstAddr, err := dst.ChainProvider.Address()
+	require.NoError(t, err)
+	msgs := NewRelayMsgs()
+	msgs.MaxMsgLength = 1
+	for i := 0; i < 3; i++ {
+		msg, err := src.ChainProvider.MsgTransfer(sdk.NewInt64Coin(testDenom, 100), dst.ChainID(), dstAddr,
+			src.PathEnd.PortID, src.PathEnd.ChannelID, 0, uint64(time.Now().Add(time.Hour).UnixNano()))
+		require.NoError(t, err)
+		msgs.Src = append(msgs.Src, msg)
+	}
+
+	msgs.Send(src, dst)
+	require.True(t, msgs.Success())
+	require.Len(t, msgs.Results, 3)
+	require.Equal(t, []string{"broadcast", "broadcast", "broadcast", "wait", "wait", "wait"}, calls.calls)
+	requireBalance(t, src, testDenom, 9700)
+}
+
+func TestSendBatchesCommitsClientUpdateFirst(t *testing.T) {
+	src, dst := newMockLink(t)
+	calls := &callsProvider{ChainProvider: dst.ChainProvider}
+	dst.ChainProvider = calls
+
+	dstAddr, err := dst.ChainProvider.Address()
+	require.NoError(t, err)
+	for i := 0; i < 5; i++ {
+		require.NoError(t, src.SendTransferMsg(dst, sdk.NewInt64Coin(testDenom, 100), dstAddr, 0, 0))
+	}
+	// the packets are queried with proofs, which need the block of the last transfer to be committed
+	require.NoError(t, src.ChainProvider.WaitForNBlocks(1))
+	sp, err := UnrelayedSequences(src, dst)
+	require.NoError(t, err)
+	require.Len(t, sp.Src, 5)
+
+	// the client update and the first recv packet make up the first batch, the recv packets of the later
+	// batches are only signed once the consensus state their proofs need is committed
+	calls.mu.Lock()
+	calls.calls = nil
+	calls.mu.Unlock()
+	require.NoError(t, RelayPackets(src, dst, sp, testMaxTxSize, 2))
+	require.Equal(t, []string{"broadcast", "wait", "broadcast", "broadcast", "wait", "wait"}, calls.calls)
+	requireBalance(t, dst, voucherDenom(dst, testDenom), 500)
+}
+
+func TestSendBatchesShareKey(t *testing.T) {
+	src, dst := newMockLink(t)
+	pool, _, err := dst.CreateKeyPool(2)
+	require.NoError(t, err)
+	calls := &callsProvider{ChainProvider: dst.ChainProvider}
+	dst.ChainProvider = calls
+
+	dstAddr, err := dst.ChainProvider.Address()
+	require.NoError(t, err)
+	for round := 0; round < 2; round++ {
+		for i := 0; i < 3; i++ {
+			require.NoError(t, src.SendTransferMsg(dst, sdk.NewInt64Coin(testDenom, 100), dstAddr, 0, 0))
+		}
+		require.NoError(t, src.ChainProvider.WaitForNBlocks(1))
+		sp, err := UnrelayedSequences(src, dst)
+		require.NoError(t, err)
+		require.NoError(t, RelayPackets(src, dst, sp, testMaxTxSize, 2))
+	}
+
+	// the two batches of a round are signed by one key of the pool, the next round takes the next key
+	require.Equal(t, []string{pool[0], pool[0], pool[1], pool[1]}, calls.keys)
+	requireBalance(t, dst, voucherDenom(dst, testDenom), 600)
+}
@@ -0,0 +1,407 @@
+package relayer
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sync"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	chantypes "github.com/cosmos/ibc-go/v2/modules/core/04-channel/types"
+	"github.com/cosmos/relayer/relayer/provider"
+	dbm "github.com/tendermint/tm-db"
+)
+
+const (
+	stateStoreName = "relayer"
+
+	// handshakeStoreName is the store of the handshake progress of paths. It is kept apart from the state
+	// store, which rly start holds locked while it runs, so paths can be linked in the meantime.
+	handshakeStoreName = "handshakes"
+
+	// inFlightTxExpiry is how long a broadcast tx that can not be found on chain is kept in the store,
+	// after which it is assumed to have been dropped from the mempool
+	inFlightTxExpiry = 10 * time.Minute
+
+	// inFlightReconcileInterval is how often the txs still recorded as in flight after a reconciliation
+	// are looked up again while relaying
+	inFlightReconcileInterval = time.Minute
+
+	txPrefix        = "tx/"
+	heightPrefix    = "height/"
+	handshakePrefix = "handshake/"
+)
+
+// Stages of the handshake of a path that are recorded in the state store
+const (
+	HandshakeClients    = "clients"
+	HandshakeConnection = "connection"
+	HandshakeChannel    = "channel"
+	HandshakeComplete   = "complete"
+)
+
+// state is the store used by the relay loops, it stays nil unless SetStateStore is called
+var state *StateStore
+
+// SetStateStore sets the store in which the relayer records its progress
+func SetStateStore(s *StateStore) {
+	state = s
+}
+
+// InFlightTx is a relay transaction that was broadcast but not yet seen committed
+type InFlightTx struct {
+	ChainID   string           `json:"chain-id"`
+	Path      string           `json:"path"`
+	Hash      string           `json:"hash"`
+	Msgs      []string         `json:"msgs"`
+	Packets   []InFlightPacket `json:"packets,omitempty"`
+	Broadcast time.Time        `json:"broadcast"`
+}
+
+// Kinds of packet msgs recorded for in-flight txs
+const (
+	packetRecv    = "recv"
+	packetTimeout = "timeout"
+	packetAck     = "ack"
+)
+
+// InFlightPacket identifies a packet relayed by an in-flight tx by the channel it was sent on
+type InFlightPacket struct {
+	Kind          string `json:"kind"`
+	SourcePort    string `json:"source-port"`
+	SourceChannel string `json:"source-channel"`
+	Sequence      uint64 `json:"sequence"`
+}
+
+// StateStore is an embedded database recording the txs the relayer has in flight, the heights
+// each path was last relayed at and the progress of path handshakes, so a restarted relayer
+// does not relay the same packets twice
+type StateStore struct {
+	mu sync.Mutex
+	db dbm.DB
+
+	// reconcileAt holds the chains with txs left in flight by their last reconciliation, along with when
+	// they are to be reconciled again
+	reconcileAt map[string]time.Time
+}
+
+// OpenStateStore opens or creates the state store in the data directory of home
+func OpenStateStore(home string) (*StateStore, error) {
+	return openStore(home, stateStoreName)
+}
+
+// OpenHandshakeStore opens or creates the store that tx link records the progress of handshakes in
+func OpenHandshakeStore(home string) (*StateStore, error) {
+	return openStore(home, handshakeStoreName)
+}
+
+func openStore(home, name string) (*StateStore, error) {
+	db, err := dbm.NewDB(name, dbm.GoLevelDBBackend, path.Join(home, "data"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s store: %w", name, err)
+	}
+	return &StateStore{db: db}, nil
+}
+
+// Close closes the underlying database
+func (s *StateStore) Close() error {
+	return s.db.Close()
+}
+
+func txKey(chainID, hash string) []byte {
+	return []byte(fmt.Sprintf("%s%s/%s", txPrefix, chainID, hash))
+}
+
+// AddInFlightTx records a tx broadcast to a chain
+func (s *StateStore) AddInFlightTx(tx *InFlightTx) error {
+	if s == nil {
+		return nil
+	}
+	bz, err := json.Marshal(tx)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.db.SetSync(txKey(tx.ChainID, tx.Hash), bz)
+}
+
+// RemoveInFlightTx drops a tx from the store once its outcome is known
+func (s *StateStore) RemoveInFlightTx(chainID, hash string) error {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.db.DeleteSync(txKey(chainID, hash))
+}
+
+// InFlightTxs returns the txs recorded for a chain
+func (s *StateStore) InFlightTxs(chainID string) ([]*InFlightTx, error) {
+	if s == nil {
+		return nil, nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	it, err := dbm.IteratePrefix(s.db, []byte(fmt.Sprintf("%s%s/", txPrefix, chainID)))
+	if err != nil {
+		return nil, err
+	}
+	defer it.Close()
+
+	var txs []*InFlightTx
+	for ; it.Valid(); it.Next() {
+		tx := &InFlightTx{}
+		if err = json.Unmarshal(it.Value(), tx); err != nil {
+			return nil, err
+		}
+		txs = append(txs, tx)
+	}
+	return txs, it.Error()
+}
+
+// Reconcile looks up every tx recorded as in flight on c. Txs found on chain and txs that have not been
+// found for longer than a mempool would keep them are dropped, the rest are kept so the packets they
+// relay are not relayed again, and are looked up again by the relay rounds after inFlightReconcileInterval.
+// Reconcile is called when the relayer starts and when a tx is not committed in time.
+func (s *StateStore) Reconcile(c *Chain) error {
+	if s == nil {
+		return nil
+	}
+	txs, err := s.InFlightTxs(c.ChainID())
+	if err != nil {
+		return err
+	}
+
+	remaining := 0
+	defer func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if remaining == 0 {
+			delete(s.reconcileAt, c.ChainID())
+			return
+		}
+		if s.reconcileAt == nil {
+			s.reconcileAt = make(map[string]time.Time)
+		}
+		s.reconcileAt[c.ChainID()] = time.Now().Add(inFlightReconcileInterval)
+	}()
+
+	for _, tx := range txs {
+		res, err := c.ChainProvider.QueryTx(tx.Hash)
+		switch {
+		case err == nil && res != nil:
+			if res.TxResult.Code == 0 {
+				c.Log(fmt.Sprintf("✔ [%s]@{%d} in-flight tx(%s) on path %s was committed", c.ChainID(), res.Height, tx.Hash, tx.Path))
+			} else {
+				c.Log(fmt.Sprintf("✘ [%s]@{%d} in-flight tx(%s) on path %s failed with code %d", c.ChainID(), res.Height, tx.Hash, tx.Path, res.TxResult.Code))
+			}
+		case time.Since(tx.Broadcast) > inFlightTxExpiry:
+			c.Log(fmt.Sprintf("✘ [%s] in-flight tx(%s) on path %s was not committed, dropping it", c.ChainID(), tx.Hash, tx.Path))
+		default:
+			remaining++
+			continue
+		}
+		if err = s.RemoveInFlightTx(c.ChainID(), tx.Hash); err != nil {
+			remaining = len(txs)
+			return err
+		}
+	}
+	return nil
+}
+
+// reconcileDue reconciles the in-flight txs of c if they are due to be looked up again
+func (s *StateStore) reconcileDue(c *Chain) error {
+	s.mu.Lock()
+	at, ok := s.reconcileAt[c.ChainID()]
+	s.mu.Unlock()
+	if !ok || time.Now().Before(at) {
+		return nil
+	}
+	return s.Reconcile(c)
+}
+
+// inFlightSequences returns the sequences of the packets sent from c that in-flight txs relay, either
+// the packets themselves when acks is false or their acknowledgements otherwise. Packets are received
+// on dst and timed out on c, while acknowledgements are delivered back to c.
+func (s *StateStore) inFlightSequences(c, dst *Chain, acks bool) (map[uint64]bool, error) {
+	seqs := make(map[uint64]bool)
+	if s == nil {
+		return seqs, nil
+	}
+
+	kinds := map[string]string{dst.ChainID(): packetRecv, c.ChainID(): packetTimeout}
+	if acks {
+		kinds = map[string]string{c.ChainID(): packetAck}
+	}
+	for chainID, kind := range kinds {
+		txs, err := s.InFlightTxs(chainID)
+		if err != nil {
+			return nil, err
+		}
+		for _, tx := range txs {
+			for _, p := range tx.Packets {
+				if p.Kind == kind && p.SourcePort == c.PathEnd.PortID && p.SourceChannel == c.PathEnd.ChannelID {
+					seqs[p.Sequence] = true
+				}
+			}
+		}
+	}
+	return seqs, nil
+}
+
+// dropInFlight removes the sequences that the in-flight txs of both chains already relay, once those
+// due to be looked up again were reconciled. The packets sent by src are in rs.Src when relaying packets,
+// while rs.Src holds acknowledgements for packets sent by dst otherwise.
+func (s *StateStore) dropInFlight(src, dst *Chain, rs *RelaySequences, acks bool) (*RelaySequences, error) {
+	if s == nil {
+		return rs, nil
+	}
+	if err := s.reconcileDue(src); err != nil {
+		return nil, err
+	}
+	if err := s.reconcileDue(dst); err != nil {
+		return nil, err
+	}
+
+	srcSent, dstSent := src, dst
+	if acks {
+		srcSent, dstSent = dst, src
+	}
+	srcInFlight, err := s.inFlightSequences(srcSent, dstSent, acks)
+	if err != nil {
+		return nil, err
+	}
+	dstInFlight, err := s.inFlightSequences(dstSent, srcSent, acks)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &RelaySequences{Src: []uint64{}, Dst: []uint64{}}
+	for _, seq := range rs.Src {
+		if !srcInFlight[seq] {
+			out.Src = append(out.Src, seq)
+		}
+	}
+	for _, seq := range rs.Dst {
+		if !dstInFlight[seq] {
+			out.Dst = append(out.Dst, seq)
+		}
+	}
+	return out, nil
+}
+
+func heightKey(path, chainID string) []byte {
+	return []byte(fmt.Sprintf("%s%s/%s", heightPrefix, path, chainID))
+}
+
+// SetPathHeight records the height of a chain at which a path was last relayed
+func (s *StateStore) SetPathHeight(path, chainID string, height int64) error {
+	if s == nil {
+		return nil
+	}
+	bz := make([]byte, 8)
+	binary.BigEndian.PutUint64(bz, uint64(height))
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.db.Set(heightKey(path, chainID), bz)
+}
+
+// PathHeight returns the height of a chain at which a path was last relayed, or zero if it never was
+func (s *StateStore) PathHeight(path, chainID string) (int64, error) {
+	if s == nil {
+		return 0, nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	bz, err := s.db.Get(heightKey(path, chainID))
+	if err != nil || len(bz) != 8 {
+		return 0, err
+	}
+	return int64(binary.BigEndian.Uint64(bz)), nil
+}
+
+// setPathHeights records the heights of src and dst at which the path between them was relayed
+func (s *StateStore) setPathHeights(src, dst *Chain, srch, dsth int64) {
+	path := pathLabel(src, dst)
+	if err := s.SetPathHeight(path, src.ChainID(), srch); err != nil {
+		src.Error(fmt.Errorf("failed to record height of path %s: %w", path, err))
+	}
+	if err := s.SetPathHeight(path, dst.ChainID(), dsth); err != nil {
+		dst.Error(fmt.Errorf("failed to record height of path %s: %w", path, err))
+	}
+}
+
+// SetHandshake records the stage that the handshake of a path has reached
+func (s *StateStore) SetHandshake(path, stage string) error {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.db.SetSync([]byte(handshakePrefix+path), []byte(stage))
+}
+
+// Handshake returns the stage that the handshake of a path has reached, or an empty string if none was recorded
+func (s *StateStore) Handshake(path string) (string, error) {
+	if s == nil {
+		return "", nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	bz, err := s.db.Get([]byte(handshakePrefix + path))
+	return string(bz), err
+}
+
+// newInFlightTx returns the record of a tx broadcast to c, along with the packets its msgs relay
+func newInFlightTx(path string, c *Chain, hash string, msgs []provider.RelayerMessage) *InFlightTx {
+	tx := &InFlightTx{ChainID: c.ChainID(), Path: path, Hash: hash, Broadcast: time.Now()}
+	for _, msg := range msgs {
+		tx.Msgs = append(tx.Msgs, msg.Type())
+		if p, ok := inFlightPacket(msg); ok {
+			tx.Packets = append(tx.Packets, p)
+		}
+	}
+	return tx
+}
+
+// inFlightPacket returns the packet relayed by a MsgRecvPacket, MsgTimeout or MsgAcknowledgement
+func inFlightPacket(msg provider.RelayerMessage) (InFlightPacket, bool) {
+	bz, err := msg.MsgBytes()
+	if err != nil {
+		return InFlightPacket{}, false
+	}
+
+	var (
+		packet chantypes.Packet
+		kind   string
+	)
+	switch msg.Type() {
+	case sdk.MsgTypeURL(&chantypes.MsgRecvPacket{}):
+		var m chantypes.MsgRecvPacket
+		err = m.Unmarshal(bz)
+		packet, kind = m.Packet, packetRecv
+	case sdk.MsgTypeURL(&chantypes.MsgTimeout{}):
+		var m chantypes.MsgTimeout
+		err = m.Unmarshal(bz)
+		packet, kind = m.Packet, packetTimeout
+	case sdk.MsgTypeURL(&chantypes.MsgAcknowledgement{}):
+		var m chantypes.MsgAcknowledgement
+		err = m.Unmarshal(bz)
+		packet, kind = m.Packet, packetAck
+	default:
+		return InFlightPacket{}, false
+	}
+	if err != nil {
+		return InFlightPacket{}, false
+	}
+	return InFlightPacket{
+		Kind:          kind,
+		SourcePort:    packet.SourcePort,
+		SourceChannel: packet.SourceChannel,
+		Sequence:      packet.Sequence,
+	}, true
+}
@@ -0,0 +1,48 @@
+package relayer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandshakeStoreWhileRelaying(t *testing.T) {
+	home := t.TempDir()
+	state, err := OpenStateStore(home)
+	require.NoError(t, err)
+	defer state.Close()
+
+	// tx link records its progress while rly start holds the state store
+	handshakes, err := OpenHandshakeStore(home)
+	require.NoError(t, err)
+	require.NoError(t, handshakes.SetHandshake("demo", HandshakeConnection))
+	require.NoError(t, handshakes.Close())
+
+	handshakes, err = OpenHandshakeStore(home)
+	require.NoError(t, err)
+	defer handshakes.Close()
+	stage, err := handshakes.Handshake("demo")
+	require.NoError(t, err)
+	require.Equal(t, HandshakeConnection, stage)
+}
+
+func TestReconcileSchedulesRemainingTxs(t *testing.T) {
+	c := newMockChain(t, "ibc-0")
+	state, err := OpenStateStore(t.TempDir())
+	require.NoError(t, err)
+	defer state.Close()
+
+	require.NoError(t, state.AddInFlightTx(&InFlightTx{ChainID: c.ChainID(), Hash: "ABCD", Broadcast: time.Now()}))
+	require.NoError(t, state.Reconcile(c))
+	require.Contains(t, state.reconcileAt, c.ChainID())
+
+	// the tx is not looked up again before it is due
+	require.NoError(t, state.RemoveInFlightTx(c.ChainID(), "ABCD"))
+	require.NoError(t, state.reconcileDue(c))
+	require.Contains(t, state.reconcileAt, c.ChainID())
+
+	state.reconcileAt[c.ChainID()] = time.Now()
+	require.NoError(t, state.reconcileDue(c))
+	require.NotContains(t, state.reconcileAt, c.ChainID())
+}
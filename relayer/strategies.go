@@ -2,52 +2,61 @@ package relayer
 
 import (
 	"fmt"
+	"sync"
 	"time"
 )
 
-// StartRelayer starts the main relaying loop
+// pausedPaths holds the names of the paths whose relay loops have been paused
+var pausedPaths sync.Map
+
+// PausePath stops the relay loops of the named path from relaying until ResumePath is called.
+// Client updates keep running while a path is paused.
+func PausePath(name string) {
+	pausedPaths.Store(name, struct{}{})
+	notifyPathStatus(name, "was paused")
+}
+
+// ResumePath resumes relaying on a path paused with PausePath
+func ResumePath(name string) {
+	pausedPaths.Delete(name)
+	notifyPathStatus(name, "was resumed")
+}
+
+// PathPaused returns true if the named path is paused
+func PathPaused(name string) bool {
+	_, ok := pausedPaths.Load(name)
+	return ok
+}
+
+// canRelay returns false if relaying on the path between src and dst is paused, either through
+// PausePath or because the path or one of its chains has spent its fee budget
+func canRelay(src, dst *Chain) bool {
+	return !PathPaused(src.PathName()) && fees.withinBudget(src, dst)
+}
+
+// StartRelayer starts the main relaying loop on the channel that src and dst are set to
 func StartRelayer(src, dst *Chain, maxTxSize, maxMsgLength uint64) (func(), error) {
+	return StartChannelSetRelayer(channelSetOf(src, dst), maxTxSize, maxMsgLength)
+}
+
+// StartChannelSetRelayer starts the main relaying loop on every channel of cs. The packets and
+// acknowledgements of all channels are relayed in one round behind a single client update on each
+// chain, and the channels are looked up again every channelRefreshInterval.
+func StartChannelSetRelayer(cs *ChannelSet, maxTxSize, maxMsgLength uint64) (func(), error) {
 	doneChan := make(chan struct{})
 	go func() {
+		refreshed := time.Now()
 		for {
 			select {
 			case <-doneChan:
 				return
 			default:
-				// Fetch any unrelayed sequences depending on the channel order
-				sp, err := UnrelayedSequences(src, dst)
-				if err != nil {
-					src.Log(fmt.Sprintf("unrelayed sequences error: %s", err))
-				} else {
-					if len(sp.Src) > 0 && src.debug {
-						src.Log(fmt.Sprintf("[%s] unrelayed-packets-> %v", src.ChainID(), sp.Src))
-					}
-					if len(sp.Dst) > 0 && dst.debug {
-						dst.Log(fmt.Sprintf("[%s] unrelayed-packets-> %v", dst.ChainID(), sp.Dst))
-					}
-					if !sp.Empty() {
-						if err = RelayPackets(src, dst, sp, maxTxSize, maxMsgLength); err != nil {
-							src.Log(fmt.Sprintf("relay packets error: %s", err))
-						}
-					}
+				if time.Since(refreshed) > channelRefreshInterval {
+					cs.refresh()
+					refreshed = time.Now()
 				}
-
-				// Fetch any unrelayed acks depending on the channel order
-				ap, err := UnrelayedAcknowledgements(src, dst)
-				if err != nil {
-					src.Log(fmt.Sprintf("unrelayed acks error: %s", err))
-				} else {
-					if len(ap.Src) > 0 && src.debug {
-						src.Log(fmt.Sprintf("[%s] unrelayed-acks-> %v", src.ChainID(), ap.Src))
-					}
-					if len(ap.Dst) > 0 && dst.debug {
-						dst.Log(fmt.Sprintf("[%s] unrelayed-acks-> %v", dst.ChainID(), ap.Dst))
-					}
-					if !ap.Empty() {
-						if err = RelayAcknowledgements(src, dst, ap, maxTxSize, maxMsgLength); err != nil && src.debug {
-							src.Log(fmt.Sprintf("relay acks error: %s", err))
-						}
-					}
+				if canRelay(cs.src, cs.dst) {
+					relayUnrelayedChannels(cs, maxTxSize, maxMsgLength)
 				}
 
 				time.Sleep(100 * time.Millisecond)
@@ -56,3 +65,11 @@ func StartRelayer(src, dst *Chain, maxTxSize, maxMsgLength uint64) (func(), erro
 	}()
 	return func() { doneChan <- struct{}{} }, nil
 }
+
+// relayUnrelayedChannels scans every channel of cs for unrelayed packets and acknowledgements and
+// relays them
+func relayUnrelayedChannels(cs *ChannelSet, maxTxSize, maxMsgLength uint64) {
+	if err := cs.RelayUnrelayed(true, true, maxTxSize, maxMsgLength); err != nil {
+		cs.src.Log(fmt.Sprintf("relay error: %s", err))
+	}
+}
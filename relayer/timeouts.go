@@ -0,0 +1,161 @@
+package relayer
+
+import (
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	transfertypes "github.com/cosmos/ibc-go/v2/modules/apps/transfer/types"
+	clienttypes "github.com/cosmos/ibc-go/v2/modules/core/02-client/types"
+	chantypes "github.com/cosmos/ibc-go/v2/modules/core/04-channel/types"
+	"github.com/cosmos/relayer/relayer/provider"
+	"golang.org/x/sync/errgroup"
+)
+
+// ExpiredPacket is an unrelayed packet that is past its timeout height or timestamp on the receiving
+// chain, so it can only be timed out on the chain that sent it
+type ExpiredPacket struct {
+	ChainID          string                                 `json:"chain-id"`
+	ChannelID        string                                 `json:"channel-id"`
+	PortID           string                                 `json:"port-id"`
+	Sequence         uint64                                 `json:"sequence"`
+	TimeoutHeight    clienttypes.Height                     `json:"timeout-height"`
+	TimeoutTimestamp uint64                                 `json:"timeout-timestamp"`
+	Transfer         *transfertypes.FungibleTokenPacketData `json:"transfer,omitempty"`
+}
+
+// ExpiredPackets holds the expired packets sent by src and dst
+type ExpiredPackets struct {
+	Src []*ExpiredPacket `json:"src"`
+	Dst []*ExpiredPacket `json:"dst"`
+}
+
+// Sequences returns the sequences of the expired packets
+func (ep *ExpiredPackets) Sequences() *RelaySequences {
+	rs := &RelaySequences{Src: []uint64{}, Dst: []uint64{}}
+	for _, p := range ep.Src {
+		rs.Src = append(rs.Src, p.Sequence)
+	}
+	for _, p := range ep.Dst {
+		rs.Dst = append(rs.Dst, p.Sequence)
+	}
+	return rs
+}
+
+// QueryExpiredPackets returns the unrelayed packets between src and dst whose timeout height or
+// timestamp has been reached by the receiving chain
+func QueryExpiredPackets(src, dst *Chain) (*ExpiredPackets, error) {
+	sp, err := UnrelayedSequences(src, dst)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		eg  = new(errgroup.Group)
+		out = &ExpiredPackets{Src: []*ExpiredPacket{}, Dst: []*ExpiredPacket{}}
+	)
+	eg.Go(func() error {
+		var err error
+		out.Src, err = expiredPackets(src, dst, sp.Src)
+		return err
+	})
+	eg.Go(func() error {
+		var err error
+		out.Dst, err = expiredPackets(dst, src, sp.Dst)
+		return err
+	})
+	if err = eg.Wait(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// expiredPackets returns the packets sent from src with the given sequences that have timed out on dst
+func expiredPackets(src, dst *Chain, seqs []uint64) ([]*ExpiredPacket, error) {
+	out := []*ExpiredPacket{}
+	if len(seqs) == 0 {
+		return out, nil
+	}
+
+	h, err := dst.ChainProvider.QueryLatestHeight()
+	if err != nil {
+		return nil, err
+	}
+	cs, _, err := dst.ChainProvider.QueryConsensusState(h)
+	if err != nil {
+		return nil, err
+	}
+	dstHeight := clienttypes.NewHeight(clienttypes.ParseChainID(dst.ChainID()), uint64(h))
+
+	for _, seq := range seqs {
+		packet, err := src.ChainProvider.QuerySendPacket(src.PathEnd.ChannelID, src.PathEnd.PortID, seq)
+		if err != nil {
+			return nil, err
+		}
+
+		heightReached := !packet.Timeout().IsZero() && dstHeight.GTE(packet.Timeout())
+		timeReached := packet.TimeoutStamp() != 0 && cs.GetTimestamp() >= packet.TimeoutStamp()
+		if !heightReached && !timeReached {
+			continue
+		}
+
+		ep := &ExpiredPacket{
+			ChainID:          src.ChainID(),
+			ChannelID:        src.PathEnd.ChannelID,
+			PortID:           src.PathEnd.PortID,
+			Sequence:         seq,
+			TimeoutHeight:    packet.Timeout(),
+			TimeoutTimestamp: packet.TimeoutStamp(),
+		}
+		var ftpd transfertypes.FungibleTokenPacketData
+		if err := transfertypes.ModuleCdc.UnmarshalJSON(packet.Data(), &ftpd); err == nil {
+			ep.Transfer = &ftpd
+		}
+		out = append(out, ep)
+	}
+	return out, nil
+}
+
+// RelayTimeouts submits MsgTimeout to the sending chain for every expired packet between src and dst
+func RelayTimeouts(src, dst *Chain, maxTxSize, maxMsgLength uint64) error {
+	ep, err := QueryExpiredPackets(src, dst)
+	if err != nil {
+		return err
+	}
+	sp := ep.Sequences()
+	if sp.Empty() {
+		src.Log(fmt.Sprintf("- No expired packets between [%s]port{%s} and [%s]port{%s}",
+			src.ChainID(), src.PathEnd.PortID, dst.ChainID(), dst.PathEnd.PortID))
+		return nil
+	}
+	return RelayPackets(src, dst, sp, maxTxSize, maxMsgLength)
+}
+
+// logTimeouts logs every MsgTimeout among msgs that was committed on c, along with the refund
+// of the transfer it timed out
+func logTimeouts(path string, c *Chain, msgs []provider.RelayerMessage) {
+	timeoutType := sdk.MsgTypeURL(&chantypes.MsgTimeout{})
+	for _, msg := range msgs {
+		if msg.Type() != timeoutType {
+			continue
+		}
+		bz, err := msg.MsgBytes()
+		if err != nil {
+			continue
+		}
+		var mt chantypes.MsgTimeout
+		if err = mt.Unmarshal(bz); err != nil {
+			continue
+		}
+		Metrics.PacketsTimedOut.WithLabelValues(path, c.ChainID()).Inc()
+
+		var ftpd transfertypes.FungibleTokenPacketData
+		if err = transfertypes.ModuleCdc.UnmarshalJSON(mt.Packet.Data, &ftpd); err != nil {
+			c.Log(fmt.Sprintf("★ Timed out packet seq(%d) on [%s]chan{%s}port{%s}",
+				mt.Packet.Sequence, c.ChainID(), mt.Packet.SourceChannel, mt.Packet.SourcePort))
+			continue
+		}
+		c.Log(fmt.Sprintf("★ Timed out packet seq(%d) on [%s]chan{%s}port{%s}, refunded %s%s to %s",
+			mt.Packet.Sequence, c.ChainID(), mt.Packet.SourceChannel, mt.Packet.SourcePort,
+			ftpd.Amount, ftpd.Denom, ftpd.Sender))
+	}
+}
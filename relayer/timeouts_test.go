@@ -0,0 +1,54 @@
+package relayer
+
+import (
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRelayTimeoutsByTimestamp(t *testing.T) {
+	src, dst := newMockLink(t)
+
+	dstAddr, err := dst.ChainProvider.Address()
+	require.NoError(t, err)
+	// the time of a mock chain runs ahead of the clock with every tx, so the timeout follows that of dst
+	dsth, err := dst.ChainProvider.QueryLatestHeight()
+	require.NoError(t, err)
+	cs, _, err := dst.ChainProvider.QueryConsensusState(dsth)
+	require.NoError(t, err)
+	msg, err := src.ChainProvider.MsgTransfer(sdk.NewInt64Coin(testDenom, 1000), dst.ChainID(), dstAddr,
+		src.PathEnd.PortID, src.PathEnd.ChannelID, 0, cs.GetTimestamp()+uint64(10*testBlockTime))
+	require.NoError(t, err)
+	_, success, err := src.ChainProvider.SendMessage(msg)
+	require.NoError(t, err)
+	require.True(t, success)
+	require.NoError(t, src.ChainProvider.WaitForNBlocks(1))
+	requireBalance(t, src, testDenom, 9000)
+
+	// the packet is only expired once the time of dst passes its timeout timestamp
+	ep, err := QueryExpiredPackets(src, dst)
+	require.NoError(t, err)
+	require.Empty(t, ep.Src)
+
+	require.Eventually(t, func() bool {
+		ep, err = QueryExpiredPackets(src, dst)
+		return err == nil && len(ep.Src) == 1
+	}, 10*time.Second, testBlockTime)
+	require.Empty(t, ep.Dst)
+	expired := ep.Src[0]
+	require.Equal(t, uint64(1), expired.Sequence)
+	require.True(t, expired.TimeoutHeight.IsZero())
+	require.NotZero(t, expired.TimeoutTimestamp)
+	require.NotNil(t, expired.Transfer)
+	require.Equal(t, "1000", expired.Transfer.Amount)
+
+	// the timeout refunds the tokens on src and nothing is received on dst
+	require.NoError(t, RelayTimeouts(src, dst, testMaxTxSize, testMaxMsgLength))
+	requireBalance(t, src, testDenom, 10000)
+	requireBalance(t, dst, voucherDenom(dst, testDenom), 0)
+	ep, err = QueryExpiredPackets(src, dst)
+	require.NoError(t, err)
+	require.Empty(t, ep.Src)
+}
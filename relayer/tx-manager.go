@@ -0,0 +1,209 @@
+package relayer
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cosmos/relayer/relayer/provider"
+)
+
+const (
+	// txCommitTimeout is how long a relay tx may take to be committed after it was broadcast
+	txCommitTimeout = time.Minute
+
+	// txMaxResubmits is how often a relay tx is resubmitted after it was dropped or could not be broadcast
+	txMaxResubmits = 3
+
+	// txFeeBump is the factor by which the gas prices of a tx are raised on every resubmission
+	txFeeBump = 1.2
+)
+
+// TxOutcome is the final state of a relay tx
+type TxOutcome string
+
+const (
+	// TxCommitted means the tx was committed and its msgs executed successfully
+	TxCommitted TxOutcome = "committed"
+	// TxFailed means the tx was committed but failed, or could not be broadcast at all
+	TxFailed TxOutcome = "failed"
+	// TxPending means the tx was still in the mempool when the relayer stopped waiting for it. It stays
+	// recorded as in flight, so its packets are not relayed again until it is committed or dropped.
+	TxPending TxOutcome = "pending"
+	// TxDropped means the tx left the mempool without being committed and every resubmission was dropped too
+	TxDropped TxOutcome = "dropped"
+	// TxSimulated means the chain is in dry-run mode, so the tx was only simulated and printed
+	TxSimulated TxOutcome = "simulated"
+	// TxOverBudget means the tx was not broadcast because its estimated fee would exceed a fee budget
+	TxOverBudget TxOutcome = "over-budget"
+	// TxHeldBack means the tx was not broadcast because the client update its msgs rely on was not committed
+	TxHeldBack TxOutcome = "held-back"
+)
+
+// TxResult reports what became of a batch of relay msgs sent to a chain
+type TxResult struct {
+	ChainID  string
+	Hash     string
+	Outcome  TxOutcome
+	Attempts int
+	Response *provider.RelayerTxResponse
+	Msgs     []provider.RelayerMessage
+
+	// key is the key the tx is signed with, shared by the txs of a relay round
+	key string
+	// gas is the gas limit estimated for the fee budget, zero to have the tx simulated when it is signed
+	gas uint64
+	// feeBump is the factor the gas prices of the next resubmission are raised by
+	feeBump float64
+}
+
+// broadcastTx broadcasts msgs to c signed with key without waiting for their tx to be committed, so the
+// txs of several batches can wait in the mempool at once. The outcome of a broadcast tx is left empty
+// until follow is called on its result.
+func broadcastTx(path string, c *Chain, key string, msgs []provider.RelayerMessage) *TxResult {
+	result := &TxResult{ChainID: c.ChainID(), Msgs: msgs, key: key, feeBump: 1}
+	gas, ok := fees.allows(path, c, key, msgs)
+	if !ok {
+		result.Outcome = TxOverBudget
+		return result
+	}
+	result.gas = gas
+	result.broadcast(path, c)
+	return result
+}
+
+// broadcast broadcasts the msgs of r, resubmitting them with bumped gas prices while they can not be
+// broadcast, up to txMaxResubmits times
+func (r *TxResult) broadcast(path string, c *Chain) {
+	for {
+		r.Attempts++
+		hash, err := c.ChainProvider.BroadcastMessages(r.Msgs, r.key, r.gas, r.feeBump)
+		if isDryRun(err) {
+			r.Outcome = TxSimulated
+			return
+		}
+		if err != nil {
+			c.LogFailedTx(nil, err, r.Msgs)
+			if r.resubmit(path, c, fmt.Sprintf("broadcast failed: %s", err)) {
+				continue
+			}
+			r.Outcome = TxFailed
+			return
+		}
+		r.Hash = hash
+		if err = state.AddInFlightTx(newInFlightTx(path, c, hash, r.Msgs)); err != nil {
+			c.Error(fmt.Errorf("failed to record in-flight tx %s: %w", hash, err))
+		}
+		return
+	}
+}
+
+// follow waits for the broadcast tx of r to be committed. A tx that times out is looked up in the
+// mempool: if it is still waiting there it is reported as pending, otherwise it was evicted and is
+// resubmitted with a fresh account sequence and bumped gas prices, up to txMaxResubmits times.
+func (r *TxResult) follow(path string, c *Chain) {
+	for r.Outcome == "" {
+		res, success, err := c.ChainProvider.WaitForTx(r.Hash, txCommitTimeout)
+		if errors.Is(err, provider.ErrTxNotCommitted) {
+			inMempool, mempoolErr := c.ChainProvider.TxInMempool(r.Hash)
+			if mempoolErr != nil || inMempool {
+				r.Outcome = TxPending
+				// have the pending tx looked up along with the other txs in flight on c
+				if err = state.Reconcile(c); err != nil {
+					c.Error(fmt.Errorf("failed to reconcile in-flight txs: %w", err))
+				}
+				return
+			}
+
+			c.removeInFlightTx(r.Hash)
+			if !r.resubmit(path, c, fmt.Sprintf("tx(%s) was dropped from the mempool", r.Hash)) {
+				r.Outcome = TxDropped
+				return
+			}
+			r.broadcast(path, c)
+			continue
+		}
+
+		c.removeInFlightTx(r.Hash)
+		if err != nil {
+			c.LogFailedTx(res, err, r.Msgs)
+		} else if success {
+			c.LogSuccessTx(res, r.Msgs)
+		}
+		r.Response = res
+		if success {
+			r.Outcome = TxCommitted
+		} else {
+			r.Outcome = TxFailed
+		}
+	}
+}
+
+// resubmit raises the gas prices of the next attempt and returns true, unless the msgs of r were
+// submitted too often already
+func (r *TxResult) resubmit(path string, c *Chain, reason string) bool {
+	if r.Attempts > txMaxResubmits {
+		return false
+	}
+	r.feeBump *= txFeeBump
+	// the state may have moved on since the gas was estimated
+	r.gas = 0
+	Metrics.TxsResubmitted.WithLabelValues(path, c.ChainID()).Inc()
+	c.Log(fmt.Sprintf("- [%s] %s, resubmitting with gas prices raised by %.2fx", c.ChainID(), reason, r.feeBump))
+	return true
+}
+
+func (c *Chain) removeInFlightTx(hash string) {
+	if err := state.RemoveInFlightTx(c.ChainID(), hash); err != nil {
+		c.Error(fmt.Errorf("failed to remove in-flight tx %s: %w", hash, err))
+	}
+}
+
+// RelayIncompleteError is returned when some of the packets or acknowledgements of a relay round were
+// not relayed because their txs failed, were dropped, exceeded a fee budget or were held back, so the
+// caller can try them again
+type RelayIncompleteError struct {
+	Sequences *RelaySequences
+}
+
+func (e *RelayIncompleteError) Error() string {
+	return fmt.Sprintf("relay txs failed for sequences src%v dst%v", e.Sequences.Src, e.Sequences.Dst)
+}
+
+// failedSequences returns the sequences of the packets or acknowledgements on the channel between src and
+// dst whose txs failed, were dropped, exceeded a fee budget or were held back, on the side of the
+// RelaySequences that they were relayed from
+func (r *RelayMsgs) failedSequences(src, dst *Chain, acks bool) *RelaySequences {
+	rs := &RelaySequences{Src: []uint64{}, Dst: []uint64{}}
+	for _, res := range r.Results {
+		switch res.Outcome {
+		case TxFailed, TxDropped, TxOverBudget, TxHeldBack:
+		default:
+			continue
+		}
+		for _, msg := range res.Msgs {
+			p, ok := inFlightPacket(msg)
+			if !ok || (p.Kind == packetAck) != acks {
+				continue
+			}
+			// packets from src are received on dst and timed out on src, their acks are delivered to src
+			fromSrc := (p.Kind == packetRecv && res.ChainID == dst.ChainID()) ||
+				(p.Kind != packetRecv && res.ChainID == src.ChainID())
+			// the msgs of other channels on the same connection may share the tx
+			sender := dst
+			if fromSrc {
+				sender = src
+			}
+			if p.SourceChannel != sender.PathEnd.ChannelID || p.SourcePort != sender.PathEnd.PortID {
+				continue
+			}
+			// acks written on src are for packets sent by dst, which are tracked in rs.Src
+			if fromSrc != acks {
+				rs.Src = append(rs.Src, p.Sequence)
+			} else {
+				rs.Dst = append(rs.Dst, p.Sequence)
+			}
+		}
+	}
+	return rs
+}
@@ -0,0 +1,80 @@
+package relayer
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/cosmos/relayer/relayer/provider"
+	"github.com/stretchr/testify/require"
+)
+
+// mempoolProvider broadcasts txs that are never committed, unless commitAfter broadcasts were made,
+// and reports them as waiting in the mempool or not
+type mempoolProvider struct {
+	provider.ChainProvider
+
+	inMempool   bool
+	mempoolErr  error
+	commitAfter int
+
+	broadcasts []string
+	feeBumps   []float64
+}
+
+func (p *mempoolProvider) BroadcastMessages(msgs []provider.RelayerMessage, key string, gas uint64, feeBump float64) (string, error) {
+	hash := fmt.Sprintf("%064X", len(p.broadcasts)+1)
+	p.broadcasts = append(p.broadcasts, hash)
+	p.feeBumps = append(p.feeBumps, feeBump)
+	return hash, nil
+}
+
+func (p *mempoolProvider) WaitForTx(txHash string, timeout time.Duration) (*provider.RelayerTxResponse, bool, error) {
+	if p.commitAfter > 0 && len(p.broadcasts) >= p.commitAfter {
+		return &provider.RelayerTxResponse{TxHash: txHash, Height: 1}, true, nil
+	}
+	return nil, false, fmt.Errorf("%w: %s", provider.ErrTxNotCommitted, txHash)
+}
+
+func (p *mempoolProvider) TxInMempool(txHash string) (bool, error) {
+	return p.inMempool, p.mempoolErr
+}
+
+func TestFollowTxNotCommitted(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		mp        *mempoolProvider
+		outcome   TxOutcome
+		broadcast int
+	}{
+		// a tx still waiting in the mempool is left pending rather than sent twice
+		{"in mempool", &mempoolProvider{inMempool: true}, TxPending, 1},
+		{"mempool unknown", &mempoolProvider{mempoolErr: errors.New("node unreachable")}, TxPending, 1},
+		// a tx evicted from the mempool is resubmitted until it is committed or resubmitted too often
+		{"dropped then committed", &mempoolProvider{commitAfter: 2}, TxCommitted, 2},
+		{"dropped", &mempoolProvider{}, TxDropped, txMaxResubmits + 1},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			c := newMockChain(t, "ibc-0")
+			tc.mp.ChainProvider = c.ChainProvider
+			c.ChainProvider = tc.mp
+
+			r := &TxResult{ChainID: c.ChainID(), gas: 100000, feeBump: 1}
+			r.broadcast("demo", c)
+			r.follow("demo", c)
+			require.Equal(t, tc.outcome, r.Outcome)
+			require.Equal(t, tc.broadcast, r.Attempts)
+			require.Len(t, tc.mp.broadcasts, tc.broadcast)
+
+			// every resubmission raises the gas prices and has its gas estimated afresh
+			for i, bump := range tc.mp.feeBumps {
+				require.InDelta(t, math.Pow(txFeeBump, float64(i)), bump, 1e-9)
+			}
+			if tc.broadcast > 1 {
+				require.Zero(t, r.gas)
+			}
+		})
+	}
+}